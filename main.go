@@ -24,38 +24,15 @@ import (
 	"math/rand"
 )
 
-type stringValue string
-
-func newStringValue(val string, p *string) *stringValue {
-	*p = val
-	return (*stringValue)(p)
-}
-
-func (s *stringValue) Set(val string) error {
-	*s = stringValue(val)
-	return nil
-}
-
-func (s *stringValue) Get() string { return string(*s) }
-
-func (s *stringValue) String() string { return string(*s) }
-
 func fooCommand() *cli.Command {
-	//var ptr string
-	//args := "-v test"
-	//flag.CommandLine = flag.NewFlagSet("name", flag.ExitOnError)
-	//flag.CommandLine.Var(newStringValue("", &ptr), "v", "usage")
-	//err := flag.CommandLine.Parse(os.Args[1:])
-	//if err != nil {
-	//	fmt.Println(err)
-	//	os.Exit(1)
-	//}
-	//fmt.Println(ptr)
-
 	foo := cli.NewCommand()
+	text := foo.Flags().StringP("text", "t", "Foo", "text to print")
+	repeat := foo.Flags().IntP("repeat", "r", 1, "number of times the text is printed")
 	foo.Run = func(cmd *cli.Command, pid int, args []string) {
 		cmd.WriteLn([]byte{})
-		cmd.WriteLn([]byte("Foo"))
+		for i := 0; i < *repeat; i++ {
+			cmd.WriteLn([]byte(*text))
+		}
 	}
 	foo.Use = "foo"
 	foo.Short = "Command foo"
@@ -135,9 +112,6 @@ func main() {
 	const secure = true
 	const pass = "p"
 
-	t := cli.NewCommand()
-	_ = t.AddCommand(fooCommand())
-
 	auth := authenticator.NewSimpleAuthenticator()
 	if err := auth.Setup(user, pass); err != nil {
 		log.Fatal(err)
@@ -148,7 +122,11 @@ func main() {
 	fmt.Println("user", user)
 	k := shell.New(secure, auth, port, false)
 	k.SetPrompt(prompt)
-	k.SetTemplate(t)
+	k.SetTemplateFunc(func() *cli.Command {
+		t := cli.NewCommand()
+		_ = t.AddCommand(fooCommand())
+		return t
+	})
 
 	k.Start()
 }
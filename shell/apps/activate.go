@@ -27,6 +27,12 @@ func CreateActivate(t commandcreator.ICreator) *cli.Command {
 	root.Short = "Activate"
 	root.Long = "Activate"
 	root.Activate = true
+	root.ValidArgsFunction = func(cmd *cli.Command, args []string, toComplete string) []cli.Completion {
+		if len(args) > 0 {
+			return nil
+		}
+		return completePids(cmd, args, toComplete)
+	}
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
 		r := cmd.GetRootContext()
 		targetPid := -1
@@ -0,0 +1,58 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"strings"
+)
+
+func CreateAlias(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "alias"
+	root.Short = "Define aliases"
+	root.Long = "Define the aliases given as name=value, e.g. alias mem='stats memory'. With a name print the alias, without arguments list them. Aliases are saved with the user settings"
+	root.DisableFlagParsing = true
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		if len(args) == 0 {
+			args = r.ListAliases()
+		}
+		header := false
+		for _, arg := range args {
+			idx := strings.Index(arg, "=")
+			if idx < 0 {
+				value, ok := r.GetAlias(arg)
+				if !ok {
+					cmd.PrintErrf("alias %s not found"+cli.DefaultEol, arg)
+					cmd.SetExitCode(cli.ExitFailure)
+					continue
+				}
+				if !header {
+					r.WriteLn("")
+					header = true
+				}
+				r.WriteLn("alias " + arg + "='" + value + "'")
+				continue
+			}
+			if err := r.SetAlias(arg[:idx], arg[idx+1:]); err != nil {
+				cmd.PrintErrf("%s"+cli.DefaultEol, err.Error())
+				cmd.SetExitCode(cli.ExitFailure)
+			}
+		}
+	}
+	return root
+}
@@ -0,0 +1,48 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+)
+
+func CreateBg(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "bg"
+	root.Short = "Background"
+	root.Long = "Continue the given stopped jobs in the background, the current job without arguments. " + jobsHelp
+	root.ValidArgsFunction = completeJobs
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		r.WriteLn("")
+		if len(args) == 0 {
+			args = []string{"%+"}
+		}
+		for _, arg := range args {
+			jobPid, err := taskPid(r, arg)
+			if err != nil {
+				r.WriteLn(err.Error())
+				cmd.SetExitCode(cli.ExitFailure)
+				continue
+			}
+			if !r.SetBg(jobPid) {
+				r.WriteLn("Not a job: " + arg)
+				cmd.SetExitCode(cli.ExitFailure)
+			}
+		}
+	}
+	return root
+}
@@ -0,0 +1,43 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buffer
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+)
+
+func Create(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "buffer"
+	root.Short = "Buffer"
+	root.Long = "Named session buffers, filled by redirecting an output to @name"
+	root.Run = func(cmd *cli.Command, pid int, args []string) {}
+
+	t.AddCommand(root, CreateBufferShow(t))
+	t.AddCommand(root, CreateBufferList(t))
+	t.AddCommand(root, CreateBufferClear(t))
+
+	return root
+}
+
+// completeBuffers completes the names of the session buffers
+func completeBuffers(cmd *cli.Command, _ []string, _ string) []cli.Completion {
+	var out []cli.Completion
+	for _, name := range cmd.GetRootContext().ListBuffers() {
+		out = append(out, cli.Completion{Value: name})
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buffer
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"strings"
+)
+
+func CreateBufferShow(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "show"
+	root.Short = "Show"
+	root.Long = "Show the content of a buffer"
+	root.ValidArgsFunction = completeBuffers
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		if len(args) <= 0 {
+			cmd.SetExitCode(cli.ExitUsage)
+			return
+		}
+		data, ok := r.GetBuffer(args[0])
+		if !ok {
+			cmd.PrintErrf(cli.DefaultEol+"buffer %s not found"+cli.DefaultEol, args[0])
+			cmd.SetExitCode(cli.ExitFailure)
+			return
+		}
+		r.WriteLn("")
+		for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+			r.WriteLn(line)
+		}
+	}
+	return root
+}
@@ -21,17 +21,26 @@ import (
 
 func CreateChangeDirectory(t commandcreator.ICreator) *cli.Command {
 	root := t.CreateCommand()
-	root.Use = "cd"
-	root.Short = "cd"
-	root.Long = "cd"
+	root.Use = "cd [path]"
+	root.Short = "Change the current command group"
+	root.Long = "Move into a group of the command tree, its commands can then be run by name. " +
+		"The path is absolute or relative, .. is the parent group and no path is the root, e.g. cd /stats"
+	root.ValidArgsFunction = func(cmd *cli.Command, args []string, toComplete string) []cli.Completion {
+		if len(args) > 0 {
+			return nil
+		}
+		return cmd.Root().CompletePath(toComplete, true)
+	}
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
 		r := cmd.GetRootContext()
-		r.WriteLn("")
-		if len(args) <= 0 {
-			r.WriteLn("Empty argument")
-			return
+		path := cli.PathSeparator
+		if len(args) > 0 {
+			path = args[0]
+		}
+		if err := r.SetBasePath(path); err != nil {
+			cmd.PrintErrf(cli.DefaultEol+"cd: %s"+cli.DefaultEol, err.Error())
+			cmd.SetExitCode(cli.ExitFailure)
 		}
-		r.SetBasePath(args[0])
 	}
 	return root
 }
@@ -0,0 +1,71 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"strconv"
+)
+
+// completePids completes the pids of the running tasks not already in args, described by the task name
+func completePids(cmd *cli.Command, args []string, _ string) []cli.Completion {
+	given := make(map[string]bool)
+	for _, arg := range args {
+		given[arg] = true
+	}
+	var out []cli.Completion
+	for _, p := range cmd.GetRootContext().Processes() {
+		pid := strconv.Itoa(p.Pid)
+		if !given[pid] {
+			out = append(out, cli.Completion{Value: pid, Description: p.Name})
+		}
+	}
+	return out
+}
+
+// completeTaskNames completes the names of the running tasks
+func completeTaskNames(cmd *cli.Command, _ []string, _ string) []cli.Completion {
+	seen := make(map[string]bool)
+	var out []cli.Completion
+	for _, p := range cmd.GetRootContext().Processes() {
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			out = append(out, cli.Completion{Value: p.Name})
+		}
+	}
+	return out
+}
+
+// completeJobs completes the job specifications, %n, of the jobs not ended
+func completeJobs(cmd *cli.Command, args []string, _ string) []cli.Completion {
+	given := make(map[string]bool)
+	for _, arg := range args {
+		given[arg] = true
+	}
+	var out []cli.Completion
+	for _, job := range cmd.GetRootContext().Jobs() {
+		spec := "%" + strconv.Itoa(job.Id)
+		if job.State != interfaces.JobDone && !given[spec] {
+			out = append(out, cli.Completion{Value: spec, Description: job.Line})
+		}
+	}
+	return out
+}
+
+// completeTasks completes the pids and the job specifications
+func completeTasks(cmd *cli.Command, args []string, toComplete string) []cli.Completion {
+	return append(completeJobs(cmd, args, toComplete), completePids(cmd, args, toComplete)...)
+}
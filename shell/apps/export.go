@@ -0,0 +1,56 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"strings"
+)
+
+func CreateExport(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "export"
+	root.Short = "Export variables"
+	root.Long = "Export the given variables, NAME or NAME=value: exported variables are saved with the user settings and restored at the next login. Without arguments list them"
+	root.DisableFlagParsing = true
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		if len(args) == 0 {
+			r.WriteLn("")
+			for _, v := range r.ListVars(true) {
+				r.WriteLn(v)
+			}
+			return
+		}
+		for _, arg := range args {
+			name := arg
+			if idx := strings.Index(arg, "="); idx >= 0 {
+				name = arg[:idx]
+				if err := r.SetVar(name, arg[idx+1:]); err != nil {
+					cmd.PrintErrf(cli.DefaultEol+"%s"+cli.DefaultEol, err.Error())
+					cmd.SetExitCode(cli.ExitFailure)
+					return
+				}
+			}
+			if err := r.ExportVar(name); err != nil {
+				cmd.PrintErrf(cli.DefaultEol+"%s"+cli.DefaultEol, err.Error())
+				cmd.SetExitCode(cli.ExitFailure)
+				return
+			}
+		}
+	}
+	return root
+}
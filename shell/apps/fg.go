@@ -17,31 +17,38 @@ package apps
 import (
 	"github.com/markel1974/goshell/shell/apps/commandcreator"
 	"github.com/markel1974/goshell/shell/cli"
-	"strconv"
 )
 
 func CreateFg(t commandcreator.ICreator) *cli.Command {
 	root := t.CreateCommand()
 	root.Use = "fg"
 	root.Short = "Foreground"
-	root.Long = "Foreground"
+	root.Long = "Bring a task or a job to the foreground, the current job without arguments. " +
+		"The output kept while the job was in the background is shown. " + jobsHelp
+	root.ValidArgsFunction = func(cmd *cli.Command, args []string, toComplete string) []cli.Completion {
+		if len(args) > 0 {
+			return nil
+		}
+		return completeTasks(cmd, args, toComplete)
+	}
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
 		r := cmd.GetRootContext()
 
 		r.WriteLn("")
 
 		if len(args) <= 0 {
-			r.WriteLn("Empty argument")
-			return
+			args = []string{"%+"}
 		}
-		pid, err := strconv.Atoi(args[0])
+		pid, err := taskPid(r, args[0])
 		if err != nil {
-			r.WriteLn("Invalid argument: " + args[0])
+			r.WriteLn(err.Error())
+			cmd.SetExitCode(cli.ExitUsage)
 			return
 		}
 
 		if !r.SetFg(pid) {
 			r.WriteLn("Unknown task: " + args[0])
+			cmd.SetExitCode(cli.ExitFailure)
 		}
 	}
 
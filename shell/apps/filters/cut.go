@@ -0,0 +1,125 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"errors"
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"strconv"
+	"strings"
+)
+
+// position is a 1-based inclusive range of fields or characters, to == 0 means up to the end
+type position struct {
+	from int
+	to   int
+}
+
+// parseList parses a cut list like "1,3-4,6-"
+func parseList(list string) ([]position, error) {
+	var out []position
+	for _, item := range strings.Split(list, ",") {
+		var p position
+		var err error
+		bounds := strings.SplitN(item, "-", 2)
+		if len(bounds[0]) > 0 {
+			if p.from, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, errors.New("invalid list value: " + item)
+			}
+		} else {
+			p.from = 1
+		}
+		if len(bounds) == 1 {
+			p.to = p.from
+		} else if len(bounds[1]) > 0 {
+			if p.to, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, errors.New("invalid list value: " + item)
+			}
+		}
+		if p.from < 1 || (p.to != 0 && p.to < p.from) {
+			return nil, errors.New("invalid list value: " + item)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func selected(positions []position, n int) bool {
+	for _, p := range positions {
+		if n >= p.from && (p.to == 0 || n <= p.to) {
+			return true
+		}
+	}
+	return false
+}
+
+func CreateCut(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "cut"
+	root.Short = "Print selected parts of lines"
+	root.Long = "Print the selected fields or characters of each input line, e.g. ps | cut -d : -f 1"
+	delimiter := root.Flags().StringP("delimiter", "d", "\t", "use the given delimiter instead of TAB for field delimiter")
+	fields := root.Flags().StringP("fields", "f", "", "select only these fields, e.g. 1,3-4")
+	characters := root.Flags().StringP("characters", "c", "", "select only these characters, e.g. 1-8")
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+
+		list := *fields
+		if len(*characters) > 0 {
+			list = *characters
+		}
+		if len(list) == 0 || (len(*fields) > 0 && len(*characters) > 0) {
+			r.WriteLn("")
+			r.WriteLn("You must specify either a list of fields or a list of characters")
+			return
+		}
+		positions, err := parseList(list)
+		if err != nil {
+			r.WriteLn("")
+			r.WriteLn(err.Error())
+			return
+		}
+
+		var out []string
+		for _, line := range readLines(cmd) {
+			if len(*characters) > 0 {
+				var sb strings.Builder
+				for n, c := range []rune(line) {
+					if selected(positions, n+1) {
+						sb.WriteRune(c)
+					}
+				}
+				out = append(out, sb.String())
+				continue
+			}
+
+			if !strings.Contains(line, *delimiter) {
+				out = append(out, line)
+				continue
+			}
+			var parts []string
+			for n, field := range strings.Split(line, *delimiter) {
+				if selected(positions, n+1) {
+					parts = append(parts, field)
+				}
+			}
+			out = append(out, strings.Join(parts, *delimiter))
+		}
+
+		writeLines(cmd, out)
+	}
+	return root
+}
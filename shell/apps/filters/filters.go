@@ -0,0 +1,39 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"bufio"
+	"github.com/markel1974/goshell/shell/cli"
+	"strings"
+)
+
+// readLines returns the lines received from the previous stage of the pipeline
+func readLines(cmd *cli.Command) []string {
+	var lines []string
+	scanner := bufio.NewScanner(cmd.InOrStdin())
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	return lines
+}
+
+func writeLines(cmd *cli.Command, lines []string) {
+	r := cmd.GetRootContext()
+	r.WriteLn("")
+	for _, line := range lines {
+		r.WriteLn(line)
+	}
+}
@@ -0,0 +1,153 @@
+package filters
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+)
+
+// testContext keeps the lines written by the filters, the rest of the context is not used
+type testContext struct {
+	interfaces.IContext
+	out []string
+}
+
+func (c *testContext) WriteLn(data string) {
+	c.out = append(c.out, data)
+}
+
+type testCreator struct {
+	ctx *testContext
+}
+
+func (t *testCreator) CreateCommand() *cli.Command {
+	cmd := cli.NewCommand()
+	cmd.SetRootContext(t.ctx)
+	return cmd
+}
+
+func (t *testCreator) AddCommand(cmd *cli.Command, child *cli.Command) {
+	_ = cmd.AddCommand(child)
+}
+
+// filter runs line with input coming from the previous stage of a pipeline,
+// it returns the lines written and the exit status
+func filter(t *testing.T, line string, input string) ([]string, int) {
+	ctx, cmd := runFilter(t, line, input, io.Discard)
+	// the output starts on a new line
+	if len(ctx.out) == 0 || ctx.out[0] != "" {
+		t.Fatalf("expected an empty first line, got %q", ctx.out)
+	}
+	return ctx.out[1:], cmd.ExitCode()
+}
+
+// runFilter runs line writing its errors to stderr
+func runFilter(t *testing.T, line string, input string, stderr io.Writer) (*testContext, *cli.Command) {
+	ctx := &testContext{}
+	creator := &testCreator{ctx: ctx}
+	root := creator.CreateCommand()
+	for _, create := range []func(commandcreator.ICreator) *cli.Command{
+		CreateGrep, CreateHead, CreateTail, CreateSort, CreateUniq, CreateWc, CreateCut,
+	} {
+		creator.AddCommand(root, create(creator))
+	}
+
+	args, err := root.ParseArgs(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetArgs(args)
+	cmd, flags, err := root.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.SetIn(strings.NewReader(input))
+	cmd.SetErr(stderr)
+	if err = root.Execute(cmd, flags, 1); err != nil {
+		t.Fatal(err)
+	}
+	return ctx, cmd
+}
+
+func TestFilters(t *testing.T) {
+	ps := "1 ps\n12 Invaders\n3 tetris\n12 invaders\n"
+	tests := []struct {
+		name     string
+		line     string
+		input    string
+		expected []string
+		status   int
+	}{
+		{"grep", "grep nv", ps, []string{"12 Invaders", "12 invaders"}, cli.ExitOK},
+		{"grep regexp", "grep '^1[0-9]? '", ps, []string{"1 ps", "12 Invaders", "12 invaders"}, cli.ExitOK},
+		{"grep ignore case", "grep -i INVADERS", ps, []string{"12 Invaders", "12 invaders"}, cli.ExitOK},
+		{"grep invert", "grep -v nv", ps, []string{"1 ps", "3 tetris"}, cli.ExitOK},
+		{"grep invert ignore case count", "grep -v -i -c INV", ps, []string{"2"}, cli.ExitOK},
+		{"grep count", "grep -c invaders", ps, []string{"1"}, cli.ExitOK},
+		{"grep line number", "grep -n tetris", ps, []string{"3:3 tetris"}, cli.ExitOK},
+		{"grep fixed", "grep -F '1 p.'", "1 ps\n1 p.\n", []string{"1 p."}, cli.ExitOK},
+		{"grep carriage return", "grep 's$'", "1 ps\r\n", []string{"1 ps"}, cli.ExitOK},
+		{"grep no match", "grep snake", ps, nil, cli.ExitFailure},
+		{"grep no match count", "grep -c snake", ps, []string{"0"}, cli.ExitFailure},
+		{"head", "head", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}, cli.ExitOK},
+		{"head lines", "head -n 2", ps, []string{"1 ps", "12 Invaders"}, cli.ExitOK},
+		{"head more lines than input", "head -n 9", "a\nb\n", []string{"a", "b"}, cli.ExitOK},
+		{"head zero", "head -n 0", ps, nil, cli.ExitOK},
+		{"tail", "tail --lines 2", ps, []string{"3 tetris", "12 invaders"}, cli.ExitOK},
+		{"tail more lines than input", "tail -n 9", "a\nb\n", []string{"a", "b"}, cli.ExitOK},
+		{"sort", "sort", "b\nc\na\n", []string{"a", "b", "c"}, cli.ExitOK},
+		{"sort reverse", "sort -r", "b\nc\na\n", []string{"c", "b", "a"}, cli.ExitOK},
+		{"sort numeric", "sort -n", "10\n9\n100\n", []string{"9", "10", "100"}, cli.ExitOK},
+		{"sort text", "sort", "10\n9\n100\n", []string{"10", "100", "9"}, cli.ExitOK},
+		{"sort reverse numeric unique", "sort -r -n -u", "10\n9\n10\n100\n9\n", []string{"100", "10", "9"}, cli.ExitOK},
+		{"sort ignore case unique", "sort -f -u", "b\nB\na\n", []string{"a", "b"}, cli.ExitOK},
+		{"sort key", "sort -k 2", ps, []string{"12 Invaders", "12 invaders", "1 ps", "3 tetris"}, cli.ExitOK},
+		{"sort key separator numeric", "sort -t : -k 2 -n", "a:3\nb:1\nc:2\n", []string{"b:1", "c:2", "a:3"}, cli.ExitOK},
+		{"uniq", "uniq", "a\na\nb\na\n", []string{"a", "b", "a"}, cli.ExitOK},
+		{"uniq count", "uniq -c", "a\na\nb\n", []string{"      2 a", "      1 b"}, cli.ExitOK},
+		{"uniq repeated", "uniq -d", "a\na\nb\nc\nc\n", []string{"a", "c"}, cli.ExitOK},
+		{"uniq unique", "uniq -u", "a\na\nb\nc\nc\n", []string{"b"}, cli.ExitOK},
+		{"uniq ignore case", "uniq -i", "a\nA\nb\n", []string{"a", "b"}, cli.ExitOK},
+		{"uniq empty", "uniq", "", nil, cli.ExitOK},
+		{"wc", "wc", ps, []string{"4 8 38"}, cli.ExitOK},
+		{"wc lines", "wc -l", ps, []string{"4"}, cli.ExitOK},
+		{"wc words chars", "wc -w -m", "è a\n", []string{"2 4"}, cli.ExitOK},
+		{"cut fields", "cut -d : -f 1,3", "a:b:c:d\n", []string{"a:c"}, cli.ExitOK},
+		{"cut field range", "cut -d ' ' -f 2-", "a b c\n", []string{"b c"}, cli.ExitOK},
+		{"cut tab", "cut -f 2", "a\tb\n", []string{"b"}, cli.ExitOK},
+		{"cut without delimiter", "cut -d : -f 2", "abc\n", []string{"abc"}, cli.ExitOK},
+		{"cut characters", "cut -c 2-3,5", "abcdef\n", []string{"bce"}, cli.ExitOK},
+		{"cut characters to", "cut -c -2", "èabc\n", []string{"èa"}, cli.ExitOK},
+		{"cut no list", "cut -d :", "a:b\n", []string{"You must specify either a list of fields or a list of characters"}, cli.ExitOK},
+		{"cut invalid list", "cut -f 3-1", "a:b\n", []string{"invalid list value: 3-1"}, cli.ExitOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, status := filter(t, tt.line, tt.input)
+			if strings.Join(out, "|") != strings.Join(tt.expected, "|") || len(out) != len(tt.expected) {
+				t.Errorf("got %q, want %q", out, tt.expected)
+			}
+			if status != tt.status {
+				t.Errorf("got status %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestGrep_InvalidPattern(t *testing.T) {
+	var stderr strings.Builder
+	ctx, cmd := runFilter(t, "grep 'a('", "a(\n", &stderr)
+	if len(ctx.out) != 0 {
+		t.Errorf("expected no output, got %q", ctx.out)
+	}
+	if !strings.Contains(stderr.String(), "grep: invalid pattern: ") {
+		t.Errorf("expected the error on stderr, got %q", stderr.String())
+	}
+	if cmd.ExitCode() != cli.ExitUsage {
+		t.Errorf("got status %d, want %d", cmd.ExitCode(), cli.ExitUsage)
+	}
+}
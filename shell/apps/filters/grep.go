@@ -0,0 +1,71 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"fmt"
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"regexp"
+	"strconv"
+)
+
+func CreateGrep(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "grep"
+	root.Short = "Print lines matching a pattern"
+	root.Long = "Print the input lines matching a regular expression, e.g. ps | grep invaders. The exit status is 1 when no line matches"
+	root.Args = cli.ExactArgs(1)
+	ignoreCase := root.Flags().BoolP("ignore-case", "i", false, "ignore case distinctions")
+	invert := root.Flags().BoolP("invert-match", "v", false, "select non-matching lines")
+	count := root.Flags().BoolP("count", "c", false, "print only the number of matching lines")
+	lineNumber := root.Flags().BoolP("line-number", "n", false, "prefix each line with its line number")
+	fixed := root.Flags().BoolP("fixed-strings", "F", false, "interpret the pattern as a fixed string")
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		pattern := args[0]
+		if *fixed {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		if *ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			cmd.PrintErrf(cli.DefaultEol+"grep: invalid pattern: %s"+cli.DefaultEol, err.Error())
+			cmd.SetExitCode(cli.ExitUsage)
+			return
+		}
+
+		var out []string
+		for n, line := range readLines(cmd) {
+			if re.MatchString(line) == *invert {
+				continue
+			}
+			if *lineNumber {
+				line = fmt.Sprintf("%d:%s", n+1, line)
+			}
+			out = append(out, line)
+		}
+
+		if len(out) == 0 {
+			cmd.SetExitCode(cli.ExitFailure)
+		}
+		if *count {
+			out = []string{strconv.Itoa(len(out))}
+		}
+		writeLines(cmd, out)
+	}
+	return root
+}
@@ -0,0 +1,95 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func CreateSort(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "sort"
+	root.Short = "Sort lines"
+	root.Long = "Sort the lines of the input"
+	reverse := root.Flags().BoolP("reverse", "r", false, "reverse the result of comparisons")
+	numeric := root.Flags().BoolP("numeric-sort", "n", false, "compare according to string numerical value")
+	ignoreCase := root.Flags().BoolP("ignore-case", "f", false, "fold lower case to upper case characters")
+	unique := root.Flags().BoolP("unique", "u", false, "output only the first of an equal run")
+	key := root.Flags().IntP("key", "k", 0, "sort on the given field, starting from 1")
+	separator := root.Flags().StringP("field-separator", "t", "", "use the given separator instead of blanks")
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		in := readLines(cmd)
+
+		value := func(line string) string {
+			if *key > 0 {
+				var fields []string
+				if len(*separator) > 0 {
+					fields = strings.Split(line, *separator)
+				} else {
+					fields = strings.Fields(line)
+				}
+				if *key > len(fields) {
+					return ""
+				}
+				line = fields[*key-1]
+			}
+			if *ignoreCase {
+				line = strings.ToUpper(line)
+			}
+			return line
+		}
+
+		compare := func(a string, b string) int {
+			va, vb := value(a), value(b)
+			if *numeric {
+				na, _ := strconv.ParseFloat(strings.TrimSpace(va), 64)
+				nb, _ := strconv.ParseFloat(strings.TrimSpace(vb), 64)
+				switch {
+				case na < nb:
+					return -1
+				case na > nb:
+					return 1
+				}
+				return 0
+			}
+			return strings.Compare(va, vb)
+		}
+
+		sort.SliceStable(in, func(i, j int) bool {
+			if *reverse {
+				return compare(in[i], in[j]) > 0
+			}
+			return compare(in[i], in[j]) < 0
+		})
+
+		if *unique {
+			var out []string
+			for i, line := range in {
+				if i > 0 && compare(in[i-1], line) == 0 {
+					continue
+				}
+				out = append(out, line)
+			}
+			in = out
+		}
+
+		writeLines(cmd, in)
+	}
+	return root
+}
@@ -0,0 +1,36 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+)
+
+func CreateTail(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "tail"
+	root.Short = "Print the last lines"
+	root.Long = "Print the last lines of the input"
+	lines := root.Flags().IntP("lines", "n", 10, "number of lines to print")
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		in := readLines(cmd)
+		if *lines >= 0 && *lines < len(in) {
+			in = in[len(in)-*lines:]
+		}
+		writeLines(cmd, in)
+	}
+	return root
+}
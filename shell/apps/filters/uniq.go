@@ -0,0 +1,68 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"fmt"
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"strings"
+)
+
+func CreateUniq(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "uniq"
+	root.Short = "Omit repeated lines"
+	root.Long = "Filter adjacent matching lines of the input"
+	count := root.Flags().BoolP("count", "c", false, "prefix lines by the number of occurrences")
+	repeated := root.Flags().BoolP("repeated", "d", false, "only print duplicate lines, one for each group")
+	unique := root.Flags().BoolP("unique", "u", false, "only print unique lines")
+	ignoreCase := root.Flags().BoolP("ignore-case", "i", false, "ignore differences in case when comparing")
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		equal := func(a string, b string) bool {
+			if *ignoreCase {
+				return strings.EqualFold(a, b)
+			}
+			return a == b
+		}
+
+		var out []string
+		flush := func(line string, n int) {
+			if n == 0 || (*repeated && n == 1) || (*unique && n > 1) {
+				return
+			}
+			if *count {
+				line = fmt.Sprintf("%7d %s", n, line)
+			}
+			out = append(out, line)
+		}
+
+		current := ""
+		n := 0
+		for _, line := range readLines(cmd) {
+			if n > 0 && equal(current, line) {
+				n++
+				continue
+			}
+			flush(current, n)
+			current = line
+			n = 1
+		}
+		flush(current, n)
+
+		writeLines(cmd, out)
+	}
+	return root
+}
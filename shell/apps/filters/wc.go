@@ -0,0 +1,53 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filters
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func CreateWc(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "wc"
+	root.Short = "Print line, word and character counts"
+	root.Long = "Print the number of lines, words and characters of the input"
+	lines := root.Flags().BoolP("lines", "l", false, "print the line count")
+	words := root.Flags().BoolP("words", "w", false, "print the word count")
+	chars := root.Flags().BoolP("chars", "m", false, "print the character count")
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		data, _ := ioutil.ReadAll(cmd.InOrStdin())
+		text := string(data)
+
+		all := !*lines && !*words && !*chars
+		var out []string
+		if all || *lines {
+			out = append(out, strconv.Itoa(strings.Count(text, "\n")))
+		}
+		if all || *words {
+			out = append(out, strconv.Itoa(len(strings.Fields(text))))
+		}
+		if all || *chars {
+			out = append(out, strconv.Itoa(utf8.RuneCountInString(text)))
+		}
+
+		writeLines(cmd, []string{strings.Join(out, " ")})
+	}
+	return root
+}
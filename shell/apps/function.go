@@ -0,0 +1,63 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"strings"
+)
+
+func CreateFunction(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "function"
+	root.Short = "Define functions"
+	root.Long = "Define a function running the command line given as body, its arguments are available as $1, $2... $# and $@, " +
+		"e.g. function restart 'kill $(ps -n $1 -p); games $1'. With a name print the function, without arguments list them. " +
+		"Functions are saved with the user settings, unset -f removes them"
+	root.DisableFlagParsing = true
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		r.WriteLn("")
+		if len(args) == 0 {
+			for _, name := range r.ListFunctions() {
+				body, _ := r.GetFunction(name)
+				r.WriteLn("function " + name + " " + singleQuote(body))
+			}
+			return
+		}
+		if len(args) == 1 {
+			body, ok := r.GetFunction(args[0])
+			if !ok {
+				cmd.PrintErrf("function %s not found"+cli.DefaultEol, args[0])
+				cmd.SetExitCode(cli.ExitFailure)
+				return
+			}
+			r.WriteLn("function " + args[0] + " " + singleQuote(body))
+			return
+		}
+		if err := r.SetFunction(args[0], strings.Join(args[1:], " ")); err != nil {
+			cmd.PrintErrf("%s"+cli.DefaultEol, err.Error())
+			cmd.SetExitCode(cli.ExitFailure)
+		}
+	}
+	return root
+}
+
+// singleQuote quotes s so the shell reads it back as it is, an embedded quote closes the
+// quoted text, is escaped and opens it again
+func singleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
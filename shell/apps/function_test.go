@@ -0,0 +1,28 @@
+package apps
+
+import (
+	"testing"
+
+	"github.com/markel1974/goshell/shell/cli"
+)
+
+func TestSingleQuote(t *testing.T) {
+	p := cli.NewParser()
+	p.ParseEnv = true
+	for _, body := range []string{
+		"echo $1",
+		"echo it's $1",
+		"echo 'a b' \"c\" \\n",
+		"''",
+	} {
+		line := "function f " + singleQuote(body)
+		args, err := p.Parse(line)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", line, err)
+			continue
+		}
+		if len(args) != 3 || args[2] != body {
+			t.Errorf("Parse(%q): expected the body %q, got %q", line, body, args)
+		}
+	}
+}
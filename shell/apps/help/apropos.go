@@ -0,0 +1,97 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package help
+
+import (
+	"fmt"
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"sort"
+	"strings"
+)
+
+func CreateApropos(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "apropos <word...>"
+	root.Short = "Search the commands"
+	root.Long = "List the commands whose name, usage, description or aliases contain one of the words, ignoring the case"
+	root.Paged = true
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		if len(args) == 0 {
+			cmd.PrintErrf(cli.DefaultEol + "apropos: what?" + cli.DefaultEol)
+			cmd.SetExitCode(cli.ExitUsage)
+			return
+		}
+		var words []string
+		for _, arg := range args {
+			words = append(words, strings.ToLower(arg))
+		}
+
+		type match struct {
+			name  string
+			short string
+		}
+		var matches []match
+		var visit func(c *cli.Command)
+		visit = func(c *cli.Command) {
+			for _, sub := range c.Commands() {
+				if !sub.IsAvailableCommand() {
+					continue
+				}
+				if containsAny(words, sub.Use, sub.Short, sub.Long, strings.Join(sub.Aliases, " ")) {
+					matches = append(matches, match{commandName(sub), sub.Short})
+				}
+				visit(sub)
+			}
+		}
+		visit(cmd.Root())
+		for _, u := range cmd.Root().UserCommands() {
+			if containsAny(words, u.Name, u.Short) {
+				matches = append(matches, match{u.Name, u.Short})
+			}
+		}
+
+		if len(matches) == 0 {
+			cmd.PrintErrf(cli.DefaultEol+"apropos: nothing appropriate for %s"+cli.DefaultEol, strings.Join(args, " "))
+			cmd.SetExitCode(cli.ExitFailure)
+			return
+		}
+		sort.Slice(matches, func(i, j int) bool { return matches[i].name < matches[j].name })
+		padding := 0
+		for _, m := range matches {
+			if len(m.name) > padding {
+				padding = len(m.name)
+			}
+		}
+		r := cmd.GetRootContext()
+		r.WriteLn("")
+		for _, m := range matches {
+			r.WriteLn(fmt.Sprintf("%-*s - %s", padding, m.name, m.short))
+		}
+	}
+	return root
+}
+
+func containsAny(words []string, fields ...string) bool {
+	for _, field := range fields {
+		field = strings.ToLower(field)
+		for _, word := range words {
+			if strings.Contains(field, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
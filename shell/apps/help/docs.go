@@ -0,0 +1,101 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package help
+
+import (
+	"fmt"
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/cli/doc"
+	"io"
+	"strings"
+)
+
+const (
+	docsMarkdown = "markdown"
+	docsMan      = "man"
+	docsJSON     = "json"
+)
+
+func CreateDocs(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "docs"
+	root.Short = "Documentation of the commands"
+	root.Hidden = true
+
+	t.AddCommand(root, createDocsGenerate(t))
+	return root
+}
+
+func createDocsGenerate(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "generate [command...]"
+	root.Short = "Generate the documentation of the commands"
+	root.Long = "Write the documentation of a command and of its subcommands, of every command when none is given, " +
+		"as Markdown, roff man pages or a JSON schema. Redirect the output to save it."
+	root.Example = "docs generate > commands.md\ndocs generate --format man stats > stats.1\ndocs generate --format json | grep roles"
+	root.ValidArgsFunction = completeCommands
+	var format string
+	root.Flags().EnumVarP(&format, "format", "f", docsMarkdown, []string{docsMarkdown, docsMan, docsJSON}, "output format")
+	root.Paged = true
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		target := cmd.Root()
+		if len(args) > 0 {
+			var err error
+			if target, err = cmd.Root().Lookup(args); err != nil {
+				cmd.PrintErrf(cli.DefaultEol+"docs: %s"+cli.DefaultEol, err.Error())
+				cmd.SetExitCode(cli.ExitFailure)
+				return
+			}
+		}
+
+		var b strings.Builder
+		var err error
+		switch format {
+		case docsMan:
+			err = genManPages(target, &b)
+		case docsJSON:
+			err = doc.GenJSON(target, &b)
+		default:
+			err = doc.GenMarkdown(target, &b)
+		}
+		if err != nil {
+			cmd.PrintErrf(cli.DefaultEol+"docs: %s"+cli.DefaultEol, err.Error())
+			cmd.SetExitCode(cli.ExitFailure)
+			return
+		}
+		r := cmd.GetRootContext()
+		r.WriteLn("")
+		r.Write(strings.ReplaceAll(b.String(), "\n", cli.DefaultEol))
+	}
+	return root
+}
+
+// genManPages writes the man pages of cmd and of its subcommands one after another, a root without a name has no page
+func genManPages(cmd *cli.Command, w io.Writer) error {
+	if cmd.HasParent() || len(cmd.Name()) > 0 {
+		if err := doc.GenMan(cmd, nil, w); err != nil {
+			return fmt.Errorf("%s: %w", commandName(cmd), err)
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() || sub.IsAdditionalHelpTopicCommand() {
+			if err := genManPages(sub, w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
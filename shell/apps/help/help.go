@@ -0,0 +1,76 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package help
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+)
+
+func Create(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "help [command...]"
+	root.Short = "Help about any command"
+	root.Long = "Show the help of a command, given by name or path, or of the current group. " +
+		"See also man for the full manual and apropos to search the commands."
+	root.ValidArgsFunction = completeCommands
+	root.Paged = true
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		if len(args) == 1 {
+			for _, u := range cmd.Root().UserCommands() {
+				if u.Name == args[0] {
+					cmd.Printf(cli.DefaultEol+"%s: %s"+cli.DefaultEol, u.Name, u.Short)
+					return
+				}
+			}
+		}
+		target, err := lookup(cmd, args)
+		if err != nil {
+			cmd.PrintErrf(cli.DefaultEol+"help: %s"+cli.DefaultEol, err.Error())
+			cmd.SetExitCode(cli.ExitFailure)
+			return
+		}
+		target.InitDefaultHelpFlag()
+		_ = target.Help()
+	}
+	return root
+}
+
+// lookup returns the command named by args, the current group without args
+func lookup(cmd *cli.Command, args []string) (*cli.Command, error) {
+	if len(args) == 0 {
+		return cmd.Root().Base(), nil
+	}
+	return cmd.Root().Lookup(args)
+}
+
+// completeCommands completes the names of the commands, the first one like a command line
+func completeCommands(cmd *cli.Command, args []string, toComplete string) []cli.Completion {
+	root := cmd.Root()
+	if len(args) == 0 {
+		return root.Complete(nil, toComplete)
+	}
+	parent, err := root.Lookup(args)
+	if err != nil {
+		return nil
+	}
+	var out []cli.Completion
+	for _, sub := range parent.Commands() {
+		if sub.IsAvailableCommand() {
+			out = append(out, cli.Completion{Value: sub.Name(), Description: sub.Short})
+		}
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package help
+
+import (
+	"fmt"
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/apps/pager"
+	"github.com/markel1974/goshell/shell/cli"
+	"strings"
+)
+
+const manIndent = "    "
+
+func CreateMan(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "man <command...>"
+	root.Short = "Show the manual of a command"
+	root.Long = "Show the description, the examples, the flags and the subcommands of a command in a pager. " +
+		"Scroll with the arrows, space and b, quit with q."
+	root.Example = "man stats cpu\nman /task/restore"
+	root.ValidArgsFunction = completeCommands
+	pager.Setup(root)
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		if len(args) == 0 {
+			cmd.PrintErrf(cli.DefaultEol + "man: which command?" + cli.DefaultEol)
+			cmd.SetExitCode(cli.ExitUsage)
+			r.Deactivate(pid)
+			return
+		}
+		target, err := cmd.Root().Lookup(args)
+		if err != nil {
+			cmd.PrintErrf(cli.DefaultEol+"man: %s"+cli.DefaultEol, err.Error())
+			cmd.SetExitCode(cli.ExitFailure)
+			r.Deactivate(pid)
+			return
+		}
+		target.InitDefaultHelpFlag()
+		pager.Open(r, pid, "man "+commandName(target), manPage(target))
+	}
+	return root
+}
+
+func commandName(cmd *cli.Command) string {
+	if !cmd.HasParent() {
+		return cli.PathSeparator
+	}
+	return strings.TrimSpace(cmd.CommandPath())
+}
+
+// manPage renders the manual of cmd
+func manPage(cmd *cli.Command) string {
+	var b strings.Builder
+	section := func(title string, body string) {
+		body = strings.TrimRight(body, "\n")
+		if len(strings.TrimSpace(body)) == 0 {
+			return
+		}
+		b.WriteString(title + "\n")
+		for _, line := range strings.Split(body, "\n") {
+			b.WriteString(manIndent + line + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	name := commandName(cmd)
+	if len(cmd.Short) > 0 {
+		name += " - " + cmd.Short
+	}
+	section("NAME", name)
+	if cmd.Runnable() {
+		section("SYNOPSIS", strings.TrimSpace(cmd.UseLine()))
+	}
+	description := cmd.Long
+	if len(description) == 0 {
+		description = cmd.Short
+	}
+	section("DESCRIPTION", description)
+	section("ALIASES", strings.Join(cmd.Aliases, ", "))
+	section("REQUIRED ROLES", strings.Join(cmd.Roles(), ", "))
+	section("EXAMPLES", cmd.Example)
+	section("OPTIONS", cmd.LocalFlags().FlagUsages())
+	section("GLOBAL OPTIONS", cmd.InheritedFlags().FlagUsages())
+
+	var commands []string
+	padding := 0
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() && len(sub.Name()) > padding {
+			padding = len(sub.Name())
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() {
+			commands = append(commands, fmt.Sprintf("%-*s  %s", padding, sub.Name(), sub.Short))
+		}
+	}
+	section("COMMANDS", strings.Join(commands, "\n"))
+	if cmd.HasParent() {
+		section("SEE ALSO", "man "+commandName(cmd.Parent())+", apropos")
+	}
+	return b.String()
+}
@@ -27,6 +27,8 @@ func Create(t commandcreator.ICreator) *cli.Command {
 	root.Short = "History"
 	root.Long = "History"
 	root.Aliases = []string{"h"}
+	root.Paged = true
+	root.InitOutputFlag()
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
 		r := cmd.GetRootContext()
 		idx := -1
@@ -38,13 +40,14 @@ func Create(t commandcreator.ICreator) *cli.Command {
 		if idx > -1 {
 			r.History(interfaces.HistoryActionExec, idx)
 		} else {
-			r.History(interfaces.HistoryActionList, -1)
+			listHistory(cmd)
 		}
 	}
 
 	t.AddCommand(root, CreateHistoryClear(t))
 	t.AddCommand(root, CreateHistoryExec(t))
 	t.AddCommand(root, CreateHistoryList(t))
+	t.AddCommand(root, CreateHistorySearch(t))
 
 	return root
 }
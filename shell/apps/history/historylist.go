@@ -18,6 +18,7 @@ import (
 	"github.com/markel1974/goshell/shell/apps/commandcreator"
 	"github.com/markel1974/goshell/shell/cli"
 	"github.com/markel1974/goshell/shell/interfaces"
+	"time"
 )
 
 func CreateHistoryList(t commandcreator.ICreator) *cli.Command {
@@ -25,10 +26,32 @@ func CreateHistoryList(t commandcreator.ICreator) *cli.Command {
 	list.Use = "list"
 	list.Short = "List"
 	list.Long = "List"
+	list.Paged = true
+	list.InitOutputFlag()
 	list.Run = func(cmd *cli.Command, pid int, args []string) {
-		r := cmd.GetRootContext()
-		r.History(interfaces.HistoryActionList, -1)
+		listHistory(cmd)
 	}
 
 	return list
 }
+
+func listHistory(cmd *cli.Command) {
+	res := historyTable(cmd.GetRootContext().GetHistory(), nil)
+	cmd.PrintResult(res)
+}
+
+// historyTable returns the entries accepted by match, all of them when match is nil, with their index
+func historyTable(entries []interfaces.HistoryEntry, match func(line string) bool) *cli.Result {
+	res := cli.NewTable("index", "time", "session", "status", "duration", "command")
+	for idx, e := range entries {
+		if match != nil && !match(e.Line) {
+			continue
+		}
+		var when string
+		if !e.Time.IsZero() {
+			when = e.Time.Format("2006-01-02 15:04:05")
+		}
+		res.AddRow(idx, when, e.Session, e.Status, e.Duration.Round(time.Millisecond).String(), e.Line)
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package history
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"regexp"
+)
+
+func CreateHistorySearch(t commandcreator.ICreator) *cli.Command {
+	search := t.CreateCommand()
+	search.Use = "search <pattern>"
+	search.Short = "Search"
+	search.Long = "List the commands of the history containing the pattern, with the index used by history exec. " +
+		"The exit status is 1 when no command matches"
+	search.Example = "history search cd\nhistory search -r '^stats (cpu|mem)'"
+	search.Args = cli.ExactArgs(1)
+	search.Paged = true
+	regex := search.Flags().BoolP("regexp", "r", false, "interpret the pattern as a regular expression")
+	ignoreCase := search.Flags().BoolP("ignore-case", "i", false, "ignore case distinctions")
+	search.InitOutputFlag()
+	search.Run = func(cmd *cli.Command, pid int, args []string) {
+		pattern := args[0]
+		if !*regex {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		if *ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			cmd.PrintErrf(cli.DefaultEol+"history search: invalid pattern: %s"+cli.DefaultEol, err.Error())
+			cmd.SetExitCode(cli.ExitUsage)
+			return
+		}
+
+		res := historyTable(cmd.GetRootContext().GetHistory(), re.MatchString)
+		if len(res.Rows()) == 0 {
+			cmd.SetExitCode(cli.ExitFailure)
+		}
+		cmd.PrintResult(res)
+	}
+
+	return search
+}
@@ -0,0 +1,73 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"errors"
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const jobsHelp = "A job is a command line started with a trailing '&', or the foreground task stopped with ctrl-z. " +
+	"A job is named by %n, %% or %+ is the current job and %- the previous one"
+
+func CreateJobs(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "jobs"
+	root.Short = "Jobs"
+	root.Long = "List the jobs with their state and runtime. " + jobsHelp
+	root.Paged = true
+	root.InitOutputFlag()
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		res := cli.NewTable("job", "pid", "state", "runtime", "command")
+		for _, job := range r.Jobs() {
+			res.AddRow(job.Id, job.Pid, jobState(job), job.Runtime.Round(time.Second).String(), job.Line)
+		}
+		cmd.PrintResult(res)
+	}
+	return root
+}
+
+// jobState describes the state of a job, with the exit status of an ended one
+func jobState(job interfaces.JobInfo) string {
+	if job.State == interfaces.JobDone && job.Status != cli.ExitOK {
+		return "Exit " + strconv.Itoa(job.Status)
+	}
+	return job.State
+}
+
+// taskPid resolves an argument naming a task: a pid or a job specification like %1
+func taskPid(r interfaces.IContext, arg string) (int, error) {
+	if strings.HasPrefix(arg, "%") {
+		job, ok := r.FindJob(arg)
+		if !ok {
+			return 0, errors.New("Unknown job: " + arg)
+		}
+		if job.State == interfaces.JobDone {
+			return 0, errors.New("Job has terminated: " + arg)
+		}
+		return job.Pid, nil
+	}
+	pid, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, errors.New("Invalid argument: " + arg)
+	}
+	return pid, nil
+}
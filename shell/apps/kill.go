@@ -17,34 +17,40 @@ package apps
 import (
 	"github.com/markel1974/goshell/shell/apps/commandcreator"
 	"github.com/markel1974/goshell/shell/cli"
-	"strconv"
 )
 
 func CreateKill(t commandcreator.ICreator) *cli.Command {
 	root := t.CreateCommand()
 	root.Use = "kill"
 	root.Short = "Kill"
-	root.Long = "Kill"
+	root.Long = "Kill the given tasks, a task is a pid or a job specification like %1"
+	root.ValidArgsFunction = completeTasks
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
 		r := cmd.GetRootContext()
 		r.WriteLn("")
 		if len(args) <= 0 {
 			r.WriteLn("Empty argument")
+			cmd.SetExitCode(cli.ExitUsage)
 			return
 		}
-		pid, err := strconv.Atoi(args[0])
-		if err != nil {
-			r.WriteLn("Invalid argument: " + args[0])
-			return
-		}
-		if !r.IsActive(pid) {
-			r.WriteLn("Unknown Task: " + args[0])
-			return
-		}
-		if r.Deactivate(pid) {
-			r.WriteLn("Task deactivated: " + args[0])
-		} else {
-			r.WriteLn("Task can't be deactivated: " + args[0])
+		for _, arg := range args {
+			pid, err := taskPid(r, arg)
+			if err != nil {
+				r.WriteLn(err.Error())
+				cmd.SetExitCode(cli.ExitUsage)
+				continue
+			}
+			if !r.IsActive(pid) {
+				r.WriteLn("Unknown Task: " + arg)
+				cmd.SetExitCode(cli.ExitFailure)
+				continue
+			}
+			if r.Deactivate(pid) {
+				r.WriteLn("Task deactivated: " + arg)
+			} else {
+				r.WriteLn("Task can't be deactivated: " + arg)
+				cmd.SetExitCode(cli.ExitFailure)
+			}
 		}
 	}
 	return root
@@ -15,8 +15,11 @@
 package apps
 
 import (
+	"context"
+	"fmt"
 	"github.com/markel1974/goshell/shell/apps/commandcreator"
 	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
 	"strconv"
 )
 
@@ -24,18 +27,66 @@ func CreateKillAll(t commandcreator.ICreator) *cli.Command {
 	root := t.CreateCommand()
 	root.Use = "killall"
 	root.Short = "Kill All"
-	root.Long = "Kill All"
-	root.Run = func(cmd *cli.Command, pid int, args []string) {
+	root.Long = "Kill the tasks with the given name, all the other tasks without a name. " +
+		"The kill is confirmed at the prompt, unless --yes is given. Without a prompt to answer, in a script, " +
+		"a pipeline or a job, killall fails unless --yes is given"
+	yes := root.Flags().BoolP("yes", "y", false, "kill without asking")
+	root.ValidArgsFunction = func(cmd *cli.Command, args []string, toComplete string) []cli.Completion {
+		if len(args) > 0 {
+			return nil
+		}
+		return completeTaskNames(cmd, args, toComplete)
+	}
+	root.RunContext = func(ctx context.Context, cmd *cli.Command, pid int, args []string) {
 		r := cmd.GetRootContext()
-		r.WriteLn("")
-		var arg string
+		var name string
 		if len(args) > 0 {
-			arg = args[0]
+			name = args[0]
+		}
+
+		var pids []int
+		if err := r.Call(ctx, func() {
+			for _, p := range r.Processes() {
+				if p.Pid != pid && (len(name) == 0 || p.Name == name) {
+					pids = append(pids, p.Pid)
+				}
+			}
+		}); err != nil {
+			return
+		}
+
+		if len(pids) > 0 && !*yes {
+			question := fmt.Sprintf("Kill %d tasks named %s?", len(pids), name)
+			if len(name) == 0 {
+				question = fmt.Sprintf("Kill all the %d tasks?", len(pids))
+			}
+			kill, err := r.Confirm(ctx, question, false)
+			if err == interfaces.ErrNotInteractive {
+				cmd.PrintErr(cli.DefaultEol + "killall: no prompt to confirm the kill, use --yes" + cli.DefaultEol)
+				cmd.SetExitCode(cli.ExitFailure)
+				return
+			}
+			if err != nil {
+				cmd.SetExitCode(cli.ExitFailure)
+				return
+			}
+			if !kill {
+				pids = nil
+			}
 		}
 
-		count := r.DeactivateAll(arg)
+		count := 0
+		if err := r.Call(ctx, func() {
+			for _, p := range pids {
+				if r.Deactivate(p) {
+					count++
+				}
+			}
+		}); err != nil {
+			return
+		}
 
-		r.WriteLn("Task deactivated: " + strconv.Itoa(count))
+		cmd.Print(cli.DefaultEol + "Task deactivated: " + strconv.Itoa(count) + cli.DefaultEol)
 	}
 	return root
 }
@@ -0,0 +1,54 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pager
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"io/ioutil"
+	"strings"
+)
+
+func CreateMore(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "more"
+	root.Short = "Page through the input"
+	root.Long = "Show the input one screen at a time. Scroll with the arrows, space and b, search with /, n and N, quit with q. " +
+		"An input shorter than the screen, or going to a pipeline or a redirection, is written as it is."
+	root.Example = "env | more\nhistory list | grep cd | more"
+	Setup(root)
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		data, err := ioutil.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			cmd.PrintErrf(cli.DefaultEol+"more: %s"+cli.DefaultEol, err.Error())
+			cmd.SetExitCode(cli.ExitFailure)
+			r.Deactivate(pid)
+			return
+		}
+		text := strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		width, height := r.GetScreenSize()
+		if r.IsOutputCaptured() || Fits(text, width, height-1) {
+			if len(text) > 0 {
+				r.WriteLn("")
+				r.WriteLn(strings.ReplaceAll(text, "\n", cli.DefaultEol))
+			}
+			r.Deactivate(pid)
+			return
+		}
+		Open(r, pid, "more", text)
+	}
+	return root
+}
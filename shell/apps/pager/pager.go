@@ -0,0 +1,292 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pager
+
+import (
+	"fmt"
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"strings"
+)
+
+const tabWidth = 4
+
+// Pager shows a text in a window, one screen at a time.
+// The text is wrapped at the width of the window when it is drawn.
+// A search, started with '/', moves the view to the next line matching the pattern, ignoring the case.
+type Pager struct {
+	text      []string
+	lines     []string
+	width     int
+	top       int
+	rows      int
+	searching bool
+	input     []rune
+	pattern   string
+	message   string
+}
+
+func New(text string) *Pager {
+	return &Pager{
+		text: splitText(text),
+		rows: 1,
+	}
+}
+
+// splitText returns the lines of text, the tabs expanded
+func splitText(text string) []string {
+	text = strings.ReplaceAll(text, "\r", "")
+	text = strings.ReplaceAll(text, "\t", strings.Repeat(" ", tabWidth))
+	return strings.Split(strings.TrimRight(text, "\n"), "\n")
+}
+
+// KeyEvent moves the view, it returns false when the pager must be closed.
+func (p *Pager) KeyEvent(code int, key rune) bool {
+	p.message = ""
+	if p.searching {
+		p.searchEvent(code, key)
+		return true
+	}
+	switch interfaces.KeyType(code) {
+	case interfaces.KeyTypeCursor:
+		switch interfaces.CursorCodeDef(key) {
+		case interfaces.CursorUpDef:
+			p.scroll(-1)
+		case interfaces.CursorDownDef:
+			p.scroll(1)
+		}
+	case interfaces.KeyTypeEnter:
+		p.scroll(1)
+	case interfaces.KeyTypeEscape:
+		return false
+	case interfaces.KeyTypeKey:
+		switch key {
+		case 'q', 'Q':
+			return false
+		case 'j':
+			p.scroll(1)
+		case 'k':
+			p.scroll(-1)
+		case ' ', 'f':
+			p.scroll(p.rows)
+		case 'b':
+			p.scroll(-p.rows)
+		case 'g':
+			p.top = 0
+		case 'G':
+			p.top = len(p.lines)
+			p.scroll(0)
+		case '/':
+			p.searching = true
+			p.input = nil
+		case 'n':
+			p.find(p.top+1, 1)
+		case 'N':
+			p.find(p.top-1, -1)
+		}
+	}
+	return true
+}
+
+// searchEvent edits the pattern, Enter starts the search from the first line shown
+func (p *Pager) searchEvent(code int, key rune) {
+	switch interfaces.KeyType(code) {
+	case interfaces.KeyTypeKey:
+		p.input = append(p.input, key)
+	case interfaces.KeyTypeBackspace:
+		if len(p.input) == 0 {
+			p.searching = false
+			return
+		}
+		p.input = p.input[:len(p.input)-1]
+	case interfaces.KeyTypeEnter:
+		p.searching = false
+		if len(p.input) > 0 {
+			p.pattern = string(p.input)
+		}
+		p.find(p.top, 1)
+	case interfaces.KeyTypeEscape, interfaces.KeyTypeCancel:
+		p.searching = false
+	}
+}
+
+// find moves the view to the first line matching the pattern from the line start, in the direction dir.
+// The search wraps around the text.
+func (p *Pager) find(start int, dir int) {
+	if len(p.pattern) == 0 {
+		p.message = "no previous search"
+		return
+	}
+	size := len(p.lines)
+	if size == 0 {
+		size = len(p.text)
+	}
+	pattern := strings.ToLower(p.pattern)
+	for i := 0; i < size; i++ {
+		idx := ((start+i*dir)%size + size) % size
+		if strings.Contains(strings.ToLower(p.line(idx)), pattern) {
+			p.top = idx
+			p.scroll(0)
+			return
+		}
+	}
+	p.message = "pattern not found: " + p.pattern
+}
+
+// line returns a line as drawn, the text lines before the first paint
+func (p *Pager) line(idx int) string {
+	if p.lines == nil {
+		return p.text[idx]
+	}
+	return p.lines[idx]
+}
+
+func (p *Pager) scroll(n int) {
+	p.top += n
+	if max := len(p.lines) - p.rows; p.top > max {
+		p.top = max
+	}
+	if p.top < 0 {
+		p.top = 0
+	}
+}
+
+// Draw writes the visible lines and a status line at the bottom of the surface.
+func (p *Pager) Draw(surface interfaces.ISurface) {
+	rows, columns := surface.GetSize()
+	if rows < 2 || columns < 1 {
+		return
+	}
+	if columns != p.width {
+		p.width = columns
+		p.lines = wrap(p.text, columns)
+	}
+	p.rows = rows - 1
+	p.scroll(0)
+
+	for y := 0; y < p.rows && p.top+y < len(p.lines); y++ {
+		line := []rune(p.lines[p.top+y])
+		matched := p.matches(line)
+		for x, r := range line {
+			if matched[x] {
+				surface.DrawColor(y, x, r, interfaces.ColorBlackDef, interfaces.ColorYellowDef, interfaces.ModeNormal)
+			} else {
+				surface.Draw(y, x, r)
+			}
+		}
+	}
+
+	var status string
+	switch {
+	case p.searching:
+		status = "/" + string(p.input)
+	case len(p.message) > 0:
+		status = " " + p.message + " "
+	default:
+		last := p.top + p.rows
+		if last > len(p.lines) {
+			last = len(p.lines)
+		}
+		status = fmt.Sprintf(" lines %d-%d/%d  space next, b back, / search, q quit ", p.top+1, last, len(p.lines))
+	}
+	for x, r := range []rune(status) {
+		if x >= columns {
+			break
+		}
+		surface.DrawColor(rows-1, x, r, interfaces.ColorBlackDef, interfaces.ColorWhiteDef, interfaces.ModeNormal)
+	}
+}
+
+// matches marks the runes of line that are part of a match of the pattern
+func (p *Pager) matches(line []rune) []bool {
+	marked := make([]bool, len(line))
+	pattern := []rune(strings.ToLower(p.pattern))
+	if len(pattern) == 0 {
+		return marked
+	}
+	lower := []rune(strings.ToLower(string(line)))
+	if len(lower) != len(line) {
+		return marked
+	}
+	for i := 0; i+len(pattern) <= len(lower); i++ {
+		if string(lower[i:i+len(pattern)]) == string(pattern) {
+			for j := range pattern {
+				marked[i+j] = true
+			}
+		}
+	}
+	return marked
+}
+
+// Fits determines if text is shown in full on a screen of the given size, leaving a line for the prompt.
+func Fits(text string, width int, height int) bool {
+	if width < 1 {
+		return true
+	}
+	return len(wrap(splitText(text), width)) < height
+}
+
+// wrap splits the lines longer than width, at the last space when there is one.
+// The continuation lines keep the indentation of the line.
+func wrap(text []string, width int) []string {
+	var out []string
+	for _, line := range text {
+		r := []rune(line)
+		indent := len(r) - len([]rune(strings.TrimLeft(line, " ")))
+		if indent > width/2 {
+			indent = 0
+		}
+		for len(r) > width {
+			cut := width
+			if idx := strings.LastIndex(string(r[:width]), " "); idx > indent {
+				cut = len([]rune(string(r[:width])[:idx]))
+			}
+			out = append(out, strings.TrimRight(string(r[:cut]), " "))
+			r = []rune(strings.Repeat(" ", indent) + strings.TrimLeft(string(r[cut:]), " "))
+		}
+		out = append(out, string(r))
+	}
+	return out
+}
+
+// Setup makes cmd a pager task, its Run opens the pager with Open.
+func Setup(cmd *cli.Command) {
+	cmd.Activate = true
+	cmd.ReadEvent = func(cmd *cli.Command, pid int, ctx interface{}, code int, key rune) {
+		r := cmd.GetRootContext()
+		p, ok := ctx.(*Pager)
+		if !ok {
+			return
+		}
+		if p.KeyEvent(code, key) {
+			r.PaintRequest(pid)
+			return
+		}
+		r.ClearScreen()
+		r.Deactivate(pid)
+	}
+	cmd.PaintEvent = func(cmd *cli.Command, pid int, ctx interface{}, surface interfaces.ISurface) {
+		if p, ok := ctx.(*Pager); ok {
+			p.Draw(surface)
+		}
+	}
+}
+
+// Open shows text in the pager of the task pid, created by a command set up with Setup.
+func Open(r interfaces.IContext, pid int, caption string, text string) {
+	r.SetContext(pid, New(text))
+	r.SetCaption(pid, caption)
+	r.PaintRequest(pid)
+}
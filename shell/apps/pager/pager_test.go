@@ -0,0 +1,250 @@
+package pager
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/markel1974/goshell/shell/interfaces"
+)
+
+// testSurface keeps the runes drawn, and the ones drawn on a yellow background
+type testSurface struct {
+	rows    [][]rune
+	matched map[[2]int]bool
+}
+
+func newTestSurface(rows int, columns int) *testSurface {
+	s := &testSurface{matched: make(map[[2]int]bool)}
+	for i := 0; i < rows; i++ {
+		s.rows = append(s.rows, []rune(strings.Repeat(" ", columns)))
+	}
+	return s
+}
+
+func (s *testSurface) GetSize() (int, int) { return len(s.rows), len(s.rows[0]) }
+func (s *testSurface) Draw(row int, column int, c rune) {
+	s.rows[row][column] = c
+}
+func (s *testSurface) DrawColor(row int, column int, c rune, _ interfaces.ColorDef, bg interfaces.ColorDef, _ interfaces.ColorMode) {
+	s.Draw(row, column, c)
+	if bg == interfaces.ColorYellowDef {
+		s.matched[[2]int{row, column}] = true
+	}
+}
+func (s *testSurface) DrawText(int, int, string) {}
+func (s *testSurface) DrawTextColor(int, int, string, interfaces.ColorDef, interfaces.ColorDef, interfaces.ColorMode) {
+}
+func (s *testSurface) DrawSeries([]float64, int, int, float64, float64) {}
+
+func (s *testSurface) row(n int) string {
+	return strings.TrimRight(string(s.rows[n]), " ")
+}
+
+// numberedText returns n lines, line 0 to line n-1
+func numberedText(n int) string {
+	var lines []string
+	for i := 0; i < n; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func press(p *Pager, keys string) bool {
+	open := true
+	for _, key := range keys {
+		open = p.KeyEvent(int(interfaces.KeyTypeKey), key)
+	}
+	return open
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{"short", "short", []string{"short"}},
+		{"exact", "0123456789", []string{"0123456789"}},
+		{"at the spaces", "hello world again", []string{"hello", "world", "again"}},
+		{"without spaces", "abcdefghijkl", []string{"abcdefghij", "kl"}},
+		{"indented", "  ab cdefghijk", []string{"  ab", "  cdefghij", "  k"}},
+		{"empty", "", []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if out := wrap([]string{tt.line}, 10); strings.Join(out, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("got %q, want %q", out, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFits(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		width    int
+		height   int
+		expected bool
+	}{
+		{"fits", "a\nb", 10, 3, true},
+		{"no line for the prompt", "a\nb", 10, 2, false},
+		{"trailing newline", "a\nb\n", 10, 3, true},
+		{"wrapped", "hello world", 5, 3, true},
+		{"wrapped too long", "hello world", 5, 2, false},
+		{"tab expanded", "\tabcdefg", 10, 2, false},
+		{"unknown width", numberedText(100), 0, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if fits := Fits(tt.text, tt.width, tt.height); fits != tt.expected {
+				t.Errorf("got %v, want %v", fits, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPager_Scroll(t *testing.T) {
+	p := New(numberedText(20))
+	// five lines of text and the status line
+	s := newTestSurface(6, 40)
+	p.Draw(s)
+	if s.row(0) != "line 0" || s.row(4) != "line 4" || !strings.Contains(s.row(5), "lines 1-5/20") {
+		t.Fatalf("unexpected first screen %q", s.rows)
+	}
+
+	steps := []struct {
+		name string
+		keys string
+		top  int
+	}{
+		{"next page", " ", 5},
+		{"next page f", "f", 10},
+		{"previous page", "b", 5},
+		{"down", "j", 6},
+		{"up", "k", 5},
+		{"top", "g", 0},
+		{"up at the top", "k", 0},
+		{"back at the top", "b", 0},
+		{"bottom", "G", 15},
+		{"next page at the bottom", " ", 15},
+		{"down at the bottom", "j", 15},
+	}
+	for _, step := range steps {
+		if !press(p, step.keys) {
+			t.Fatalf("%s: expected the pager open", step.name)
+		}
+		if p.top != step.top {
+			t.Fatalf("%s: got top %d, want %d", step.name, p.top, step.top)
+		}
+	}
+
+	p.KeyEvent(int(interfaces.KeyTypeCursor), rune(interfaces.CursorUpDef))
+	p.KeyEvent(int(interfaces.KeyTypeCursor), rune(interfaces.CursorUpDef))
+	p.KeyEvent(int(interfaces.KeyTypeCursor), rune(interfaces.CursorDownDef))
+	if p.top != 14 {
+		t.Errorf("expected the arrows to move by a line, got top %d", p.top)
+	}
+	p.KeyEvent(int(interfaces.KeyTypeEnter), '\n')
+	if p.top != 15 {
+		t.Errorf("expected enter to move down a line, got top %d", p.top)
+	}
+	s = newTestSurface(6, 40)
+	p.Draw(s)
+	if s.row(0) != "line 15" || s.row(4) != "line 19" || !strings.Contains(s.row(5), "lines 16-20/20") {
+		t.Errorf("unexpected last screen %q", s.rows)
+	}
+
+	if press(p, "q") || New("a").KeyEvent(int(interfaces.KeyTypeEscape), 27) {
+		t.Errorf("expected q and escape to close the pager")
+	}
+}
+
+func TestPager_ResizeWraps(t *testing.T) {
+	p := New("hello world\nagain")
+	s := newTestSurface(4, 5)
+	p.Draw(s)
+	if s.row(0) != "hello" || s.row(1) != "world" || s.row(2) != "again" {
+		t.Errorf("expected the text wrapped at the width, got %q", s.rows)
+	}
+	s = newTestSurface(4, 20)
+	p.Draw(s)
+	if s.row(0) != "hello world" || s.row(1) != "again" {
+		t.Errorf("expected the text wrapped again at the new width, got %q", s.rows)
+	}
+}
+
+func TestPager_Search(t *testing.T) {
+	text := "alpha\nBeta\ngamma\nbeta two\ndelta\n" + numberedText(10)
+	p := New(text)
+	s := newTestSurface(6, 40)
+	p.Draw(s)
+
+	if !press(p, "n") || p.message != "no previous search" {
+		t.Errorf("expected no previous search, got %q", p.message)
+	}
+
+	press(p, "/BE")
+	s = newTestSurface(6, 40)
+	p.Draw(s)
+	if s.row(5) != "/BE" {
+		t.Errorf("expected the pattern typed in the status line, got %q", s.row(5))
+	}
+	// backspace edits the pattern
+	p.KeyEvent(int(interfaces.KeyTypeBackspace), 8)
+	press(p, "ETA")
+	p.KeyEvent(int(interfaces.KeyTypeEnter), '\n')
+	if p.searching || p.pattern != "BETA" || p.top != 1 {
+		t.Fatalf("expected the view on the first match, got top %d pattern %q", p.top, p.pattern)
+	}
+
+	// the matches are highlighted, ignoring the case
+	s = newTestSurface(6, 40)
+	p.Draw(s)
+	if s.row(0) != "Beta" || !s.matched[[2]int{0, 0}] || !s.matched[[2]int{0, 3}] || !s.matched[[2]int{2, 3}] || s.matched[[2]int{2, 4}] {
+		t.Errorf("expected the matches highlighted, got %v", s.matched)
+	}
+
+	steps := []struct {
+		name string
+		keys string
+		top  int
+	}{
+		{"next", "n", 3},
+		{"next wraps", "n", 1},
+		{"previous wraps", "N", 3},
+		{"previous", "N", 1},
+	}
+	for _, step := range steps {
+		press(p, step.keys)
+		if p.top != step.top {
+			t.Fatalf("%s: got top %d, want %d", step.name, p.top, step.top)
+		}
+	}
+
+	press(p, "/zzz")
+	p.KeyEvent(int(interfaces.KeyTypeEnter), '\n')
+	if p.top != 1 || p.message != "pattern not found: zzz" {
+		t.Errorf("expected the view kept when nothing matches, got top %d %q", p.top, p.message)
+	}
+
+	// an empty pattern searches the previous one again
+	press(p, "/")
+	p.KeyEvent(int(interfaces.KeyTypeEnter), '\n')
+	if p.pattern != "zzz" {
+		t.Errorf("expected the previous pattern, got %q", p.pattern)
+	}
+
+	// escape and a backspace on an empty pattern leave the search
+	press(p, "/x")
+	p.KeyEvent(int(interfaces.KeyTypeEscape), 27)
+	if p.searching || p.pattern != "zzz" {
+		t.Errorf("expected escape to cancel the search")
+	}
+	press(p, "/")
+	p.KeyEvent(int(interfaces.KeyTypeBackspace), 8)
+	if p.searching {
+		t.Errorf("expected backspace to leave the empty search")
+	}
+}
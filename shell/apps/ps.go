@@ -17,16 +17,43 @@ package apps
 import (
 	"github.com/markel1974/goshell/shell/apps/commandcreator"
 	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"strconv"
 )
 
 func CreatePs(t commandcreator.ICreator) *cli.Command {
 	root := t.CreateCommand()
 	root.Use = "ps"
 	root.Short = "Processes"
-	root.Long = "Processes"
+	root.Long = "Processes, e.g. kill $(ps --name snake --pid-only)"
+	name := root.Flags().StringP("name", "n", "", "only the tasks with the given name")
+	pidOnly := root.Flags().BoolP("pid-only", "p", false, "print the pids only")
+	_ = root.RegisterFlagCompletionFunc("name", completeTaskNames)
+	root.Paged = true
+	root.InitOutputFlag()
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
 		r := cmd.GetRootContext()
-		r.WriteLn(r.TaskList())
+		var processes []interfaces.ProcessInfo
+		for _, p := range r.Processes() {
+			if len(*name) == 0 || p.Name == *name {
+				processes = append(processes, p)
+			}
+		}
+		if len(*name) > 0 && len(processes) == 0 {
+			cmd.SetExitCode(cli.ExitFailure)
+		}
+		if *pidOnly {
+			r.WriteLn("")
+			for _, p := range processes {
+				r.WriteLn(strconv.Itoa(p.Pid))
+			}
+			return
+		}
+		res := cli.NewTable("pid", "name", "command")
+		for _, p := range processes {
+			res.AddRow(p.Pid, p.Name, p.Line)
+		}
+		cmd.PrintResult(res)
 	}
 
 	return root
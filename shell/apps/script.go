@@ -0,0 +1,118 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"github.com/markel1974/goshell/shell/script"
+	"time"
+)
+
+const scriptHelp = "Scripts are read from the scripts embedded by the host or from the scripts directory, the extension .sh can be omitted. " +
+	"A script runs a command line per line and supports # comments, NAME=value, if COMMAND; then ... else ... fi, " +
+	"for NAME in WORDS; do ... done, sleep SECONDS and source SCRIPT"
+
+func CreateSource(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "source"
+	root.Short = "Run a script"
+	root.Long = "Run a script in the foreground, ctrl-c stops it. " + scriptHelp
+	setupScript(root)
+	return root
+}
+
+func CreateRun(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "run"
+	root.Short = "Run a script in the background"
+	root.Long = "Run a script as a background task, kill stops it. " + scriptHelp
+	root.Background = true
+	setupScript(root)
+	return root
+}
+
+func setupScript(root *cli.Command) {
+	root.Activate = true
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		if len(args) != 1 {
+			cmd.PrintErrf(cli.DefaultEol+"Usage: %s SCRIPT"+cli.DefaultEol, cmd.Name())
+			cmd.SetExitCode(cli.ExitUsage)
+			r.Deactivate(pid)
+			return
+		}
+		data, err := r.LoadScript(args[0])
+		if err != nil {
+			stopScript(cmd, pid, err)
+			return
+		}
+		s, err := script.Parse(args[0], data)
+		if err != nil {
+			stopScript(cmd, pid, err)
+			return
+		}
+		interpreter := script.NewInterpreter(&scriptExecutor{r: r}, s)
+		r.SetContext(pid, interpreter)
+		stepScript(cmd, pid, interpreter)
+	}
+	root.TimerEvent = func(cmd *cli.Command, pid int, tid int, ctx interface{}, interval int) {
+		stepScript(cmd, pid, ctx.(*script.Interpreter))
+	}
+}
+
+// stepScript runs the script up to the next sleep, then waits for a timer
+func stepScript(cmd *cli.Command, pid int, interpreter *script.Interpreter) {
+	r := cmd.GetRootContext()
+	d, err := interpreter.Run()
+	if err != nil {
+		stopScript(cmd, pid, err)
+		return
+	}
+	if interpreter.Done() {
+		cmd.SetExitCode(interpreter.Status())
+		r.Deactivate(pid)
+		return
+	}
+	ms := int(d / time.Millisecond)
+	r.CreateTimer(pid, ms, ms, 1)
+}
+
+func stopScript(cmd *cli.Command, pid int, err error) {
+	cmd.PrintErrf(cli.DefaultEol+"Error %s"+cli.DefaultEol, err.Error())
+	cmd.SetExitCode(cli.ExitFailure)
+	cmd.GetRootContext().Deactivate(pid)
+}
+
+type scriptExecutor struct {
+	r interfaces.IContext
+}
+
+func (e *scriptExecutor) Exec(line string) int {
+	return e.r.ExecLine(line)
+}
+
+func (e *scriptExecutor) Expand(text string) ([]string, error) {
+	return e.r.ExpandArgs(text)
+}
+
+func (e *scriptExecutor) SetVar(name string, value string) error {
+	return e.r.SetVar(name, value)
+}
+
+func (e *scriptExecutor) Load(name string) (string, error) {
+	return e.r.LoadScript(name)
+}
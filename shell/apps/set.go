@@ -0,0 +1,54 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"strings"
+)
+
+func CreateSet(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "set"
+	root.Short = "Set variables"
+	root.Long = "Set the session variables given as NAME=value, without arguments list them. Use them as $NAME, ${NAME} or ${NAME:-default}. " +
+		"The variables last for the session, export them to keep them in the user settings"
+	root.DisableFlagParsing = true
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		if len(args) == 0 {
+			r.WriteLn("")
+			for _, v := range r.ListVars(false) {
+				r.WriteLn(v)
+			}
+			return
+		}
+		for _, arg := range args {
+			idx := strings.Index(arg, "=")
+			if idx <= 0 {
+				cmd.PrintErrf(cli.DefaultEol+"invalid assignment %s, expected NAME=value"+cli.DefaultEol, arg)
+				cmd.SetExitCode(cli.ExitUsage)
+				return
+			}
+			if err := r.SetVar(arg[:idx], arg[idx+1:]); err != nil {
+				cmd.PrintErrf(cli.DefaultEol+"%s"+cli.DefaultEol, err.Error())
+				cmd.SetExitCode(cli.ExitFailure)
+				return
+			}
+		}
+	}
+	return root
+}
@@ -0,0 +1,88 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"github.com/markel1974/goshell/shell/interfaces"
+)
+
+// AskDoneType receives the line typed for a question, ok is false when the question has been cancelled
+type AskDoneType func(answer string, ok bool)
+
+// question is a line read for a command with the line editor, while the prompt is held
+type question struct {
+	prompt string
+	echo   bool
+	done   AskDoneType
+}
+
+// Ask shows prompt on a new line and reads a line with the line editor, without history and completion.
+// The typed characters are not shown when echo is false. done is called with the line on enter,
+// or when the question is cancelled with escape or CancelAsk.
+func (c *Shell) Ask(prompt string, echo bool, done AskDoneType) {
+	c.CancelAsk()
+	c.closeMenu(false)
+	c.resetBuffer()
+	c.question = &question{prompt: prompt, echo: c.echo, done: done}
+	c.echo = echo
+	_, _ = c.terminal.Write("\r\n")
+	c.writeLinePrompt()
+}
+
+// IsAsking reports if a question waits for its answer
+func (c *Shell) IsAsking() bool {
+	return c.question != nil
+}
+
+// CancelAsk cancels the question waiting for its answer, if any
+func (c *Shell) CancelAsk() {
+	if c.question != nil {
+		c.endQuestion("", false)
+	}
+}
+
+func (c *Shell) endQuestion(answer string, ok bool) {
+	c.moveTo(len(c.current))
+	q := c.question
+	c.question = nil
+	c.echo = q.echo
+	c.resetBuffer()
+	q.done(answer, ok)
+}
+
+// questionEvent handles a key while a question waits for its answer
+func (c *Shell) questionEvent(event *interfaces.KeyData) {
+	switch event.GetType() {
+	case interfaces.KeyTypeEnter:
+		c.endQuestion(string(c.current), true)
+	case interfaces.KeyTypeEscape:
+		c.endQuestion("", false)
+	case interfaces.KeyTypeCancel:
+		c.textCancel()
+	case interfaces.KeyTypeBackspace:
+		c.textBackspace()
+	case interfaces.KeyTypeKey:
+		c.keyPressed(event.Key)
+	case interfaces.KeyTypeCursor:
+		switch interfaces.CursorCodeDef(event.Key) {
+		case interfaces.CursorLeftDef, interfaces.CursorRightDef, interfaces.CursorHomeDef, interfaces.CursorEndDef:
+			c.cursorPressed(interfaces.CursorCodeDef(event.Key))
+		}
+	case interfaces.KeyTypeCtrl:
+		c.ctrlPressed(event.Key)
+	case interfaces.KeyTypeAlt:
+		c.altPressed(event.Key)
+	}
+}
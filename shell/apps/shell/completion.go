@@ -0,0 +1,310 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	candidateGap      = 2
+	minCandidateWidth = 8
+)
+
+// candidateLayout places the completion candidates in columns, filled top to bottom like ls
+type candidateLayout struct {
+	items      []cli.Completion
+	valueWidth int
+	descWidth  int
+	cols       int
+	rows       int
+}
+
+func newCandidateLayout(items []cli.Completion, width int) *candidateLayout {
+	l := &candidateLayout{items: items}
+	for _, item := range items {
+		if n := len([]rune(item.Value)); n > l.valueWidth {
+			l.valueWidth = n
+		}
+		if n := len([]rune(item.Description)); n > l.descWidth {
+			l.descWidth = n
+		}
+	}
+	available := width - candidateGap
+	if available < minCandidateWidth {
+		available = minCandidateWidth
+	}
+	if l.valueWidth > available {
+		l.valueWidth = available
+	}
+	if l.descWidth > 0 {
+		if free := available - l.valueWidth - candidateGap; free < l.descWidth {
+			l.descWidth = free
+		}
+		if l.descWidth < 1 {
+			l.descWidth = 0
+		}
+	}
+	l.cols = width / l.cellWidth()
+	if l.cols < 1 {
+		l.cols = 1
+	}
+	l.rows = (len(items) + l.cols - 1) / l.cols
+	return l
+}
+
+func (l *candidateLayout) cellWidth() int {
+	w := l.valueWidth + candidateGap
+	if l.descWidth > 0 {
+		w += l.descWidth + candidateGap
+	}
+	return w
+}
+
+// index returns the candidate at the given row and column, or -1
+func (l *candidateLayout) index(row int, col int) int {
+	idx := col*l.rows + row
+	if idx >= len(l.items) {
+		return -1
+	}
+	return idx
+}
+
+// writeRow writes a row of candidates, the selected one is highlighted
+func (l *candidateLayout) writeRow(t interfaces.ITerminal, row int, selected int) {
+	for col := 0; col < l.cols; col++ {
+		idx := l.index(row, col)
+		if idx < 0 {
+			break
+		}
+		item := l.items[idx]
+		value := pad(truncate(item.Value, l.valueWidth), l.valueWidth)
+		if idx == selected {
+			_, _ = t.WriteColor(value, interfaces.ColorBlackDef, interfaces.ColorWhiteDef, interfaces.ModeNormal)
+		} else {
+			_, _ = t.WriteColor(value, interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+		}
+		_, _ = t.Write(strings.Repeat(" ", candidateGap))
+		if l.descWidth > 0 {
+			_, _ = t.WriteColor(pad(truncate(item.Description, l.descWidth), l.descWidth), interfaces.ColorGrayDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+			_, _ = t.Write(strings.Repeat(" ", candidateGap))
+		}
+	}
+}
+
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width])
+}
+
+func pad(s string, width int) string {
+	if n := len([]rune(s)); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// commonPrefix returns the longest prefix shared by the values of the candidates
+func commonPrefix(items []cli.Completion) string {
+	if len(items) == 0 {
+		return ""
+	}
+	prefix := items[0].Value
+	for _, item := range items[1:] {
+		for !strings.HasPrefix(item.Value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
+
+// completionMenu is the interactive candidate menu opened by a third tab
+type completionMenu struct {
+	layout   *candidateLayout
+	line     string
+	data     string
+	selected int
+	top      int
+	visible  int
+}
+
+func (c *Shell) complete() {
+	if c.pos != len(c.current) || c.ExecSuggestion == nil {
+		return
+	}
+	line := string(c.current)
+	data, candidates := c.ExecSuggestion(line)
+
+	switch {
+	case len(candidates) == 0:
+		c.tabCount = 0
+	case len(candidates) == 1:
+		c.tabCount = 0
+		value := candidates[0].Value
+		if !strings.HasSuffix(value, "=") && !strings.HasSuffix(value, cli.PathSeparator) {
+			value += " "
+		}
+		c.insertCompletion(line, data, value)
+	default:
+		if prefix := commonPrefix(candidates); len(prefix) > len(data) {
+			c.insertCompletion(line, data, prefix)
+			c.tabCount = 1
+			return
+		}
+		switch c.tabCount {
+		case 0:
+			c.tabCount = 1
+		case 1:
+			c.writeCandidates(candidates)
+			c.tabCount = 2
+		default:
+			c.openMenu(line, data, candidates)
+		}
+	}
+}
+
+func (c *Shell) insertCompletion(line string, data string, value string) {
+	if !strings.HasPrefix(value, data) {
+		return
+	}
+	line += value[len(data):]
+	c.DoRedraw(line)
+	c.history.SetDefault(line)
+}
+
+// writeCandidates lists the candidates below the prompt, then writes the prompt again
+func (c *Shell) writeCandidates(candidates []cli.Completion) {
+	l := newCandidateLayout(candidates, c.width)
+	rows := l.rows
+	if maxRows := c.height - 2; maxRows > 0 && rows > maxRows {
+		rows = maxRows
+	}
+	for row := 0; row < rows; row++ {
+		_, _ = c.terminal.Write("\r\n")
+		l.writeRow(c.terminal, row, -1)
+	}
+	if rows < l.rows {
+		hidden := len(candidates) - rows*l.cols
+		_, _ = c.terminal.WriteColor("\r\n"+strconv.Itoa(hidden)+" more, press tab for the menu", interfaces.ColorGrayDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+	}
+	_, _ = c.terminal.Write("\r\n")
+	line := c.current
+	c.current = nil
+	c.pos = 0
+	c.writeLinePrompt()
+	c.setLine(0, line, len(line))
+}
+
+func (c *Shell) openMenu(line string, data string, candidates []cli.Completion) {
+	l := newCandidateLayout(candidates, c.width)
+	visible := l.rows
+	if maxRows := c.height - 2; maxRows > 0 && visible > maxRows {
+		visible = maxRows
+	}
+	c.menu = &completionMenu{layout: l, line: line, data: data, visible: visible}
+	c.drawMenu()
+}
+
+// drawMenu writes the line with the selected candidate and the visible rows of the menu below it
+func (c *Shell) drawMenu() {
+	m := c.menu
+	row := m.selected % m.layout.rows
+	if row < m.top {
+		m.top = row
+	} else if row >= m.top+m.visible {
+		m.top = row - m.visible + 1
+	}
+
+	value := m.layout.items[m.selected].Value
+	line := m.line
+	if strings.HasPrefix(value, m.data) {
+		line += value[len(m.data):]
+	}
+	c.rewrite([]rune(line), len([]rune(line)))
+	for r := m.top; r < m.top+m.visible; r++ {
+		_, _ = c.terminal.Write("\r\n")
+		m.layout.writeRow(c.terminal, r, m.selected)
+	}
+	// back at the end of the line, on its last row
+	for r := 0; r < m.visible; r++ {
+		_, _ = c.terminal.MoveCursorUp()
+	}
+	end := c.offset(c.pos)
+	_, _ = c.terminal.Write("\r")
+	c.moveOffset(end-end%c.width, end)
+}
+
+// closeMenu erases the menu, the line keeps the selected candidate unless restore is set
+func (c *Shell) closeMenu(restore bool) {
+	m := c.menu
+	if m == nil {
+		return
+	}
+	c.menu = nil
+	line := c.current
+	if restore {
+		line = []rune(m.line)
+	}
+	// the menu below the line is erased with it
+	c.rewrite(line, len(line))
+	c.history.SetDefault(string(line))
+	c.tabCount = 0
+}
+
+// menuEvent handles a key while the menu is open, it returns false if the key must be handled by the editor
+func (c *Shell) menuEvent(event *interfaces.KeyData) bool {
+	m := c.menu
+	n := len(m.layout.items)
+	switch event.GetType() {
+	case interfaces.KeyTypeTab:
+		m.selected = (m.selected + 1) % n
+	case interfaces.KeyTypeCursor:
+		switch interfaces.CursorCodeDef(event.Key) {
+		case interfaces.CursorUpDef:
+			m.selected = (m.selected - 1 + n) % n
+		case interfaces.CursorDownDef:
+			m.selected = (m.selected + 1) % n
+		case interfaces.CursorLeftDef:
+			if m.selected-m.layout.rows >= 0 {
+				m.selected -= m.layout.rows
+			}
+		case interfaces.CursorRightDef:
+			if m.selected+m.layout.rows < n {
+				m.selected += m.layout.rows
+			}
+		}
+	case interfaces.KeyTypeEnter:
+		c.closeMenu(false)
+		return true
+	case interfaces.KeyTypeEscape, interfaces.KeyTypeBackspace, interfaces.KeyTypeCancel:
+		c.closeMenu(true)
+		return true
+	default:
+		c.closeMenu(false)
+		return false
+	}
+	c.drawMenu()
+	return true
+}
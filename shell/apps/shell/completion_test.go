@@ -0,0 +1,166 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/markel1974/goshell/shell/cli"
+)
+
+func completions(values ...string) []cli.Completion {
+	var out []cli.Completion
+	for _, v := range values {
+		out = append(out, cli.Completion{Value: v})
+	}
+	return out
+}
+
+func numbered(prefix string, n int) []cli.Completion {
+	var out []cli.Completion
+	for i := 0; i < n; i++ {
+		out = append(out, cli.Completion{Value: fmt.Sprintf("%s%d", prefix, i)})
+	}
+	return out
+}
+
+func TestCandidateLayout(t *testing.T) {
+	tests := []struct {
+		name       string
+		items      []cli.Completion
+		width      int
+		valueWidth int
+		descWidth  int
+		cols       int
+		rows       int
+	}{
+		{"one row", completions("a", "bb", "ccc"), 20, 3, 0, 4, 1},
+		{"columns", numbered("item", 10), 20, 5, 0, 2, 5},
+		{"one column", numbered("item", 3), 6, 5, 0, 1, 3},
+		{"description truncated", []cli.Completion{{Value: "abc", Description: "a long description"}}, 20, 3, 13, 1, 1},
+		{"description dropped", []cli.Completion{{Value: "abcdefg", Description: "x"}}, 10, 7, 0, 1, 1},
+		{"value truncated", completions("abcdefghijkl"), 5, 8, 0, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newCandidateLayout(tt.items, tt.width)
+			if l.valueWidth != tt.valueWidth || l.descWidth != tt.descWidth || l.cols != tt.cols || l.rows != tt.rows {
+				t.Errorf("got value %d desc %d cols %d rows %d, want value %d desc %d cols %d rows %d",
+					l.valueWidth, l.descWidth, l.cols, l.rows, tt.valueWidth, tt.descWidth, tt.cols, tt.rows)
+			}
+		})
+	}
+}
+
+func TestCandidateLayout_Index(t *testing.T) {
+	// the columns are filled top to bottom
+	l := newCandidateLayout(completions("a", "b", "c"), 6)
+	if l.cols != 2 || l.rows != 2 {
+		t.Fatalf("expected 2 columns of 2 rows, got %d of %d", l.cols, l.rows)
+	}
+	for _, tt := range []struct{ row, col, index int }{{0, 0, 0}, {1, 0, 1}, {0, 1, 2}, {1, 1, -1}} {
+		if index := l.index(tt.row, tt.col); index != tt.index {
+			t.Errorf("index(%d, %d) = %d, want %d", tt.row, tt.col, index, tt.index)
+		}
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []cli.Completion
+		expected string
+	}{
+		{"none", nil, ""},
+		{"one", completions("stats"), "stats"},
+		{"shared", completions("stats", "start", "stop"), "st"},
+		{"nothing shared", completions("ps", "kill"), ""},
+		{"multibyte", completions("caffè", "caffé"), "caff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if prefix := commonPrefix(tt.items); prefix != tt.expected {
+				t.Errorf("got %q, want %q", prefix, tt.expected)
+			}
+		})
+	}
+}
+
+// newMenuShell returns a shell editing "x item", completed by ten candidates in two columns
+// of five rows, three rows fit below the line
+func newMenuShell() (*Shell, func(keys string)) {
+	c, terminal := newEditorShell("")
+	c.state = stateAuthenticated
+	c.width = 20
+	c.height = 5
+	c.ExecSuggestion = func(in string) (string, []cli.Completion) {
+		return in[strings.LastIndex(in, " ")+1:], numbered("item", 10)
+	}
+	terminal.Scan([]byte("x item"))
+	return c, func(keys string) { terminal.Scan([]byte(keys)) }
+}
+
+func TestShell_CompletionMenu(t *testing.T) {
+	c, keys := newMenuShell()
+	keys("\t\t")
+	if c.menu != nil {
+		t.Fatal("expected the menu to open at the third tab")
+	}
+	keys("\t")
+	if c.menu == nil || c.menu.visible != 3 || string(c.current) != "x item0" {
+		t.Fatalf("expected the menu open on the first candidate, got %q", string(c.current))
+	}
+
+	steps := []struct {
+		name     string
+		keys     string
+		selected int
+		top      int
+	}{
+		{"tab", "\t", 1, 0},
+		{"down scrolls", "\x1b[B\x1b[B\x1b[B", 4, 2},
+		{"right", "\x1b[C", 9, 2},
+		{"right at the last column", "\x1b[C", 9, 2},
+		{"left", "\x1b[D", 4, 2},
+		{"up", "\x1b[A", 3, 2},
+		{"up scrolls", "\x1b[A\x1b[A", 1, 1},
+		{"up wraps", "\x1b[A\x1b[A", 9, 2},
+		{"tab wraps", "\t", 0, 0},
+	}
+	for _, step := range steps {
+		keys(step.keys)
+		if c.menu.selected != step.selected || c.menu.top != step.top {
+			t.Fatalf("%s: got selected %d top %d, want selected %d top %d", step.name, c.menu.selected, c.menu.top, step.selected, step.top)
+		}
+		if line := "x " + c.menu.layout.items[step.selected].Value; string(c.current) != line {
+			t.Fatalf("%s: got line %q, want %q", step.name, string(c.current), line)
+		}
+	}
+
+	keys("\t\x1b[C\r")
+	if c.menu != nil || string(c.current) != "x item6" {
+		t.Errorf("expected enter to keep the selected candidate, got %q", string(c.current))
+	}
+}
+
+func TestShell_CompletionMenuClose(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     string
+		expected string
+	}{
+		{"escape restores the line", "\t\x1b", "x item"},
+		{"backspace restores the line", "\t\x7f", "x item"},
+		{"a key is typed after the candidate", "\tz", "x item1z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, keys := newMenuShell()
+			keys("\t\t\t")
+			keys(tt.keys)
+			if c.menu != nil || string(c.current) != tt.expected {
+				t.Errorf("got %q, want %q with the menu closed", string(c.current), tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package shell
+
+import (
+	"unicode"
+
+	"github.com/markel1974/goshell/shell/interfaces"
+)
+
+// killRingSize is the number of killed texts kept for ctrl-Y and alt-Y
+const killRingSize = 16
+
+// ctrlPressed handles the emacs style bindings of the control keys
+func (c *Shell) ctrlPressed(key rune) {
+	switch key {
+	case 1: // ctrl-A
+		c.moveTo(0)
+	case 2: // ctrl-B
+		c.cursorPressed(interfaces.CursorLeftDef)
+	case 4: // ctrl-D
+		if c.pos < len(c.current) {
+			c.textCancel()
+		}
+	case 5: // ctrl-E
+		c.moveTo(len(c.current))
+	case 6: // ctrl-F
+		c.cursorPressed(interfaces.CursorRightDef)
+	case 11: // ctrl-K
+		c.killText(c.pos, len(c.current))
+	case 12: // ctrl-L
+		c.redrawScreen()
+	case 14: // ctrl-N
+		if c.question == nil {
+			c.cursorPressed(interfaces.CursorDownDef)
+		}
+	case 16: // ctrl-P
+		if c.question == nil {
+			c.cursorPressed(interfaces.CursorUpDef)
+		}
+	case 18: // ctrl-R
+		c.startSearch()
+	case 20: // ctrl-T
+		c.transpose()
+	case 21: // ctrl-U
+		c.killText(0, c.pos)
+	case 23: // ctrl-W
+		c.killText(c.spaceWordStart(c.pos), c.pos)
+	case 25: // ctrl-Y
+		c.yank()
+	}
+}
+
+// altPressed handles the emacs style bindings of the keys pressed with alt, 127 is alt-backspace
+func (c *Shell) altPressed(key rune) {
+	switch key {
+	case 'b', 'B':
+		c.moveTo(c.wordStart(c.pos))
+	case 'f', 'F':
+		c.moveTo(c.wordEnd(c.pos))
+	case 'd', 'D':
+		c.killText(c.pos, c.wordEnd(c.pos))
+	case 'y', 'Y':
+		c.yankPop()
+	case 127:
+		c.killText(c.wordStart(c.pos), c.pos)
+	}
+}
+
+// offset returns the position on the screen of the character pos of the line, counted from the
+// start of the prompt. The lines wider than the terminal wrap, a row holds width characters.
+func (c *Shell) offset(pos int) int {
+	if !c.echo {
+		return c.promptWidth
+	}
+	return c.promptWidth + pos
+}
+
+// moveOffset moves the cursor between two offsets, across the rows of a wrapped line
+func (c *Shell) moveOffset(from int, to int) {
+	fromRow, toRow := from/c.width, to/c.width
+	for ; fromRow > toRow; fromRow-- {
+		_, _ = c.terminal.MoveCursorUp()
+	}
+	for ; fromRow < toRow; fromRow++ {
+		_, _ = c.terminal.MoveCursorDown()
+	}
+	fromCol, toCol := from%c.width, to%c.width
+	if toCol == 0 && fromCol > 0 {
+		_, _ = c.terminal.Write("\r")
+		fromCol = 0
+	}
+	for ; fromCol > toCol; fromCol-- {
+		_, _ = c.terminal.MoveCursorLeft()
+	}
+	for ; fromCol < toCol; fromCol++ {
+		_, _ = c.terminal.MoveCursorRight()
+	}
+}
+
+// moveTo moves the cursor to pos in the line
+func (c *Shell) moveTo(pos int) {
+	c.moveOffset(c.offset(c.pos), c.offset(pos))
+	c.pos = pos
+}
+
+// wrap moves the cursor to the next row when the text written ends at the last column, the terminals
+// keep the cursor on the last column until the next character
+func (c *Shell) wrap(offset int) {
+	if offset > 0 && offset%c.width == 0 {
+		_, _ = c.terminal.Write("\r\n")
+	}
+}
+
+// setLine replaces the line from the position from, which is kept on the screen, and moves the cursor to pos.
+// A highlighted line is written again from the start, a change can give a new color to the words before it.
+func (c *Shell) setLine(from int, line []rune, pos int) {
+	if c.highlighting() {
+		from = 0
+	}
+	c.moveTo(from)
+	if c.echo {
+		c.writeLine(line, from)
+		end := c.promptWidth + len(line)
+		if len(line) > from {
+			c.wrap(end)
+		}
+		if len(line) < len(c.current) {
+			_, _ = c.terminal.ClearToEnd()
+		}
+		c.moveOffset(end, c.promptWidth+pos)
+	}
+	c.current = line
+	c.pos = pos
+}
+
+// edit is setLine for a change typed by the user, the line is kept when moving in the history
+func (c *Shell) edit(from int, line []rune, pos int) {
+	c.setLine(from, line, pos)
+	if c.state == stateAuthenticated && c.question == nil && c.search == nil {
+		c.history.SetDefault(string(c.current))
+	}
+}
+
+// rewrite writes again the prompt and the line, from the start of the prompt
+func (c *Shell) rewrite(line []rune, pos int) {
+	c.moveOffset(c.offset(c.pos), 0)
+	_, _ = c.terminal.ClearToEnd()
+	c.writeLinePrompt()
+	c.current = nil
+	c.pos = 0
+	c.setLine(0, line, pos)
+}
+
+// writeLinePrompt writes the prompt of the line being edited: the one of a question, of the search
+// in the history, of a continued command or the prompt of the shell
+func (c *Shell) writeLinePrompt() {
+	switch {
+	case c.search != nil:
+		c.writeSearchPrompt()
+	case c.question != nil:
+		_, _ = c.terminal.WriteColor(c.question.prompt, interfaces.ColorCyanDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+		c.promptWidth = len([]rune(c.question.prompt))
+	case len(c.continued) > 0:
+		_, _ = c.terminal.WriteColor(continuationPrompt, interfaces.ColorGreenDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+		c.promptWidth = len([]rune(continuationPrompt))
+	default:
+		c.writePrompt()
+	}
+	c.wrap(c.promptWidth)
+}
+
+// killText removes the text between start and end and saves it in the kill ring,
+// the texts of consecutive kills are joined
+func (c *Shell) killText(start int, end int) {
+	if start >= end {
+		return
+	}
+	text := string(c.current[start:end])
+	if c.killing && len(c.killRing) > 0 {
+		last := len(c.killRing) - 1
+		if start < c.pos {
+			c.killRing[last] = text + c.killRing[last]
+		} else {
+			c.killRing[last] += text
+		}
+	} else {
+		c.killRing = append(c.killRing, text)
+		if len(c.killRing) > killRingSize {
+			c.killRing = c.killRing[1:]
+		}
+	}
+	c.killed = true
+
+	line := append(append([]rune{}, c.current[:start]...), c.current[end:]...)
+	c.edit(start, line, start)
+}
+
+// yank inserts the last killed text at the cursor
+func (c *Shell) yank() {
+	if len(c.killRing) == 0 {
+		return
+	}
+	c.yankIndex = len(c.killRing) - 1
+	c.yankText(c.pos, c.pos)
+}
+
+// yankPop replaces the text just yanked with the text killed before it, the kill ring rotates
+func (c *Shell) yankPop() {
+	if !c.yanking || len(c.killRing) == 0 {
+		return
+	}
+	c.yankIndex = (c.yankIndex + len(c.killRing) - 1) % len(c.killRing)
+	c.yankText(c.yankStart, c.pos)
+}
+
+// yankText replaces the characters from start to end with the entry yankIndex of the kill ring
+func (c *Shell) yankText(start int, end int) {
+	text := []rune(c.killRing[c.yankIndex])
+	line := append(append(append([]rune{}, c.current[:start]...), text...), c.current[end:]...)
+	c.yankStart = start
+	c.yanked = true
+	c.edit(start, line, start+len(text))
+}
+
+// transpose swaps the character before the cursor with the one under it, at the end of the line
+// it swaps the last two characters
+func (c *Shell) transpose() {
+	if c.pos == 0 || len(c.current) < 2 {
+		return
+	}
+	pos := c.pos
+	if pos == len(c.current) {
+		pos--
+	}
+	line := append([]rune{}, c.current...)
+	line[pos-1], line[pos] = line[pos], line[pos-1]
+	c.edit(pos-1, line, pos+1)
+}
+
+// redrawScreen clears the screen and writes the prompt and the line at the top
+func (c *Shell) redrawScreen() {
+	_, _ = c.terminal.ClearScreen()
+	_, _ = c.terminal.MoveCursorTopLeft()
+	line, pos := c.current, c.pos
+	c.current = nil
+	c.pos = 0
+	c.writeLinePrompt()
+	c.setLine(0, line, pos)
+}
+
+// wordStart returns the start of the word before pos, the words are made of letters and digits
+func (c *Shell) wordStart(pos int) int {
+	for pos > 0 && !isWordRune(c.current[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(c.current[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// wordEnd returns the end of the word after pos
+func (c *Shell) wordEnd(pos int) int {
+	for pos < len(c.current) && !isWordRune(c.current[pos]) {
+		pos++
+	}
+	for pos < len(c.current) && isWordRune(c.current[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// spaceWordStart returns the start of the word before pos, the words are separated by spaces
+func (c *Shell) spaceWordStart(pos int) int {
+	for pos > 0 && unicode.IsSpace(c.current[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(c.current[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,70 @@
+package shell
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/markel1974/goshell/shell/interfaces"
+	"github.com/markel1974/goshell/shell/terminal/vt100"
+)
+
+// newEditorShell returns a shell editing line with the cursor at the end, the keys typed in the
+// terminal go to the line editor
+func newEditorShell(line string) (*Shell, *vt100.VT100) {
+	terminal := vt100.NewVt100(&bytes.Buffer{})
+	c := &Shell{
+		history:  NewHistoryHandler(DefaultHistorySize, false),
+		echo:     true,
+		width:    80,
+		height:   24,
+		terminal: terminal,
+	}
+	terminal.SetKeyFunc(func(event *interfaces.KeyData) { c.KeyEvent(event) })
+	terminal.Scan([]byte(line))
+	return c, terminal
+}
+
+func TestShell_Editor(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		keys     string
+		expected string
+		pos      int
+	}{
+		{"typed", "echo hi", "", "echo hi", 7},
+		{"start and end", "echo hi", "\x01x\x05y", "xecho hiy", 9},
+		{"back and forward", "echo hi", "\x02\x02\x02x\x06_", "echox _hi", 7},
+		{"word back", "echo one-two three", "\x1bb\x1bb|", "echo one-|two three", 10},
+		{"word forward", "echo one-two three", "\x01\x1bf\x1bf|", "echo one|-two three", 9},
+		{"kill to the end", "echo one two", "\x01\x1bf\x0b", "echo", 4},
+		{"kill to the start", "echo one two", "\x1bb\x15", "two", 0},
+		{"kill a spaced word", "echo one-two three ", "\x17", "echo one-two ", 13},
+		{"kill the next word", "echo one-two", "\x01\x1bf\x1bd", "echo-two", 4},
+		{"kill the previous word", "echo one-two", "\x1b\x7f", "echo one-", 9},
+		{"delete", "echo hi", "\x02\x04", "echo h", 6},
+		{"delete at the end", "echo hi", "\x04", "echo hi", 7},
+		{"transpose", "echo hi", "\x02\x14", "echo ih", 7},
+		{"transpose at the end", "echo hi", "\x14", "echo ih", 7},
+		{"yank", "echo one two", "\x17\x01\x19 ", "two echo one ", 4},
+		{"yank twice", "echo hi", "\x17\x19\x19", "echo hihi", 9},
+		{"yank empty ring", "echo hi", "\x19", "echo hi", 7},
+		{"consecutive kills are joined", "echo one two", "\x17\x17\x19", "echo one two", 12},
+		{"consecutive kills back and forth", "echo one two", "\x1bb\x0b\x15\x19", "echo one two", 12},
+		{"separate kills", "echo one two", "\x17\x02\x17\x05\x19", "echo  one", 9},
+		{"yank pop", "echo one two", "\x17\x02\x17\x05\x19\x1by", "echo  two", 9},
+		{"yank pop rotates", "echo one two", "\x17\x02\x17\x05\x19\x1by\x1by", "echo  one", 9},
+		{"yank pop in the line", "a b c", "\x17\x02\x17\x01\x19\x1by", "ca  ", 1},
+		{"yank pop without yank", "echo one two", "\x17\x1by", "echo one ", 9},
+		{"yank pop after a key", "echo one two", "\x17\x02\x17\x05\x19x\x1by", "echo  onex", 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, terminal := newEditorShell(tt.line)
+			terminal.Scan([]byte(tt.keys))
+			if string(c.current) != tt.expected || c.pos != tt.pos {
+				t.Errorf("got %q at %d, want %q at %d", string(c.current), c.pos, tt.expected, tt.pos)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package shell
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// expandHistory replaces the history references of line: !! is the previous command, !n the command
+// at index n of the history, !-n the n-th previous command and !prefix the last command starting
+// with prefix. A line ^old^new is the previous command with old replaced by new.
+// The references are not expanded inside single quotes or after a backslash.
+// It returns true when line has been changed.
+func (c *Shell) expandHistory(line string) (string, bool, error) {
+	if strings.HasPrefix(line, "^") {
+		return c.substituteHistory(line)
+	}
+	if !strings.Contains(line, "!") {
+		return line, false, nil
+	}
+
+	in := []rune(line)
+	var out strings.Builder
+	expanded := false
+	quoted := false
+	for i := 0; i < len(in); i++ {
+		r := in[i]
+		switch {
+		case r == '\\' && !quoted && i+1 < len(in):
+			out.WriteRune(r)
+			i++
+			out.WriteRune(in[i])
+			continue
+		case r == '\'':
+			quoted = !quoted
+		case r == '!' && !quoted && i+1 < len(in):
+			end := i + 2
+			if in[i+1] != '!' {
+				end = i + 1
+				for end < len(in) && !strings.ContainsRune(" \t=;|&<>()'\"", in[end]) {
+					end++
+				}
+			}
+			if end == i+1 {
+				break
+			}
+			event := string(in[i+1 : end])
+			text, ok := c.historyEvent(event)
+			if !ok {
+				return line, false, fmt.Errorf("!%s: event not found", event)
+			}
+			out.WriteString(text)
+			expanded = true
+			i = end - 1
+			continue
+		}
+		out.WriteRune(r)
+	}
+	return out.String(), expanded, nil
+}
+
+// historyEvent returns the command referenced by the text following a '!'
+func (c *Shell) historyEvent(event string) (string, bool) {
+	l := c.history.Len()
+	if event == "!" {
+		return c.history.GetHistoryAtPos(l - 1)
+	}
+	if n, err := strconv.Atoi(event); err == nil {
+		if n < 0 {
+			n = l + n
+		}
+		return c.history.GetHistoryAtPos(n)
+	}
+	idx := c.history.Search(func(line string) bool {
+		return strings.HasPrefix(line, event)
+	}, l)
+	return c.history.GetHistoryAtPos(idx)
+}
+
+// substituteHistory expands ^old^new, a trailing '^' is allowed
+func (c *Shell) substituteHistory(line string) (string, bool, error) {
+	parts := strings.SplitN(line[1:], "^", 3)
+	if len(parts) < 2 || len(parts[0]) == 0 {
+		return line, false, fmt.Errorf("%s: bad substitution", line)
+	}
+	last, ok := c.history.GetHistoryAtPos(c.history.Len() - 1)
+	if !ok {
+		return line, false, fmt.Errorf("^%s: event not found", parts[0])
+	}
+	if !strings.Contains(last, parts[0]) {
+		return line, false, fmt.Errorf("^%s: substitution failed", parts[0])
+	}
+	out := strings.Replace(last, parts[0], parts[1], 1)
+	if len(parts) == 3 {
+		out += parts[2]
+	}
+	return out, true, nil
+}
@@ -0,0 +1,75 @@
+package shell
+
+import "testing"
+
+// newHistoryShell returns a shell whose history holds lines, the first one at index 0
+func newHistoryShell(lines ...string) *Shell {
+	c := &Shell{history: NewHistoryHandler(DefaultHistorySize, false)}
+	for _, line := range lines {
+		c.history.AddToHistory(line)
+		c.history.Finish(0)
+	}
+	return c
+}
+
+func TestShell_ExpandHistory(t *testing.T) {
+	history := []string{"stats memory", "ps", "echo a b", "kill 12"}
+	tests := []struct {
+		name     string
+		history  []string
+		line     string
+		expected string
+		expanded bool
+		err      string
+	}{
+		{"no reference", history, "echo hi", "echo hi", false, ""},
+		{"previous", history, "!!", "kill 12", true, ""},
+		{"previous with args", history, "!! --help", "kill 12 --help", true, ""},
+		{"previous in a pipeline", history, "echo x | !!", "echo x | kill 12", true, ""},
+		{"index", history, "!1", "ps", true, ""},
+		{"first index", history, "!0 -v", "stats memory -v", true, ""},
+		{"relative", history, "!-2", "echo a b", true, ""},
+		{"relative last", history, "!-1", "kill 12", true, ""},
+		{"prefix", history, "!st", "stats memory", true, ""},
+		{"prefix last match", []string{"echo 1", "echo 2"}, "!ec", "echo 2", true, ""},
+		{"prefix before a delimiter", history, "!ps;echo", "ps;echo", true, ""},
+		{"several", history, "!1 && !!", "ps && kill 12", true, ""},
+		{"delimiter", history, "echo a!=b", "echo a!=b", false, ""},
+		{"bang and space", history, "echo ! x", "echo ! x", false, ""},
+		{"trailing bang", history, "echo hi!", "echo hi!", false, ""},
+		{"single quoted", history, "echo '!x' '!!'", "echo '!x' '!!'", false, ""},
+		{"after quotes", history, "echo 'a' !!", "echo 'a' kill 12", true, ""},
+		{"escaped", history, `echo \!!`, `echo \!!`, false, ""},
+		{"escaped then reference", history, `echo \!x !!`, `echo \!x kill 12`, true, ""},
+		{"index not found", history, "!9", "", false, "!9: event not found"},
+		{"relative not found", history, "!-9", "", false, "!-9: event not found"},
+		{"prefix not found", history, "!nothing", "", false, "!nothing: event not found"},
+		{"empty history", nil, "!!", "", false, "!!: event not found"},
+		{"substitution", history, "^12^13", "kill 13", true, ""},
+		{"substitution trailing caret", history, "^12^13^", "kill 13", true, ""},
+		{"substitution tail", history, "^12^13^ 14", "kill 13 14", true, ""},
+		{"substitution first only", []string{"echo a a"}, "^a^b", "echo b a", true, ""},
+		{"substitution removes", history, "^ 12^", "kill", true, ""},
+		{"substitution failed", history, "^x^y", "", false, "^x: substitution failed"},
+		{"substitution empty history", nil, "^a^b", "", false, "^a: event not found"},
+		{"bad substitution", history, "^a", "", false, "^a: bad substitution"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			line, expanded, err := newHistoryShell(tc.history...).expandHistory(tc.line)
+			if len(tc.err) > 0 {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if line != tc.expected || expanded != tc.expanded {
+				t.Errorf("expected %q %v, got %q %v", tc.expected, tc.expanded, line, expanded)
+			}
+		})
+	}
+}
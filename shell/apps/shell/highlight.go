@@ -0,0 +1,81 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+)
+
+// highlightColors are the colors of the parts of the command line
+var highlightColors = map[cli.HighlightKind]interfaces.ColorDef{
+	cli.HighlightCommand:     interfaces.ColorGreenDef,
+	cli.HighlightError:       interfaces.ColorRedDef,
+	cli.HighlightFlag:        interfaces.ColorCyanDef,
+	cli.HighlightInvalidFlag: interfaces.ColorCyanDef,
+	cli.HighlightString:      interfaces.ColorYellowDef,
+	cli.HighlightVariable:    interfaces.ColorMagentaDef,
+	cli.HighlightOperator:    interfaces.ColorBlueDef,
+}
+
+// highlighting reports if the line is a command being typed, the questions and the search are not highlighted
+func (c *Shell) highlighting() bool {
+	return c.ExecHighlight != nil && c.echo && c.state == stateAuthenticated && c.question == nil && c.search == nil
+}
+
+// highlight returns the spans of the line, the lines continued before it are part of the command
+func (c *Shell) highlight(line []rune) []cli.Span {
+	skip := len([]rune(c.continued))
+	var out []cli.Span
+	for _, s := range c.ExecHighlight(c.continued + string(line)) {
+		s.Start, s.End = s.Start-skip, s.End-skip
+		if s.End <= 0 {
+			continue
+		}
+		if s.Start < 0 {
+			s.Start = 0
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// writeLine writes the line from the position from, with the colors of its parts when highlighting
+func (c *Shell) writeLine(line []rune, from int) {
+	if !c.highlighting() {
+		_, _ = c.terminal.WriteColor(string(line[from:]), interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+		return
+	}
+	pos := from
+	for _, s := range c.highlight(line) {
+		if s.End <= pos {
+			continue
+		}
+		if s.Start > pos {
+			_, _ = c.terminal.Write(string(line[pos:s.Start]))
+		} else {
+			s.Start = pos
+		}
+		mode := interfaces.ModeNormal
+		if s.Kind == cli.HighlightInvalidFlag {
+			mode = interfaces.ModeUnderline
+		}
+		_, _ = c.terminal.WriteColor(string(line[s.Start:s.End]), highlightColors[s.Kind], interfaces.ColorNoneDef, mode)
+		pos = s.End
+	}
+	if pos < len(line) {
+		_, _ = c.terminal.Write(string(line[pos:]))
+	}
+}
@@ -11,24 +11,28 @@
  * See the License for the specific language governing permissions and
  * limitations under the License.
  */
-
 package shell
 
 import (
-	"encoding/json"
-	"io/ioutil"
-	"sync"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"log"
+	"strings"
+	"time"
 )
 
-var historySaveLock sync.Mutex
+// DefaultHistorySize is the number of commands kept in the history when no size is set
+const DefaultHistorySize = 128
 
 type HistoryHandler struct {
-	Queue    []string
+	Queue    []interfaces.HistoryEntry
 	queuePos int
 	max      uint
 	def      string
 	enabled  bool
 	autosave bool
+	session  string
+	pending  bool
+	path     string
 }
 
 func NewHistoryHandler(max uint, autosave bool) *HistoryHandler {
@@ -37,59 +41,99 @@ func NewHistoryHandler(max uint, autosave bool) *HistoryHandler {
 		enabled:  true,
 		autosave: autosave,
 	}
-	h.Clear()
-
-	if h.autosave {
-		h.restore()
-	}
+	h.reset()
 	return h
 }
 
-func (h *HistoryHandler) save() {
-	if out, err := json.Marshal(h); err == nil {
-		historySaveLock.Lock()
-		ioutil.WriteFile("history.json", out, 0644)
-		historySaveLock.Unlock()
+// SetFile loads the history of the user from path, the commands are then appended to it.
+// Nothing is read or written without autosave.
+func (h *HistoryHandler) SetFile(path string) {
+	if !h.autosave {
+		return
+	}
+	entries, err := loadHistoryFile(path, h.max)
+	if err != nil {
+		log.Println("Failed to load the history", path, err)
 	}
+	h.path = path
+	h.reset()
+	h.Queue = append(entries, h.Queue...)
+	h.queuePos = len(h.Queue) - 1
 }
 
-func (h *HistoryHandler) restore() {
-	historySaveLock.Lock()
-	body, err := ioutil.ReadFile("history.json")
-	historySaveLock.Unlock()
-
-	if err == nil {
-		h.Clear()
-		json.Unmarshal(body, h)
-		h.queuePos = len(h.Queue) - 1
-		h.def = ""
+// Clear removes all the commands, also from the file of the history
+func (h *HistoryHandler) Clear() {
+	h.reset()
+	if len(h.path) > 0 {
+		if err := clearHistoryFile(h.path); err != nil {
+			log.Println("Failed to clear the history", h.path, err)
+		}
 	}
 }
 
-func (h *HistoryHandler) Clear() {
+func (h *HistoryHandler) reset() {
 	h.Queue = nil
-	h.Queue = append(h.Queue, "")
+	h.Queue = append(h.Queue, interfaces.HistoryEntry{})
 	h.queuePos = 0
 	h.def = ""
+	h.pending = false
 }
 
 func (h *HistoryHandler) SetEnabled(enabled bool) {
 	h.enabled = enabled
 }
 
+// SetMax sets the number of commands kept, the oldest ones are dropped
+func (h *HistoryHandler) SetMax(max uint) {
+	h.max = max
+	h.trim()
+	h.queuePos = len(h.Queue) - 1
+}
+
+// SetSession sets the session recorded in the new entries
+func (h *HistoryHandler) SetSession(session string) {
+	h.session = session
+}
+
+func (h *HistoryHandler) trim() {
+	if over := len(h.Queue) - 1 - int(h.max); over > 0 {
+		h.Queue = h.Queue[over:]
+	}
+}
+
+// AddToHistory records a command line, unless it starts with a space or repeats the previous one.
+// The entry is completed by Finish when the command ends.
 func (h *HistoryHandler) AddToHistory(data string) {
 	if h.enabled {
-		h.Queue[len(h.Queue)-1] = data
-
-		h.Queue = append(h.Queue, "")
-		if len(h.Queue) > int(h.max) {
-			h.Queue = h.Queue[1:]
+		h.queuePos = len(h.Queue) - 1
+		if strings.HasPrefix(data, " ") {
+			return
+		}
+		if last := len(h.Queue) - 2; last >= 0 && h.Queue[last].Line == data {
+			return
 		}
 
+		h.Queue[len(h.Queue)-1] = interfaces.HistoryEntry{Line: data, Time: time.Now(), Session: h.session}
+		h.Queue = append(h.Queue, interfaces.HistoryEntry{})
+		h.trim()
 		h.queuePos = len(h.Queue) - 1
+		h.pending = true
+	}
+}
 
-		if h.autosave {
-			h.save()
+// Finish records the exit status and the duration of the command added last
+func (h *HistoryHandler) Finish(status int) {
+	if !h.pending {
+		return
+	}
+	h.pending = false
+	if last := len(h.Queue) - 2; last >= 0 {
+		h.Queue[last].Status = status
+		h.Queue[last].Duration = time.Since(h.Queue[last].Time)
+		if len(h.path) > 0 {
+			if err := appendHistoryFile(h.path, h.Queue[last]); err != nil {
+				log.Println("Failed to save the history", h.path, err)
+			}
 		}
 	}
 }
@@ -98,7 +142,7 @@ func (h *HistoryHandler) getHistoryAtIndex(idx int) string {
 	var out string
 	if h.enabled {
 		if idx >= 0 && idx < len(h.Queue)-1 {
-			out = h.Queue[idx]
+			out = h.Queue[idx].Line
 		}
 	}
 	return out
@@ -119,13 +163,11 @@ func (h *HistoryHandler) GetHistoryPrev() (string, bool) {
 	return data, true
 }
 
-func (h *HistoryHandler) GetHistory() []string {
-	var out []string
+func (h *HistoryHandler) GetHistory() []interfaces.HistoryEntry {
+	var out []interfaces.HistoryEntry
 	l := len(h.Queue) - 1
 	if l > 0 {
-		for x := 0; x < l; x++ {
-			out = append(out, h.Queue[x])
-		}
+		out = append(out, h.Queue[:l]...)
 	}
 	return out
 }
@@ -134,8 +176,8 @@ func (h *HistoryHandler) GetHistoryAtPos(pos int) (string, bool) {
 	var out string
 	found := false
 	l := len(h.Queue) - 1
-	if l > 0 && pos < l {
-		out = h.Queue[pos]
+	if l > 0 && pos >= 0 && pos < l {
+		out = h.Queue[pos].Line
 		if len(out) > 0 {
 			found = true
 		}
@@ -166,6 +208,27 @@ func (h *HistoryHandler) GetHistoryNext() (string, bool) {
 	return data, true
 }
 
+// Search returns the index of the newest entry before from accepted by match, -1 if none
+func (h *HistoryHandler) Search(match func(line string) bool, from int) int {
+	if !h.enabled {
+		return -1
+	}
+	if l := len(h.Queue) - 1; from > l {
+		from = l
+	}
+	for idx := from - 1; idx >= 0; idx-- {
+		if match(h.Queue[idx].Line) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Len returns the number of entries
+func (h *HistoryHandler) Len() int {
+	return len(h.Queue) - 1
+}
+
 func (h *HistoryHandler) SetDefault(def string) {
 	h.def = def
 }
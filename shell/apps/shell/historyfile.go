@@ -0,0 +1,200 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package shell
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// The history of a user is a file of JSON lines, one for each command, shared by the sessions
+// of the user. The sessions only append to it, a write of a line is atomic with O_APPEND,
+// and the entries of the sessions are merged by time when the file is read.
+// The file is readable only by its owner as the commands may contain secrets.
+// The sessions of all the processes take the lock of the file before changing it,
+// a compaction never drops the lines appended by another process.
+
+var historyFileLocks = struct {
+	sync.Mutex
+	paths map[string]*sync.Mutex
+}{paths: make(map[string]*sync.Mutex)}
+
+var errHistorySymlink = errors.New("history file is a symbolic link")
+
+// lockHistoryFile takes the lock of path, of the sessions of this process and of the other
+// processes, the returned function releases it
+func lockHistoryFile(path string) (func(), error) {
+	l := historyFileLock(path)
+	l.Lock()
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		l.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlock()
+		l.Unlock()
+	}, nil
+}
+
+// historyFileLock returns the lock of the sessions of this process using path
+func historyFileLock(path string) *sync.Mutex {
+	historyFileLocks.Lock()
+	defer historyFileLocks.Unlock()
+	l, ok := historyFileLocks.paths[path]
+	if !ok {
+		l = &sync.Mutex{}
+		historyFileLocks.paths[path] = l
+	}
+	return l
+}
+
+// openHistoryFile opens path with flags, creating it and its directory only for its owner
+func openHistoryFile(path string, flags int) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil, errHistorySymlink
+		}
+		if info.Mode().Perm()&0077 != 0 {
+			if err = os.Chmod(path, 0600); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return os.OpenFile(path, flags, 0600)
+}
+
+// loadHistoryFile returns the last max entries of path ordered by time. The file is compacted
+// when it holds more than twice max entries.
+func loadHistoryFile(path string, max uint) ([]interfaces.HistoryEntry, error) {
+	if info, err := os.Lstat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	} else if info.Mode()&os.ModeSymlink != 0 {
+		return nil, errHistorySymlink
+	}
+	unlock, err := lockHistoryFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []interfaces.HistoryEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e interfaces.HistoryEntry
+		// a line cut by a crash is skipped
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && len(e.Line) > 0 {
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+
+	compact := len(entries) > 2*int(max)
+	if over := len(entries) - int(max); over > 0 {
+		entries = entries[over:]
+	}
+	if compact {
+		err = writeHistoryFile(path, entries)
+	}
+	return entries, err
+}
+
+// writeHistoryFile replaces the content of path with entries, through a temporary file of the
+// same directory. The caller holds the lock of path.
+func writeHistoryFile(path string, entries []interfaces.HistoryEntry) error {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	// the temporary file is created readable only by its owner
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(buf.Bytes())
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+// appendHistoryFile adds e at the end of path with a single write
+func appendHistoryFile(path string, e interfaces.HistoryEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockHistoryFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f, err := openHistoryFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+// clearHistoryFile removes the entries of path, of all the sessions
+func clearHistoryFile(path string) error {
+	unlock, err := lockHistoryFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f, err := openHistoryFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,50 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/markel1974/goshell/shell/interfaces"
+)
+
+func TestHistoryFile_Compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	start := time.Now()
+	for i := 0; i < 7; i++ {
+		e := interfaces.HistoryEntry{Line: "echo " + strconv.Itoa(i), Time: start.Add(time.Duration(i) * time.Second)}
+		if err := appendHistoryFile(path, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := loadHistoryFile(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].Line != "echo 4" || entries[2].Line != "echo 6" {
+		t.Fatalf("expected the last 3 entries, got %v", entries)
+	}
+
+	// the file has been compacted, the entries appended after it are kept
+	if err = appendHistoryFile(path, interfaces.HistoryEntry{Line: "echo 7", Time: start.Add(7 * time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = loadHistoryFile(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || entries[0].Line != "echo 4" || entries[3].Line != "echo 7" {
+		t.Errorf("expected the compacted entries and the new one, got %v", entries)
+	}
+
+	names, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	if err != nil || len(names) != 0 {
+		t.Errorf("expected no temporary file, got %v", names)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected a file readable only by its owner, got %v", info)
+	}
+}
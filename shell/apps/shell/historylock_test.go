@@ -0,0 +1,42 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package shell
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.lock")
+	unlock, err := lockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a lock taken through another open file waits, as the one of another process
+	locked := make(chan func())
+	go func() {
+		other, err := lockFile(path)
+		if err != nil {
+			t.Error(err)
+			other = func() {}
+		}
+		locked <- other
+	}()
+	select {
+	case <-locked:
+		t.Fatal("expected the lock to be held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case other := <-locked:
+		other()
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the lock to be released")
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package shell
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/markel1974/goshell/shell/interfaces"
+)
+
+// historySearch is the state of the reverse incremental search started with ctrl-R
+type historySearch struct {
+	query  []rune
+	idx    int
+	failed bool
+	line   []rune
+	pos    int
+}
+
+// startSearch starts the reverse incremental search of the history, or moves to the older match
+func (c *Shell) startSearch() {
+	if c.state != stateAuthenticated || c.question != nil {
+		return
+	}
+	if c.search != nil {
+		c.searchHistory(c.search.idx)
+		return
+	}
+	c.search = &historySearch{
+		idx:  -1,
+		line: append([]rune{}, c.current...),
+		pos:  c.pos,
+	}
+	c.drawSearch()
+}
+
+// searchEvent handles a key during the search, it returns false for the keys that end the search
+// and must be handled by the line editor
+func (c *Shell) searchEvent(event *interfaces.KeyData) (bool, bool) {
+	s := c.search
+	switch event.GetType() {
+	case interfaces.KeyTypeKey:
+		if unicode.IsPrint(event.Key) {
+			s.query = append(s.query, event.Key)
+			from := c.history.Len()
+			if s.idx >= 0 {
+				from = s.idx + 1
+			}
+			c.searchHistory(from)
+		}
+		return true, false
+	case interfaces.KeyTypeBackspace:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			s.idx = -1
+			s.failed = false
+			if len(s.query) > 0 {
+				c.searchHistory(c.history.Len())
+			} else {
+				c.drawSearch()
+			}
+		}
+		return true, false
+	case interfaces.KeyTypeCtrl:
+		switch event.Key {
+		case 7: // ctrl-G
+			c.endSearch(false)
+			return true, false
+		case 18:
+			c.startSearch()
+			return true, false
+		}
+	case interfaces.KeyTypeEnter:
+		c.endSearch(true)
+		return true, c.enterPressed()
+	case interfaces.KeyTypeEscape:
+		c.endSearch(true)
+		return true, false
+	}
+	c.endSearch(true)
+	return false, false
+}
+
+// searchHistory looks for the query in the entries older than from
+func (c *Shell) searchHistory(from int) {
+	s := c.search
+	query := string(s.query)
+	if len(query) > 0 {
+		idx := c.history.Search(func(line string) bool {
+			return strings.Contains(line, query)
+		}, from)
+		s.failed = idx < 0
+		if idx >= 0 {
+			s.idx = idx
+		}
+	}
+	c.drawSearch()
+}
+
+// searchLine returns the line matched by the search and the position of the query in it
+func (c *Shell) searchLine() ([]rune, int) {
+	s := c.search
+	if s.idx < 0 {
+		return s.line, s.pos
+	}
+	line, _ := c.history.GetHistoryAtPos(s.idx)
+	pos := strings.Index(line, string(s.query))
+	if pos < 0 {
+		pos = 0
+	}
+	return []rune(line), len([]rune(line[:pos]))
+}
+
+func (c *Shell) drawSearch() {
+	c.rewrite(c.searchLine())
+}
+
+// writeSearchPrompt writes the query of the search in place of the prompt
+func (c *Shell) writeSearchPrompt() {
+	s := c.search
+	label := "(reverse-i-search)`"
+	if s.failed {
+		label = "(failed reverse-i-search)`"
+	}
+	label += string(s.query) + "': "
+	_, _ = c.terminal.WriteColor(label, interfaces.ColorGreenDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+	c.promptWidth = len([]rune(label))
+}
+
+// endSearch leaves the search with the matched line in the line editor, or with the line
+// typed before the search when accept is false
+func (c *Shell) endSearch(accept bool) {
+	line, pos := c.search.line, c.search.pos
+	if accept {
+		line, pos = c.searchLine()
+	}
+	c.search = nil
+	c.rewrite(line, pos)
+	c.history.SetDefault(string(line))
+}
@@ -0,0 +1,98 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHistoryHandler_Search(t *testing.T) {
+	c := newHistoryShell("ls -l", "echo one", "ps", "echo two")
+	echo := func(line string) bool { return strings.HasPrefix(line, "echo") }
+	tests := []struct {
+		name     string
+		from     int
+		expected int
+	}{
+		{"newest", c.history.Len(), 3},
+		{"older", 3, 1},
+		{"none older", 1, -1},
+		{"from past the end", 100, 3},
+		{"from the start", 0, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if idx := c.history.Search(echo, tt.from); idx != tt.expected {
+				t.Errorf("got %d, want %d", idx, tt.expected)
+			}
+		})
+	}
+}
+
+// newSearchShell returns a shell editing "typed" with a history, ready for ctrl-R
+func newSearchShell() (*Shell, func(keys string)) {
+	c, terminal := newEditorShell("")
+	c.state = stateAuthenticated
+	for _, line := range []string{"ls -l", "echo one", "ps", "echo two", "cd /tmp"} {
+		c.history.AddToHistory(line)
+		c.history.Finish(0)
+	}
+	terminal.Scan([]byte("typed"))
+	return c, func(keys string) { terminal.Scan([]byte(keys)) }
+}
+
+func TestShell_ReverseSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      string
+		line      string
+		pos       int
+		searching bool
+		failed    bool
+	}{
+		{"started", "\x12", "typed", 5, true, false},
+		{"newest match", "\x12echo", "echo two", 0, true, false},
+		{"match position", "\x12two", "echo two", 5, true, false},
+		{"the match is kept while typing", "\x12e\x12c", "echo one", 0, true, false},
+		{"older match", "\x12echo\x12", "echo one", 0, true, false},
+		{"no older match", "\x12echo\x12\x12", "echo one", 0, true, true},
+		{"no match", "\x12zz", "typed", 5, true, true},
+		{"case sensitive", "\x12ECHO", "typed", 5, true, true},
+		{"backspace searches again", "\x12echo\x12x\x7f", "echo two", 0, true, false},
+		{"backspace to an empty query", "\x12one\x7f\x7f\x7f", "typed", 5, true, false},
+		{"escape keeps the match", "\x12one\x1b", "echo one", 5, false, false},
+		{"ctrl-g restores the line", "\x12one\x07", "typed", 5, false, false},
+		{"another key edits the match", "\x12one\x01", "echo one", 0, false, false},
+		{"arrows edit the match", "\x12one\x1b[D", "echo one", 4, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, keys := newSearchShell()
+			keys(tt.keys)
+			if string(c.current) != tt.line || c.pos != tt.pos {
+				t.Errorf("got %q at %d, want %q at %d", string(c.current), c.pos, tt.line, tt.pos)
+			}
+			if searching := c.search != nil; searching != tt.searching {
+				t.Fatalf("got searching %v, want %v", searching, tt.searching)
+			}
+			if c.search != nil && c.search.failed != tt.failed {
+				t.Errorf("got failed %v, want %v", c.search.failed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestShell_ReverseSearchEnter(t *testing.T) {
+	c, keys := newSearchShell()
+	var executed []string
+	c.ExecCommand = func(command string) bool {
+		executed = append(executed, command)
+		return false
+	}
+	keys("\x12one\r")
+	if c.search != nil || len(executed) != 1 || executed[0] != "echo one" {
+		t.Errorf("expected enter to run the match, got %q", executed)
+	}
+	if line, _ := c.history.GetHistoryAtPos(c.history.Len() - 1); line != "echo one" {
+		t.Errorf("expected the match added to the history, got %q", line)
+	}
+}
@@ -15,9 +15,12 @@
 package shell
 
 import (
+	"errors"
 	"fmt"
+	"github.com/markel1974/goshell/shell/cli"
 	"github.com/markel1974/goshell/shell/interfaces"
 	"log"
+	"strings"
 	"unicode"
 )
 
@@ -32,36 +35,68 @@ const (
 	usernamePrompt   = "Username: "
 	passwordPrompt   = "Password: "
 	maxPasswordRetry = 3
+	promptSuffix     = "<>#$%: "
+	// continuationPrompt is shown for the lines continuing a command
+	continuationPrompt = "> "
 )
 
-type ExecSuggestionType func(in string, count int) bool
+// ExecSuggestionType returns the word being completed at the end of in and its candidates
+type ExecSuggestionType func(in string) (string, []cli.Completion)
 
 type ExecCommandType func(command string) bool
 
+// ExecHighlightType returns the spans showing the parts of a command line
+type ExecHighlightType func(line string) []cli.Span
+
+type ExecLoginType func(username string)
+
+// ExecNotifyType writes the notifications due before the prompt, like the jobs ended in the background
+type ExecNotifyType func()
+
 type Shell struct {
 	current  []rune
 	pos      int
 	echo     bool
 	history  *HistoryHandler
-	tabData  string
-	tabFound bool
 	tabCount int
+	menu     *completionMenu
+	width    int
+	height   int
 	terminal interfaces.ITerminal
 
 	defaultPrompt   string
 	prompt          string
+	path            string
 	currentUsername string
 	passwordRetry   int
 	state           int
+	status          int
+	suspended       bool
+	question        *question
+	search          *historySearch
+	promptWidth     int
+	continued       string
+	killRing        []string
+	killing         bool
+	killed          bool
+	yanking         bool
+	yanked          bool
+	yankStart       int
+	yankIndex       int
 	auth            interfaces.IAuthenticator
 	ExecSuggestion  ExecSuggestionType
+	ExecHighlight   ExecHighlightType
 	ExecCommand     ExecCommandType
+	ExecLogin       ExecLoginType
+	ExecNotify      ExecNotifyType
 }
 
 func NewShell(auth interfaces.IAuthenticator, terminal interfaces.ITerminal, prompt string, autosave bool) *Shell {
 	c := &Shell{
-		history:       NewHistoryHandler(128, autosave),
+		history:       NewHistoryHandler(DefaultHistorySize, autosave),
 		echo:          true,
+		width:         80,
+		height:        24,
 		terminal:      terminal,
 		auth:          auth,
 		defaultPrompt: prompt,
@@ -69,12 +104,31 @@ func NewShell(auth interfaces.IAuthenticator, terminal interfaces.ITerminal, pro
 		state:         stateUndefined,
 	}
 	if auth.IsAuthenticated() {
-		c.state = stateAuthenticated
+		c.setAuthenticatedState()
 	}
 	return c
 }
 
 func (c *Shell) KeyEvent(event *interfaces.KeyData) bool {
+	// a kill right after another kill is joined to it in the kill ring
+	c.killing, c.killed = c.killed, false
+	// alt-Y only replaces the text of the yank right before it
+	c.yanking, c.yanked = c.yanked, false
+	if c.question != nil {
+		c.questionEvent(event)
+		return false
+	}
+	if c.menu != nil && c.menuEvent(event) {
+		return false
+	}
+	if c.search != nil {
+		if handled, quit := c.searchEvent(event); handled {
+			return quit
+		}
+	}
+	if event.GetType() != interfaces.KeyTypeTab {
+		c.tabCount = 0
+	}
 	ret := false
 	switch event.GetType() {
 	case interfaces.KeyTypeEnter:
@@ -89,10 +143,24 @@ func (c *Shell) KeyEvent(event *interfaces.KeyData) bool {
 		c.keyPressed(event.Key)
 	case interfaces.KeyTypeCursor:
 		c.cursorPressed(interfaces.CursorCodeDef(event.Key))
+	case interfaces.KeyTypeCtrl:
+		c.ctrlPressed(event.Key)
+	case interfaces.KeyTypeAlt:
+		c.altPressed(event.Key)
 	}
 	return ret
 }
 
+// SetScreenSize sets the size of the terminal, used to lay out the completion candidates
+func (c *Shell) SetScreenSize(width int, height int) {
+	if width > 0 {
+		c.width = width
+	}
+	if height > 0 {
+		c.height = height
+	}
+}
+
 func (c *Shell) ClearHistory() {
 	c.history.Clear()
 }
@@ -101,31 +169,98 @@ func (c *Shell) GetHistoryAtPos(idx int) (string, bool) {
 	return c.history.GetHistoryAtPos(idx)
 }
 
-func (c *Shell) GetHistory() string {
-	out := ""
-	for n, x := range c.history.GetHistory() {
-		out += "\r\n"
-		out += fmt.Sprintf("%d: %s", n, x)
-	}
-	return out
+func (c *Shell) GetHistory() []interfaces.HistoryEntry {
+	return c.history.GetHistory()
+}
+
+// SetHistorySize sets the number of commands kept in the history
+func (c *Shell) SetHistorySize(size uint) {
+	c.history.SetMax(size)
+}
+
+// SetHistoryFile loads the history shared by the sessions of the user from path, when autosave is on
+func (c *Shell) SetHistoryFile(path string) {
+	c.history.SetFile(path)
+}
+
+// SetSession sets the session recorded with the commands of the history
+func (c *Shell) SetSession(session string) {
+	c.history.SetSession(session)
 }
 
 func (c *Shell) SetHistoryDefault(data string) {
 	c.history.SetDefault(data)
 }
 
+// SetStatus sets the exit status of the last command, a failure is shown in the prompt.
+func (c *Shell) SetStatus(status int) {
+	c.status = status
+}
+
+// SetPath sets the position in the command tree shown in the prompt, the root is not shown
+func (c *Shell) SetPath(path string) {
+	c.path = path
+}
+
+// Suspend holds the prompt while a foreground task runs, until Resume
+func (c *Shell) Suspend() {
+	c.suspended = true
+}
+
+func (c *Shell) IsSuspended() bool {
+	return c.suspended
+}
+
+// Resume shows the prompt held by Suspend
+func (c *Shell) Resume() {
+	if !c.suspended {
+		return
+	}
+	c.suspended = false
+	c.DoNext()
+}
+
 func (c *Shell) DoNext() {
+	if c.suspended {
+		return
+	}
+	c.closeMenu(false)
+	c.moveTo(len(c.current))
+	c.search = nil
+	c.continued = ""
 	c.resetBuffer()
+	if c.state == stateAuthenticated {
+		c.history.Finish(c.status)
+	}
 	_, _ = c.terminal.WriteColor("\r\n", interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
-	_, _ = c.terminal.WriteColor(c.prompt, interfaces.ColorGreenDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+	if c.state == stateAuthenticated && c.ExecNotify != nil {
+		c.ExecNotify()
+	}
+	c.writePrompt()
 }
 
+// DoRedraw replaces the line being edited with line
 func (c *Shell) DoRedraw(line string) {
-	c.current = []rune(line)
-	c.pos = len(c.current)
-	_, _ = c.terminal.ClearLine(line)
+	c.rewrite([]rune(line), len([]rune(line)))
+}
+
+func (c *Shell) writePrompt() {
+	status := ""
+	if c.state == stateAuthenticated && c.status != 0 {
+		status = fmt.Sprintf("[%d] ", c.status)
+		_, _ = c.terminal.WriteColor(status, interfaces.ColorRedDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+	}
+	if c.state == stateAuthenticated && len(c.path) > 0 && c.path != "/" {
+		// the path goes before the closing characters of the prompt, like "admin:/stats> "
+		name := strings.TrimRight(c.prompt, promptSuffix)
+		_, _ = c.terminal.WriteColor(name+":", interfaces.ColorGreenDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+		_, _ = c.terminal.WriteColor(c.path, interfaces.ColorBlueDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+		_, _ = c.terminal.WriteColor(c.prompt[len(name):], interfaces.ColorGreenDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+		c.promptWidth = len([]rune(status + name + ":" + c.path + c.prompt[len(name):]))
+		return
+	}
 	_, _ = c.terminal.WriteColor(c.prompt, interfaces.ColorGreenDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
-	_, _ = c.terminal.WriteColor(line, interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+	c.promptWidth = len([]rune(status + c.prompt))
 }
 
 func (c *Shell) cursorPressed(code interfaces.CursorCodeDef) {
@@ -141,22 +276,25 @@ func (c *Shell) cursorPressed(code interfaces.CursorCodeDef) {
 
 	case interfaces.CursorLeftDef:
 		if c.pos > 0 {
-			c.pos--
-			_, _ = c.terminal.MoveCursorLeft()
+			c.moveTo(c.pos - 1)
 		}
 	case interfaces.CursorRightDef:
 		if c.pos >= 0 && c.pos < len(c.current) {
-			c.pos++
-			_, _ = c.terminal.MoveCursorRight()
+			c.moveTo(c.pos + 1)
 		}
+	case interfaces.CursorHomeDef:
+		c.moveTo(0)
+	case interfaces.CursorEndDef:
+		c.moveTo(len(c.current))
 	}
 }
 
 func (c *Shell) enterPressed() bool {
 	buffer := string(c.current)
 	quit := false
+	c.moveTo(len(c.current))
 
-	if len(buffer) > 0 {
+	if len(buffer) > 0 || len(c.continued) > 0 {
 		switch c.state {
 		case stateUsernameRequired:
 			c.passwordRetry = 0
@@ -166,6 +304,9 @@ func (c *Shell) enterPressed() bool {
 		case statePasswordRequired:
 			if c.auth.Authenticate(c.currentUsername, buffer) {
 				c.setAuthenticatedState()
+				if c.ExecLogin != nil {
+					c.ExecLogin(c.currentUsername)
+				}
 			} else {
 				c.passwordRetry++
 				if c.passwordRetry >= maxPasswordRetry {
@@ -177,11 +318,26 @@ func (c *Shell) enterPressed() bool {
 			}
 
 		case stateAuthenticated:
-			c.history.AddToHistory(buffer)
+			text := c.continued + buffer
+			if err := cli.CheckLine(text); cli.IsIncomplete(err) {
+				c.continueLine(text, err)
+				return false
+			}
+			c.continued = ""
+			line, expanded, err := c.expandHistory(text)
+			if err != nil {
+				_, _ = c.terminal.WriteColor("\r\n"+err.Error(), interfaces.ColorRedDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+				break
+			}
+			if expanded {
+				// the expanded line is shown before it runs
+				_, _ = c.terminal.WriteColor("\r\n"+line, interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
+			}
+			c.history.AddToHistory(line)
 			c.history.SetDefault("")
 
 			if c.ExecCommand != nil {
-				c.ExecCommand(buffer)
+				c.ExecCommand(line)
 			}
 			//c.quit = c.execCommand(buffer)
 
@@ -197,55 +353,39 @@ func (c *Shell) enterPressed() bool {
 
 func (c *Shell) tabPressed() {
 	if c.state == stateAuthenticated {
-		if c.tabCount == 0 {
-			c.tabFound = false
-			c.tabData = ""
-
-			if c.pos == len(c.current) {
-				c.tabData = string(c.current[:c.pos])
-				c.tabFound = true
-			}
-		}
-		if c.tabFound {
-
-			if c.ExecSuggestion != nil {
-				c.ExecSuggestion(c.tabData, c.tabCount)
-			}
-			//c.terminalExecSuggestion(c.tabData, c.tabCount)
-		}
-		c.tabCount++
+		c.complete()
 	}
 }
 
 func (c *Shell) keyPressed(key rune) {
 	if unicode.IsPrint(key) {
-		if c.pos < 0 {
-			log.Println("doTextInsert: negative pos", c.pos)
+		if c.pos < 0 || c.pos > len(c.current) {
+			log.Println("terminalKeyPressed: invalid pos", c.pos)
 		} else if c.pos == len(c.current) {
-			if c.echo {
-				_, _ = c.terminal.WriteColor(string(key), interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
-			}
-			c.current = append(c.current, key)
-			c.pos++
-		} else if c.pos < len(c.current) {
-			if c.echo {
-				_, _ = c.terminal.WriteColor(string(key), interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
-				_, _ = c.terminal.SaveCursor()
-				_, _ = c.terminal.WriteColor(string(c.current[c.pos:]), interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
-			}
-			_, _ = c.terminal.RestoreCursor()
-
-			c.current = insertAtPos(c.current, key, c.pos)
-			c.pos++
+			c.edit(c.pos, append(c.current, key), c.pos+1)
 		} else {
-			log.Println("terminalKeyPressed: invalid pos", c.pos)
+			c.edit(c.pos, insertAtPos(c.current, key, c.pos), c.pos+1)
 		}
 	}
+}
 
-	if c.state == stateAuthenticated {
-		c.history.SetDefault(string(c.current))
-		c.tabCount = 0
+// continueLine shows the continuation prompt for the rest of a command, text is the command so far
+func (c *Shell) continueLine(text string, err error) {
+	var incomplete *cli.IncompleteError
+	errors.As(err, &incomplete)
+	switch {
+	case incomplete.Escaped:
+		// the backslash joins the lines
+		text = text[:len(text)-1]
+	case incomplete.Quoted:
+		text += "\n"
+	default:
+		text += " "
 	}
+	c.continued = text
+	c.resetBuffer()
+	_, _ = c.terminal.Write("\r\n")
+	c.writeLinePrompt()
 }
 
 func (c *Shell) setUsernameRequiredState() {
@@ -271,29 +411,13 @@ func (c *Shell) setAuthenticatedState() {
 
 func (c *Shell) textBackspace() {
 	if c.pos > 0 {
-		c.pos--
-		c.current = removeAtPos(c.current, c.pos)
-
-		if c.echo {
-			_, _ = c.terminal.MoveCursorLeft()
-			_, _ = c.terminal.SaveCursor()
-			_, _ = c.terminal.WriteColor(string(c.current[c.pos:]), interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
-			_, _ = c.terminal.WriteColor(string(' '), interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
-			_, _ = c.terminal.RestoreCursor()
-		}
+		c.edit(c.pos-1, removeAtPos(c.current, c.pos-1), c.pos-1)
 	}
 }
 
 func (c *Shell) textCancel() {
-	if c.pos >= 0 {
-		c.current = removeAtPos(c.current, c.pos)
-
-		if c.echo {
-			_, _ = c.terminal.SaveCursor()
-			_, _ = c.terminal.WriteColor(string(c.current[c.pos:]), interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
-			_, _ = c.terminal.WriteColor(string(' '), interfaces.ColorNoneDef, interfaces.ColorNoneDef, interfaces.ModeNormal)
-			_, _ = c.terminal.RestoreCursor()
-		}
+	if c.pos >= 0 && c.pos < len(c.current) {
+		c.edit(c.pos, removeAtPos(c.current, c.pos), c.pos)
 	}
 }
 
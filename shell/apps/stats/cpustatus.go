@@ -15,7 +15,6 @@
 package stats
 
 import (
-	"fmt"
 	"github.com/markel1974/goshell/shell/apps/commandcreator"
 	"github.com/markel1974/goshell/shell/cli"
 	"runtime"
@@ -25,14 +24,16 @@ func CreateCPUStatus(t commandcreator.ICreator) *cli.Command {
 	root := t.CreateCommand()
 	root.Use = "cpu"
 	root.Short = "CPUs status"
-	root.Long = "CPUs status"
+	root.Long = "Number of logical CPUs, maximum number of CPUs executing simultaneously, " +
+		"number of goroutines and number of cgo calls made by the process"
+	root.InitOutputFlag()
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
-		r := cmd.GetRootContext()
-		r.WriteLn("")
-		r.WriteLn(fmt.Sprintf("Number of logical CPUs: %d", runtime.NumCPU()))
-		r.WriteLn(fmt.Sprintf("Maximum number of CPUs that can be executing simultaneously: %d", runtime.GOMAXPROCS(0)))
-		r.WriteLn(fmt.Sprintf("Number of goroutines that currently exist: %d", runtime.NumGoroutine()))
-		r.WriteLn(fmt.Sprintf("Number of cgo calls made by the current process: %d", runtime.NumCgoCall()))
+		res := cli.NewObject()
+		res.Set("cpus", runtime.NumCPU())
+		res.Set("max_procs", runtime.GOMAXPROCS(0))
+		res.Set("goroutines", runtime.NumGoroutine())
+		res.Set("cgo_calls", runtime.NumCgoCall())
+		cmd.PrintResult(res)
 	}
 	return root
 }
@@ -15,7 +15,7 @@
 package stats
 
 import (
-	"fmt"
+	"context"
 	"github.com/markel1974/goshell/shell/apps/commandcreator"
 	"github.com/markel1974/goshell/shell/cli"
 	"time"
@@ -25,18 +25,20 @@ func CreateCPUUsage(t commandcreator.ICreator) *cli.Command {
 	root := t.CreateCommand()
 	root.Use = "usage"
 	root.Short = "CPU usage"
-	root.Long = "CPU usage"
-	root.Run = func(cmd *cli.Command, pid int, args []string) {
-		r := cmd.GetRootContext()
-		r.WriteLn("")
-		r.WriteLn("Computing cpu usage")
+	root.Long = "CPU usage sampled over three seconds, ctrl-c interrupts the sampling"
+	root.RunContext = func(ctx context.Context, cmd *cli.Command, pid int, args []string) {
+		cmd.Print(cli.DefaultEol + "Computing cpu usage" + cli.DefaultEol)
 		idle0, total0 := getCPUSample()
-		time.Sleep(3 * time.Second)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(3 * time.Second):
+		}
 		idle1, total1 := getCPUSample()
 		idleTicks := float64(idle1 - idle0)
 		totalTicks := float64(total1 - total0)
 		cpuUsage := 100 * (totalTicks - idleTicks) / totalTicks
-		r.WriteLn(fmt.Sprintf("CPU Usage: %f", cpuUsage))
+		cmd.Printf("CPU Usage: %f"+cli.DefaultEol, cpuUsage)
 	}
 
 	return root
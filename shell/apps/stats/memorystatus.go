@@ -15,27 +15,32 @@
 package stats
 
 import (
-	"fmt"
 	"github.com/markel1974/goshell/shell/apps/commandcreator"
 	"github.com/markel1974/goshell/shell/cli"
+	"math"
 	"runtime"
 )
 
 func CreateMemoryStatus(t commandcreator.ICreator) *cli.Command {
 	root := t.CreateCommand()
 	root.Use = "rt"
+	root.Aliases = []string{"memory"}
 	root.Short = "Runtime Status"
-	root.Long = "Runtime Status"
+	root.Long = "Memory allocated in heap objects, total memory allocated for heap objects, " +
+		"total memory obtained from the OS, in MB, and number of completed GC cycles"
+	root.InitOutputFlag()
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
-		r := cmd.GetRootContext()
+		// in MB, rounded to three decimals
+		mb := func(b uint64) float64 { return math.Round(bToMb(b)*1000) / 1000 }
 		var m runtime.MemStats
 
 		runtime.ReadMemStats(&m)
-		r.WriteLn("")
-		r.WriteLn(fmt.Sprintf("Allocated memory in heap objects: %.3f MB", bToMb(m.Alloc)))
-		r.WriteLn(fmt.Sprintf("Total memory allocated for heap objects: %.3f MB", bToMb(m.TotalAlloc)))
-		r.WriteLn(fmt.Sprintf("Total memory obtained from the OS: %.3f MB", bToMb(m.Sys)))
-		r.WriteLn(fmt.Sprintf("Number of completed GC cycles: %d", m.NumGC))
+		res := cli.NewObject()
+		res.Set("alloc_mb", mb(m.Alloc))
+		res.Set("total_alloc_mb", mb(m.TotalAlloc))
+		res.Set("sys_mb", mb(m.Sys))
+		res.Set("num_gc", m.NumGC)
+		cmd.PrintResult(res)
 	}
 	return root
 }
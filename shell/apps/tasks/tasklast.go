@@ -0,0 +1,40 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tasks
+
+import (
+	"github.com/markel1974/goshell/shell/adaptiveticker"
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"strconv"
+)
+
+func CreateTasksLast(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "last"
+	root.Short = "Last"
+	root.Long = "Print the pid of the last task started, e.g. fg $(task last)"
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		last := r.LastPid()
+		if last == adaptiveticker.UnknownId {
+			cmd.SetExitCode(cli.ExitFailure)
+			return
+		}
+		r.WriteLn("")
+		r.WriteLn(strconv.Itoa(last))
+	}
+	return root
+}
@@ -24,12 +24,13 @@ func CreateTasksList(t commandcreator.ICreator) *cli.Command {
 	root.Use = "list"
 	root.Short = "List"
 	root.Long = "List"
+	root.InitOutputFlag()
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
-		r := cmd.GetRootContext()
-		r.WriteLn("")
-		for _, task := range r.ListTasks() {
-			r.WriteLn(task)
+		res := cli.NewTable("name")
+		for _, task := range cmd.GetRootContext().ListTasks() {
+			res.AddRow(task)
 		}
+		cmd.PrintResult(res)
 	}
 	return root
 }
@@ -24,12 +24,25 @@ func CreateTasksRestore(t commandcreator.ICreator) *cli.Command {
 	root.Use = "restore"
 	root.Short = "Restore"
 	root.Long = "Restore"
+	root.ValidArgsFunction = func(cmd *cli.Command, args []string, _ string) []cli.Completion {
+		if len(args) > 0 {
+			return nil
+		}
+		var out []cli.Completion
+		for _, name := range cmd.GetRootContext().ListTasks() {
+			out = append(out, cli.Completion{Value: name, Description: "saved layout"})
+		}
+		return out
+	}
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
 		r := cmd.GetRootContext()
 		if len(args) <= 0 {
+			cmd.SetExitCode(cli.ExitUsage)
 			return
 		}
-		r.RestoreTasks(args[0])
+		if !r.RestoreTasks(args[0]) {
+			cmd.SetExitCode(cli.ExitFailure)
+		}
 	}
 	return root
 }
@@ -29,6 +29,7 @@ func Create(t commandcreator.ICreator) *cli.Command {
 	t.AddCommand(root, CreateTasksSave(t))
 	t.AddCommand(root, CreateTasksRestore(t))
 	t.AddCommand(root, CreateTasksList(t))
+	t.AddCommand(root, CreateTasksLast(t))
 
 	return root
 }
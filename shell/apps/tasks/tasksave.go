@@ -27,9 +27,12 @@ func CreateTasksSave(t commandcreator.ICreator) *cli.Command {
 	root.Run = func(cmd *cli.Command, pid int, args []string) {
 		r := cmd.GetRootContext()
 		if len(args) <= 0 {
+			cmd.SetExitCode(cli.ExitUsage)
 			return
 		}
-		r.SaveTasks(args[0])
+		if !r.SaveTasks(args[0]) {
+			cmd.SetExitCode(cli.ExitFailure)
+		}
 	}
 	return root
 }
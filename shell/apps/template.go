@@ -15,8 +15,13 @@
 package apps
 
 import (
+	"bytes"
+	"github.com/markel1974/goshell/shell/apps/buffer"
+	"github.com/markel1974/goshell/shell/apps/filters"
 	"github.com/markel1974/goshell/shell/apps/games"
+	"github.com/markel1974/goshell/shell/apps/help"
 	"github.com/markel1974/goshell/shell/apps/history"
+	"github.com/markel1974/goshell/shell/apps/pager"
 	"github.com/markel1974/goshell/shell/apps/runtime"
 	"github.com/markel1974/goshell/shell/apps/stats"
 	"github.com/markel1974/goshell/shell/apps/tasks"
@@ -26,22 +31,27 @@ import (
 )
 
 type template struct {
-	ctx    interfaces.IContext
-	writer io.Writer
+	ctx       interfaces.IContext
+	writer    io.Writer
+	errWriter io.Writer
 }
 
-func NewTemplate(ctx interfaces.IContext, writer io.Writer) *template {
+func NewTemplate(ctx interfaces.IContext, writer io.Writer, errWriter io.Writer) *template {
 	return &template{
-		ctx:    ctx,
-		writer: writer,
+		ctx:       ctx,
+		writer:    writer,
+		errWriter: errWriter,
 	}
 }
 
-func (t *template) Run(template *cli.Command) *cli.Command {
+// Run creates the commands of a session, the commands of the host are built by template and
+// added to the internal ones. template is called for every session, the host commands are not shared.
+func (t *template) Run(template func() *cli.Command) *cli.Command {
 	root := t.createInternalCommands()
 	if template != nil {
-		//template childs only
-		t.commandIterator(root, template)
+		if src := template(); src != nil {
+			t.adoptCommands(root, src)
+		}
 	}
 
 	return root
@@ -76,78 +86,65 @@ func (t *template) createInternalCommands() *cli.Command {
 	t.AddCommand(root, CreatePs(t))
 	t.AddCommand(root, CreateClear(t))
 	t.AddCommand(root, CreateFg(t))
+	t.AddCommand(root, CreateBg(t))
+	t.AddCommand(root, CreateJobs(t))
+	t.AddCommand(root, CreateWait(t))
+	t.AddCommand(root, CreateChangeDirectory(t))
+	t.AddCommand(root, CreatePwd(t))
+	t.AddCommand(root, CreateEcho(t))
+	t.AddCommand(root, CreateTrue(t))
+	t.AddCommand(root, CreateFalse(t))
+	t.AddCommand(root, CreateSet(t))
+	t.AddCommand(root, CreateUnset(t))
+	t.AddCommand(root, CreateExport(t))
+	t.AddCommand(root, CreateEnv(t))
+	t.AddCommand(root, CreateAlias(t))
+	t.AddCommand(root, CreateUnalias(t))
+	t.AddCommand(root, CreateFunction(t))
+	t.AddCommand(root, CreateSource(t))
+	t.AddCommand(root, CreateRun(t))
+	t.AddCommand(root, buffer.Create(t))
 	t.AddCommand(root, games.Create(t))
 
+	helpCmd := help.Create(t)
+	t.AddCommand(root, helpCmd)
+	root.SetHelpCommand(helpCmd)
+	t.AddCommand(root, help.CreateMan(t))
+	t.AddCommand(root, help.CreateApropos(t))
+	t.AddCommand(root, help.CreateDocs(t))
+
+	t.AddCommand(root, filters.CreateGrep(t))
+	t.AddCommand(root, filters.CreateHead(t))
+	t.AddCommand(root, filters.CreateTail(t))
+	t.AddCommand(root, filters.CreateSort(t))
+	t.AddCommand(root, filters.CreateUniq(t))
+	t.AddCommand(root, filters.CreateWc(t))
+	t.AddCommand(root, filters.CreateCut(t))
+	t.AddCommand(root, pager.CreateMore(t))
+
 	root.SetOut(t.writer)
-	root.SetErr(t.writer)
+	root.SetErr(t.errWriter)
+	// commands without a pipeline input read nothing, never the process stdin
+	root.SetIn(bytes.NewReader(nil))
 	return root
 }
 
-func (t *template) cloneCommand(src *cli.Command) *cli.Command {
-	dst := cli.NewCommand()
-
-	dst.Use = src.Use
-	dst.Aliases = src.Aliases
-	dst.SuggestFor = src.SuggestFor
-	dst.Short = src.Short
-	dst.Long = src.Long
-	dst.Example = src.Example
-	dst.ValidArgs = src.ValidArgs
-	dst.Args = src.Args
-	dst.ArgAliases = src.ArgAliases
-	dst.Deprecated = src.Deprecated
-	dst.Hidden = src.Hidden
-	dst.Annotations = src.Annotations
-	dst.Version = src.Version
-	dst.SilenceErrors = src.SilenceErrors
-	dst.SilenceUsage = src.SilenceUsage
-	dst.DisableFlagParsing = src.DisableFlagParsing
-	dst.DisableAutoGenTag = src.DisableAutoGenTag
-	dst.DisableFlagsInUseLine = src.DisableFlagsInUseLine
-	dst.DisableSuggestions = src.DisableSuggestions
-	dst.SuggestionsMinimumDistance = src.SuggestionsMinimumDistance
-	dst.TraverseChildren = src.TraverseChildren
-	dst.FParseErrWhitelist = src.FParseErrWhitelist
-	dst.PersistentPreRun = src.PersistentPreRun
-	dst.PersistentPreRunE = src.PersistentPreRunE
-	dst.PreRun = src.PreRun
-	dst.PreRunE = src.PreRunE
-	dst.Activate = src.Activate
-	dst.Background = src.Background
-	dst.Run = src.Run
-	dst.ReadEvent = src.ReadEvent
-	dst.TimerEvent = src.TimerEvent
-	dst.PaintEvent = src.PaintEvent
-	dst.RunE = src.RunE
-	dst.PostRun = src.PostRun
-	dst.PostRunE = src.PostRunE
-	dst.PersistentPostRun = src.PersistentPostRun
-	dst.PersistentPostRunE = src.PersistentPostRunE
-	dst.SetUsageFunc(src.GetUsageFunc())
-	dst.SetUsageTemplate(src.GetUsageTemplate())
-	dst.SetFlagErrorFunc(src.GetFlagErrorFunc())
-	dst.SetHelpFunc(src.GetHelpFunc())
-	dst.SetHelpCommand(dst.GetHelpCommand())
-	dst.SetHelpTemplate(src.GetHelpTemplate())
-	dst.SetVersionTemplate(src.GetVersionTemplate())
-	if src.GetGlobalNormalizationFunc() != nil {
-		dst.SetGlobalNormalizationFunc(src.GetGlobalNormalizationFunc())
+// adoptCommands moves the childs of src under dst, src has been built for the session only
+func (t *template) adoptCommands(dst *cli.Command, src *cli.Command) {
+	for _, child := range src.Childs() {
+		if child != nil {
+			t.AddCommand(dst, child)
+			t.setupTree(child)
+		}
 	}
-
-	t.setupCommand(dst)
-
-	return dst
 }
 
-func (t *template) commandIterator(dst *cli.Command, src *cli.Command) {
-	if src.HasSubCommands() {
-		for _, srcChild := range src.Childs() {
-			if srcChild != nil {
-				dstChild := t.cloneCommand(srcChild)
-				t.AddCommand(dst, dstChild)
-				//dst.AddCommand(dstChild)
-				t.commandIterator(dstChild, srcChild)
-			}
+func (t *template) setupTree(cmd *cli.Command) {
+	t.setupCommand(cmd)
+	for _, child := range cmd.Childs() {
+		if child != nil {
+			cmd.CommonCommands = true
+			t.setupTree(child)
 		}
 	}
 }
@@ -155,5 +152,5 @@ func (t *template) commandIterator(dst *cli.Command, src *cli.Command) {
 func (t *template) setupCommand(cmd *cli.Command) {
 	cmd.SetRootContext(t.ctx)
 	cmd.SetOut(t.writer)
-	cmd.SetErr(t.writer)
+	cmd.SetErr(t.errWriter)
 }
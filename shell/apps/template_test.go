@@ -0,0 +1,66 @@
+package apps
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/markel1974/goshell/shell/cli"
+)
+
+// hostTemplate builds a host command printing its flags, like the commands a host application adds
+func hostTemplate() *cli.Command {
+	root := cli.NewCommand()
+	foo := &cli.Command{Use: "foo"}
+	text := foo.Flags().StringP("text", "t", "Foo", "text")
+	repeat := foo.Flags().IntP("repeat", "r", 1, "repeat")
+	foo.Run = func(cmd *cli.Command, pid int, args []string) {
+		for i := 0; i < *repeat; i++ {
+			cmd.Print(*text)
+		}
+	}
+	_ = root.AddCommand(foo)
+	return root
+}
+
+// prepare parses line in the session of root up to the flags, without running the command
+func prepare(t *testing.T, root *cli.Command, line string) *cli.Command {
+	args, err := root.ParseArgs(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetArgs(args)
+	cmd, flags, err := root.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cmd.ParseFlags(flags); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestTemplate_SessionsDoNotShareFlags(t *testing.T) {
+	out1, out2 := &bytes.Buffer{}, &bytes.Buffer{}
+	root1 := NewTemplate(nil, out1, out1).Run(hostTemplate)
+	root2 := NewTemplate(nil, out2, out2).Run(hostTemplate)
+
+	// the second session parses its flags while the first one is running its command
+	cmd1 := prepare(t, root1, "foo -t one -r 2")
+	cmd2 := prepare(t, root2, "foo --text two")
+	cmd1.Run(cmd1, 1, nil)
+	cmd2.Run(cmd2, 2, nil)
+
+	if out1.String() != "oneone" {
+		t.Errorf("expected oneone in the first session, got %q", out1.String())
+	}
+	if out2.String() != "two" {
+		t.Errorf("expected two in the second session, got %q", out2.String())
+	}
+
+	// a new parse in a session starts from the defaults and leaves the other one alone
+	prepare(t, root2, "foo")
+	cmd1.Run(cmd1, 1, nil)
+	if out1.String() != "oneoneoneone" {
+		t.Errorf("expected the first session to keep its flags, got %q", out1.String())
+	}
+}
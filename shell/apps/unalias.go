@@ -0,0 +1,48 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+)
+
+func CreateUnalias(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "unalias"
+	root.Short = "Remove aliases"
+	root.Long = "Remove the given aliases"
+	root.ValidArgsFunction = func(cmd *cli.Command, _ []string, _ string) []cli.Completion {
+		var out []cli.Completion
+		for _, name := range cmd.GetRootContext().ListAliases() {
+			out = append(out, cli.Completion{Value: name})
+		}
+		return out
+	}
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		if len(args) == 0 {
+			cmd.SetExitCode(cli.ExitUsage)
+			return
+		}
+		for _, name := range args {
+			if err := r.UnsetAlias(name); err != nil {
+				cmd.PrintErrf(cli.DefaultEol+"%s"+cli.DefaultEol, err.Error())
+				cmd.SetExitCode(cli.ExitFailure)
+			}
+		}
+	}
+	return root
+}
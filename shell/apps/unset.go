@@ -0,0 +1,48 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+)
+
+func CreateUnset(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "unset"
+	root.Short = "Unset variables"
+	root.Long = "Remove the given session variables, exported ones are also removed from the user settings"
+	function := root.Flags().BoolP("function", "f", false, "remove functions")
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		if len(args) == 0 {
+			cmd.SetExitCode(cli.ExitUsage)
+			return
+		}
+		for _, name := range args {
+			var err error
+			if *function {
+				err = r.UnsetFunction(name)
+			} else {
+				err = r.UnsetVar(name)
+			}
+			if err != nil {
+				cmd.PrintErrf(cli.DefaultEol+"%s"+cli.DefaultEol, err.Error())
+				cmd.SetExitCode(cli.ExitFailure)
+			}
+		}
+	}
+	return root
+}
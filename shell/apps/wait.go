@@ -0,0 +1,81 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apps
+
+import (
+	"github.com/markel1974/goshell/shell/apps/commandcreator"
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"strconv"
+)
+
+// waitInterval is the delay between the checks of the jobs waited for, in milliseconds
+const waitInterval = 100
+
+func CreateWait(t commandcreator.ICreator) *cli.Command {
+	root := t.CreateCommand()
+	root.Use = "wait"
+	root.Short = "Wait for jobs"
+	root.Long = "Wait for the given jobs to end, for the running jobs without arguments. " +
+		"The exit status is the one of the last job, ctrl-c stops waiting. " + jobsHelp
+	root.Activate = true
+	root.ValidArgsFunction = completeJobs
+	root.Run = func(cmd *cli.Command, pid int, args []string) {
+		r := cmd.GetRootContext()
+		var ids []int
+		if len(args) == 0 {
+			for _, job := range r.Jobs() {
+				if job.State == interfaces.JobRunning {
+					ids = append(ids, job.Id)
+				}
+			}
+		}
+		for _, arg := range args {
+			job, ok := r.FindJob(arg)
+			if !ok {
+				cmd.PrintErrf(cli.DefaultEol+"Unknown job: %s"+cli.DefaultEol, arg)
+				cmd.SetExitCode(cli.ExitNotFound)
+				r.Deactivate(pid)
+				return
+			}
+			ids = append(ids, job.Id)
+		}
+		r.SetContext(pid, ids)
+		waitJobs(cmd, pid, ids)
+	}
+	root.TimerEvent = func(cmd *cli.Command, pid int, tid int, ctx interface{}, interval int) {
+		waitJobs(cmd, pid, ctx.([]int))
+	}
+	return root
+}
+
+// waitJobs ends wait once the jobs have ended, otherwise it checks them again after waitInterval
+func waitJobs(cmd *cli.Command, pid int, ids []int) {
+	r := cmd.GetRootContext()
+	status := cli.ExitOK
+	for _, id := range ids {
+		job, ok := r.FindJob("%" + strconv.Itoa(id))
+		if !ok {
+			continue
+		}
+		if job.State != interfaces.JobDone {
+			r.CreateTimer(pid, waitInterval, waitInterval, 1)
+			return
+		}
+		status = job.Status
+	}
+	cmd.SetExitCode(status)
+	r.Deactivate(pid)
+}
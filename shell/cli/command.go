@@ -16,6 +16,7 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/markel1974/goshell/shell/cli/mflag"
@@ -28,10 +29,25 @@ import (
 
 var CompOneRequiredFlag = "completion_one_required_flag"
 
+// AnnotationRoles is the annotation listing, comma separated, the roles required to run a command
+var AnnotationRoles = "roles"
+
 var ErrSubCommandRequired = errors.New("subcommand is required")
 
 var DefaultEol = "\r\n"
 
+// Exit status of a command
+const (
+	ExitOK       = 0
+	ExitFailure  = 1
+	ExitUsage    = 2
+	ExitNotFound = 127
+	// ExitInterrupt is the status of a command interrupted before its end
+	ExitInterrupt = 130
+	// ExitStopped is the status of a command stopped with ctrl-z
+	ExitStopped = 148
+)
+
 type FParseErrWhitelist mflag.ParseErrorsWhitelist
 
 type Command struct {
@@ -42,6 +58,7 @@ type Command struct {
 	Long                       string
 	Example                    string
 	ValidArgs                  []string
+	ValidArgsFunction          CompletionFunc
 	Args                       PositionalArgs
 	ArgAliases                 []string
 	Deprecated                 string
@@ -58,8 +75,10 @@ type Command struct {
 	TraverseChildren           bool
 	FParseErrWhitelist         FParseErrWhitelist
 
-	Activate       bool
-	Background     bool
+	Activate   bool
+	Background bool
+	// Paged shows the output written to the terminal in a pager when it is longer than the screen
+	Paged          bool
 	CommonCommands bool
 	Pid            int
 
@@ -81,6 +100,9 @@ type Command struct {
 	PreRunE func(cmd *Command, pid int, args []string) error
 	// Run: Typically the actual work function. Most commands will only implement this.
 	Run func(cmd *Command, pid int, args []string)
+	// RunContext: Run off the event loop, ctx is cancelled when the command is interrupted or the session ends.
+	// The output is written with the Print functions of cmd, never through the root context.
+	RunContext func(ctx context.Context, cmd *Command, pid int, args []string)
 
 	TimerEvent func(cmd *Command, pid int, tid int, ctx interface{}, interval int)
 
@@ -114,6 +136,23 @@ type Command struct {
 
 	rootCtx interfaces.IContext
 
+	// base is the position in the tree used to resolve the relative commands, kept by the root.
+	base *Command
+
+	// envFunc resolves the $VAR references found while parsing a command line.
+	envFunc func(string) (string, bool)
+	// substituteFunc runs the $(...) and backtick substitutions found while parsing a command line.
+	substituteFunc func(string) (string, error)
+	// flagCompletionFuncs complete the values of the flags, by flag name.
+	flagCompletionFuncs map[string]CompletionFunc
+	// userCommandsFunc returns the commands defined by the user, shown in the help of the root.
+	userCommandsFunc func() []UserCommand
+	// runContextFunc starts the RunContext of the commands, only the root has it.
+	runContextFunc func(cmd *Command, pid int, run func(ctx context.Context))
+
+	// exitCode is the exit status of the last run
+	exitCode int
+
 	// args is actual args parsed from flags.
 	args []string
 	// flagErrorBuf contains all error messages from pflag.
@@ -162,7 +201,7 @@ func NewCommand() *Command {
 }
 
 func (c *Command) Parse(line string) bool {
-	args, err := Parse(line)
+	args, err := c.ParseArgs(line)
 	if err != nil {
 		return false
 	}
@@ -170,6 +209,37 @@ func (c *Command) Parse(line string) bool {
 	return true
 }
 
+// ParseArgs splits line in arguments, expanding the variables and the command substitutions.
+func (c *Command) ParseArgs(line string) ([]string, error) {
+	return c.newParser().Parse(line)
+}
+
+// ParseLine splits line in pipelines and stages. Variables are not expanded,
+// every stage is parsed again with Parse right before running it.
+func (c *Command) ParseLine(line string) ([]*Pipeline, error) {
+	p := NewParser()
+	p.ParseEnv = false
+	return p.ParseLine(line)
+}
+
+// SetArgs sets the arguments used by Prepare to find the command to run.
+func (c *Command) SetArgs(args []string) {
+	c.args = args
+}
+
+func (c *Command) newParser() *Parser {
+	p := NewParser()
+	if c.envFunc != nil {
+		p.ParseEnv = true
+		p.LookupEnv = c.envFunc
+	}
+	if c.substituteFunc != nil {
+		p.ParseBacktick = true
+		p.Substitute = c.substituteFunc
+	}
+	return p
+}
+
 // SetOutput sets the destination for usage and error messages.
 // If output is nil, os.Stderr is used.
 // Deprecated: Use SetOut and/or SetErr instead
@@ -193,6 +263,81 @@ func (c *Command) SetIn(newIn io.Reader) {
 	c.inReader = newIn
 }
 
+// SetExitCode sets the exit status of the running command.
+// A command that does not set it exits with ExitOK, or ExitFailure when RunE fails.
+func (c *Command) SetExitCode(code int) {
+	c.exitCode = code
+}
+
+// ExitCode returns the exit status of the last run.
+func (c *Command) ExitCode() int {
+	return c.exitCode
+}
+
+// SetEnvFunc sets the function used to resolve variables when a line is parsed,
+// it returns false for the undefined variables.
+func (c *Command) SetEnvFunc(f func(string) (string, bool)) {
+	c.envFunc = f
+}
+
+// SetSubstituteFunc sets the function running the command substitutions when a line is parsed.
+func (c *Command) SetSubstituteFunc(f func(string) (string, error)) {
+	c.substituteFunc = f
+}
+
+// SetRunContextFunc sets the function starting the RunContext of the commands, they run in place without it.
+func (c *Command) SetRunContextFunc(f func(cmd *Command, pid int, run func(ctx context.Context))) {
+	c.runContextFunc = f
+}
+
+// runContext starts RunContext with the function set on the root
+func (c *Command) runContext(pid int, args []string) {
+	run := func(ctx context.Context) {
+		c.RunContext(ctx, c, pid, args)
+	}
+	if f := c.Root().runContextFunc; f != nil {
+		f(c, pid, run)
+		return
+	}
+	run(context.Background())
+}
+
+// SetUserCommandsFunc sets the function listing the commands defined by the user, like aliases and functions.
+func (c *Command) SetUserCommandsFunc(f func() []UserCommand) {
+	c.userCommandsFunc = f
+}
+
+// UserCommands returns the commands defined by the user, only the root has them.
+func (c *Command) UserCommands() []UserCommand {
+	if c.HasParent() || c.userCommandsFunc == nil {
+		return nil
+	}
+	out := c.userCommandsFunc()
+	padding := minNamePadding
+	for _, u := range out {
+		if len(u.Name) > padding {
+			padding = len(u.Name)
+		}
+	}
+	for i := range out {
+		out[i].Padding = padding
+	}
+	return out
+}
+
+// HasUserCommands determines if the command lists commands defined by the user.
+func (c *Command) HasUserCommands() bool {
+	return len(c.UserCommands()) > 0
+}
+
+// ExpandEnv expands the variables referenced by word.
+func (c *Command) ExpandEnv(word string) string {
+	if c.envFunc == nil {
+		return word
+	}
+	return lookupEnv(c.envFunc, word)
+}
+
 func (c *Command) SetRootContext(ctx interfaces.IContext) {
 	c.rootCtx = ctx
 }
@@ -475,7 +620,10 @@ Examples:
 {{.Example}}{{end}}{{if .HasAvailableSubCommands}}
 
 Available Commands:{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
-  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasUserCommands}}
+
+User Commands:{{range .UserCommands}}
+  {{rpad .Name .Padding}} {{.Short}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
 
 Flags:
 {{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableInheritedFlags}}
@@ -756,6 +904,7 @@ func (c *Command) execute(a []string, pid int) (err error) {
 
 	err = c.ParseFlags(a)
 	if err != nil {
+		c.SetExitCode(ExitUsage)
 		return c.FlagErrorFunc()(c, err)
 	}
 
@@ -800,6 +949,7 @@ func (c *Command) execute(a []string, pid int) (err error) {
 	}
 
 	if err := c.ValidateArgs(argWoFlags); err != nil {
+		c.SetExitCode(ExitUsage)
 		return err
 	}
 
@@ -823,12 +973,15 @@ func (c *Command) execute(a []string, pid int) (err error) {
 	}
 
 	if err := c.validateRequiredFlags(); err != nil {
+		c.SetExitCode(ExitUsage)
 		return err
 	}
 	if c.RunE != nil {
 		if err := c.RunE(c, pid, argWoFlags); err != nil {
 			return err
 		}
+	} else if c.RunContext != nil {
+		c.runContext(pid, argWoFlags)
 	} else {
 		c.Run(c, pid, argWoFlags)
 	}
@@ -876,7 +1029,7 @@ func (c *Command) prepareCommand() (*Command, []string, error) {
 	// initialize help as the last point possible to allow for user overriding
 	c.InitDefaultHelpCmd()
 
-	args := c.args
+	args := c.resolveArgs(c.args)
 
 	if c.TraverseChildren {
 		cmd, flags, err = c.Traverse(args)
@@ -890,8 +1043,8 @@ func (c *Command) prepareCommand() (*Command, []string, error) {
 			z = cmd
 		}
 		if !z.SilenceErrors {
-			z.Printf(DefaultEol+"Error %s"+DefaultEol, err.Error())
-			z.Printf("Run '%v --help' for usage."+DefaultEol, c.CommandPath())
+			z.PrintErrf(DefaultEol+"Error %s"+DefaultEol, err.Error())
+			z.PrintErrf("Run '%v --help' for usage."+DefaultEol, c.CommandPath())
 		}
 		return z, flags, err
 	}
@@ -927,7 +1080,7 @@ func (c *Command) Execute(cmd *Command, flags []string, pid int) error {
 
 		// If root command has SilentErrors flagged
 		if !cmd.SilenceErrors && !c.SilenceErrors {
-			c.Println(DefaultEol+"Error:", err.Error(), DefaultEol)
+			c.PrintErrln(DefaultEol+"Error:", err.Error(), DefaultEol)
 		}
 
 		// If root command has SilentUsage flagged
@@ -1017,6 +1170,14 @@ func (c *Command) InitDefaultHelpCmd() {
 			Short: "Help about any command",
 			Long:  `Help provides help for any command in the application. Simply type ` + c.Name() + ` help [path to command] for full details.`,
 			Run: func(c *Command, pid int, args []string) {
+				if len(args) == 1 {
+					for _, u := range c.Root().UserCommands() {
+						if u.Name == args[0] {
+							c.Printf(DefaultEol+"%s: %s"+DefaultEol, u.Name, u.Short)
+							return
+						}
+					}
+				}
 				cmd, _, e := c.Root().Find(args)
 				if cmd == nil || e != nil {
 					c.Printf("Unknown help topic %#q"+DefaultEol, args)
@@ -1142,12 +1303,12 @@ func (c *Command) PrintErr(i ...interface{}) {
 
 // PrintErrln is a convenience method to Println to the defined Err output, fallback to Stderr if not set.
 func (c *Command) PrintErrln(i ...interface{}) {
-	c.Print(fmt.Sprintln(i...))
+	c.PrintErr(fmt.Sprintln(i...))
 }
 
 // PrintErrf is a convenience method to Printf to the defined Err output, fallback to Stderr if not set.
 func (c *Command) PrintErrf(format string, i ...interface{}) {
-	c.Print(fmt.Sprintf(format, i...))
+	c.PrintErr(fmt.Sprintf(format, i...))
 }
 
 // CommandPath returns the full path to this command.
@@ -1266,6 +1427,17 @@ func (c *Command) NameAndAliases() string {
 	return strings.Join(append([]string{c.Name()}, c.Aliases...), ", ")
 }
 
+// Roles returns the roles required to run the command, read from the AnnotationRoles annotation.
+func (c *Command) Roles() []string {
+	var roles []string
+	for _, role := range strings.Split(c.Annotations[AnnotationRoles], ",") {
+		if role = strings.TrimSpace(role); len(role) > 0 {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
 // HasExample determines if the command has example.
 func (c *Command) HasExample() bool {
 	return len(c.Example) > 0
@@ -1273,7 +1445,7 @@ func (c *Command) HasExample() bool {
 
 // Runnable determines if the command is itself runnable.
 func (c *Command) Runnable() bool {
-	return c.Run != nil || c.RunE != nil
+	return c.Run != nil || c.RunE != nil || c.RunContext != nil
 }
 
 // HasSubCommands determines if the command has children commands.
@@ -1542,6 +1714,9 @@ func (c *Command) ParseFlags(args []string) error {
 	//do it here after merging all flags and just before parse
 	c.Flags().ParseErrorsWhitelist = mflag.ParseErrorsWhitelist(c.FParseErrWhitelist)
 
+	// flags are bound once to the command, every invocation starts from the defaults
+	c.Flags().ResetFlags()
+
 	err := c.Flags().Parse(args)
 	// Print warnings if they occurred (e.g. deprecated flag messages).
 	if c.flagErrorBuf.Len()-beforeErrorBufLen > 0 && err == nil {
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCommand_RunContext(t *testing.T) {
+	root := &Command{Use: "root"}
+	var got []string
+	var cancelled bool
+	sleep := &Command{
+		Use: "sleep",
+		RunContext: func(ctx context.Context, cmd *Command, pid int, args []string) {
+			cancelled = ctx.Err() != nil
+			got = args
+		},
+	}
+	root.AddCommand(sleep)
+	if !sleep.Runnable() {
+		t.Fatalf("expected a RunContext command to be runnable")
+	}
+
+	// without a function the command runs in place
+	if err := root.Execute(sleep, []string{"1"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "1" || cancelled {
+		t.Errorf("expected args [1] and a live context, got %v %v", got, cancelled)
+	}
+
+	var started *Command
+	var startedPid int
+	root.SetRunContextFunc(func(cmd *Command, pid int, run func(ctx context.Context)) {
+		started, startedPid = cmd, pid
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		run(ctx)
+	})
+	got = nil
+	if err := root.Execute(sleep, []string{"2"}, 7); err != nil {
+		t.Fatal(err)
+	}
+	if started != sleep || startedPid != 7 {
+		t.Errorf("expected the root function to start sleep as 7, got %v %d", started, startedPid)
+	}
+	if len(got) != 1 || got[0] != "2" || !cancelled {
+		t.Errorf("expected args [2] and the context of the function, got %v %v", got, cancelled)
+	}
+}
@@ -0,0 +1,235 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"github.com/markel1974/goshell/shell/cli/mflag"
+	"sort"
+	"strings"
+)
+
+// Completion is a candidate offered by the tab completion, the description is shown next to the value.
+type Completion struct {
+	Value       string
+	Description string
+}
+
+// CompletionFunc returns the candidates for toComplete, args are the positional arguments already typed.
+// The candidates not starting with toComplete are discarded by the caller.
+type CompletionFunc func(cmd *Command, args []string, toComplete string) []Completion
+
+// RegisterFlagCompletionFunc sets the function completing the values of the named flag.
+func (c *Command) RegisterFlagCompletionFunc(name string, f CompletionFunc) error {
+	if c.Flag(name) == nil {
+		return fmt.Errorf("flag %q does not exist for %q", name, c.CommandPath())
+	}
+	if c.flagCompletionFuncs == nil {
+		c.flagCompletionFuncs = make(map[string]CompletionFunc)
+	}
+	c.flagCompletionFuncs[name] = f
+	return nil
+}
+
+// GetFlagCompletionFunc returns the function completing the values of the named flag,
+// persistent flags are looked up in the parents too.
+func (c *Command) GetFlagCompletionFunc(name string) (CompletionFunc, bool) {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if f, ok := cmd.flagCompletionFuncs[name]; ok {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// Complete returns the candidates for the last word of a command line, args are the words before it.
+// Subcommands, flags, flag values and positional args are completed, starting from c.
+// Commands are looked up from the base first, then from the root.
+func (c *Command) Complete(args []string, toComplete string) []Completion {
+	if len(args) == 0 && !strings.HasPrefix(toComplete, "-") {
+		if strings.HasPrefix(toComplete, PathSeparator) {
+			return c.CompletePath(toComplete, false)
+		}
+		if base := c.Base(); base != c {
+			return mergeCompletions(base.completeArgs(nil, toComplete), c.completeArgs(nil, toComplete))
+		}
+	}
+	args = c.resolveArgs(args)
+
+	cmd := c
+	var positional []string
+	var pending *mflag.Flag
+	used := make(map[string]bool)
+	dashed := false
+
+	for _, arg := range args {
+		switch {
+		case pending != nil:
+			pending = nil
+		case dashed:
+			positional = append(positional, arg)
+		case arg == "--":
+			dashed = true
+		case len(arg) > 1 && strings.HasPrefix(arg, "-"):
+			if f, value := cmd.lookupFlagArg(arg); f != nil {
+				used[f.Name] = true
+				if !value && f.NoOptDefVal == "" {
+					pending = f
+				}
+			}
+		default:
+			if len(positional) == 0 {
+				if next := cmd.findNext(arg); next != nil {
+					cmd = next
+					continue
+				}
+			}
+			positional = append(positional, arg)
+		}
+	}
+
+	if pending != nil {
+		return cmd.completeFlagValue(pending, positional, toComplete, "")
+	}
+	if !dashed && strings.HasPrefix(toComplete, "-") {
+		if idx := strings.Index(toComplete, "="); idx > 2 && strings.HasPrefix(toComplete, "--") {
+			f := cmd.Flags().Lookup(toComplete[2:idx])
+			if f == nil {
+				return nil
+			}
+			return cmd.completeFlagValue(f, positional, toComplete[idx+1:], toComplete[:idx+1])
+		}
+		return cmd.completeFlags(toComplete, used)
+	}
+	return cmd.completeArgs(positional, toComplete)
+}
+
+// lookupFlagArg returns the flag named by arg and whether arg carries its value too, like --name=value or -nvalue.
+func (c *Command) lookupFlagArg(arg string) (*mflag.Flag, bool) {
+	c.mergePersistentFlags()
+	if strings.HasPrefix(arg, "--") {
+		name := arg[2:]
+		value := false
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name = name[:idx]
+			value = true
+		}
+		return c.Flags().Lookup(name), value
+	}
+	return c.Flags().ShorthandLookup(arg[1:2]), len(arg) > 2
+}
+
+func (c *Command) completeFlags(toComplete string, used map[string]bool) []Completion {
+	c.mergePersistentFlags()
+	var out []Completion
+	c.Flags().VisitAll(func(f *mflag.Flag) {
+		if f.Hidden || len(f.Deprecated) > 0 {
+			return
+		}
+		if used[f.Name] && !isRepeatableFlag(f) {
+			return
+		}
+		if name := "--" + f.Name; strings.HasPrefix(name, toComplete) {
+			out = append(out, Completion{Value: name, Description: f.Usage})
+		}
+		if len(f.Shorthand) > 0 && len(f.ShorthandDeprecated) == 0 {
+			if name := "-" + f.Shorthand; strings.HasPrefix(name, toComplete) {
+				out = append(out, Completion{Value: name, Description: f.Usage})
+			}
+		}
+	})
+	return out
+}
+
+// isRepeatableFlag determines if the flag can be given more than once.
+func isRepeatableFlag(f *mflag.Flag) bool {
+	switch f.Value.Type() {
+	case "count", "stringSlice", "stringToString":
+		return true
+	}
+	return false
+}
+
+func (c *Command) completeFlagValue(f *mflag.Flag, args []string, toComplete string, prefix string) []Completion {
+	var candidates []Completion
+	if fn, ok := c.GetFlagCompletionFunc(f.Name); ok {
+		candidates = fn(c, args, toComplete)
+	} else if enum, ok := f.Value.(interface{ Allowed() []string }); ok {
+		for _, v := range enum.Allowed() {
+			candidates = append(candidates, Completion{Value: v})
+		}
+	} else if f.Value.Type() == "bool" {
+		candidates = []Completion{{Value: "true"}, {Value: "false"}}
+	}
+
+	var out []Completion
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate.Value, toComplete) {
+			candidate.Value = prefix + candidate.Value
+			out = append(out, candidate)
+		}
+	}
+	return out
+}
+
+func (c *Command) completeArgs(args []string, toComplete string) []Completion {
+	var out []Completion
+	if len(args) == 0 {
+		for _, cmd := range c.Commands() {
+			if cmd.IsAvailableCommand() && strings.HasPrefix(cmd.Name(), toComplete) {
+				out = append(out, Completion{Value: cmd.Name(), Description: cmd.Short})
+			}
+		}
+		for _, u := range c.UserCommands() {
+			if strings.HasPrefix(u.Name, toComplete) {
+				out = append(out, Completion{Value: u.Name, Description: u.Short})
+			}
+		}
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Value < out[j].Value })
+	}
+
+	// a valid arg can carry its description after a tab
+	for _, v := range c.ValidArgs {
+		candidate := Completion{Value: v}
+		if idx := strings.Index(v, "\t"); idx >= 0 {
+			candidate = Completion{Value: v[:idx], Description: v[idx+1:]}
+		}
+		if strings.HasPrefix(candidate.Value, toComplete) {
+			out = append(out, candidate)
+		}
+	}
+	if c.ValidArgsFunction != nil {
+		for _, candidate := range c.ValidArgsFunction(c, args, toComplete) {
+			if strings.HasPrefix(candidate.Value, toComplete) {
+				out = append(out, candidate)
+			}
+		}
+	}
+	return out
+}
+
+// mergeCompletions appends the candidates of b not already in a.
+func mergeCompletions(a []Completion, b []Completion) []Completion {
+	seen := make(map[string]bool)
+	for _, candidate := range a {
+		seen[candidate.Value] = true
+	}
+	for _, candidate := range b {
+		if !seen[candidate.Value] {
+			a = append(a, candidate)
+		}
+	}
+	return a
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func newCompletionTree() *Command {
+	root := NewCommand()
+	stats := &Command{Use: "stats", Short: "Statistics"}
+	memory := &Command{Use: "memory", Short: "Memory usage", Run: func(*Command, int, []string) {}}
+	memory.Flags().StringP("unit", "u", "", "unit")
+	memory.Flags().Enum("format", "text", []string{"text", "json"}, "format")
+	memory.Flags().BoolP("verbose", "v", false, "verbose")
+	memory.ValidArgs = []string{"heap\tthe heap", "stack"}
+	_ = memory.RegisterFlagCompletionFunc("unit", func(*Command, []string, string) []Completion {
+		return []Completion{{Value: "kb"}, {Value: "mb"}}
+	})
+	kill := &Command{Use: "kill", Short: "Kill", Run: func(*Command, int, []string) {}}
+	kill.ValidArgsFunction = func(_ *Command, args []string, _ string) []Completion {
+		if len(args) > 0 {
+			return nil
+		}
+		return []Completion{{Value: "12", Description: "snake"}, {Value: "13", Description: "tetris"}}
+	}
+	_ = stats.AddCommand(memory)
+	_ = root.AddCommand(stats, kill)
+	return root
+}
+
+func TestCommand_Complete(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		toComplete string
+		expected   []string
+	}{
+		{"commands", "", "", []string{"kill", "stats"}},
+		{"command prefix", "", "st", []string{"stats"}},
+		{"subcommand", "stats", "m", []string{"memory"}},
+		{"valid args", "stats memory", "", []string{"heap", "stack"}},
+		{"long flags", "stats memory", "--", []string{"--format", "--unit", "--verbose"}},
+		{"used flag", "stats memory --verbose", "--", []string{"--format", "--unit"}},
+		{"flags and shorthands", "stats memory", "-", []string{"--format", "--unit", "-u", "--verbose", "-v"}},
+		{"enum value", "stats memory --format", "j", []string{"json"}},
+		{"inline enum value", "stats memory", "--format=", []string{"--format=text", "--format=json"}},
+		{"function value", "stats memory -u", "", []string{"kb", "mb"}},
+		{"after flag value", "stats memory -u kb", "s", []string{"stack"}},
+		{"dynamic args", "kill", "1", []string{"12", "13"}},
+		{"dynamic args done", "kill 12", "", nil},
+		{"unknown", "nothing", "", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var result []string
+			for _, c := range newCompletionTree().Complete(strings.Fields(tc.line), tc.toComplete) {
+				result = append(result, c.Value)
+			}
+			if strings.Join(result, " ") != strings.Join(tc.expected, " ") {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestCommand_CompleteDescriptions(t *testing.T) {
+	result := newCompletionTree().Complete([]string{"stats", "memory"}, "h")
+	if len(result) != 1 || result[0].Description != "the heap" {
+		t.Errorf("expected the heap description, got %v", result)
+	}
+	result = newCompletionTree().Complete(nil, "k")
+	if len(result) != 1 || result[0].Description != "Kill" {
+		t.Errorf("expected the Short of kill, got %v", result)
+	}
+}
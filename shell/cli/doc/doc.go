@@ -0,0 +1,155 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package doc generates the documentation of a command tree as Markdown, roff man pages or a JSON schema.
+package doc
+
+import (
+	"encoding/json"
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/cli/mflag"
+	"io"
+	"strings"
+)
+
+// Command describes a command, its flags and its subcommands.
+type Command struct {
+	Name           string     `json:"name"`
+	Path           string     `json:"path"`
+	Usage          string     `json:"usage,omitempty"`
+	Short          string     `json:"short,omitempty"`
+	Long           string     `json:"long,omitempty"`
+	Aliases        []string   `json:"aliases,omitempty"`
+	Example        string     `json:"example,omitempty"`
+	Roles          []string   `json:"roles,omitempty"`
+	Deprecated     string     `json:"deprecated,omitempty"`
+	Flags          []Flag     `json:"flags,omitempty"`
+	InheritedFlags []Flag     `json:"inheritedFlags,omitempty"`
+	Commands       []*Command `json:"commands,omitempty"`
+}
+
+// Flag describes a flag of a command.
+type Flag struct {
+	Name      string   `json:"name"`
+	Shorthand string   `json:"shorthand,omitempty"`
+	Type      string   `json:"type"`
+	Default   string   `json:"default,omitempty"`
+	Usage     string   `json:"usage,omitempty"`
+	Allowed   []string `json:"allowed,omitempty"`
+	Required  bool     `json:"required,omitempty"`
+}
+
+// Describe returns the description of cmd and of its available subcommands.
+func Describe(cmd *cli.Command) *Command {
+	d := &Command{
+		Name:           cmd.Name(),
+		Path:           cmd.Path(),
+		Short:          cmd.Short,
+		Long:           cmd.Long,
+		Aliases:        cmd.Aliases,
+		Example:        cmd.Example,
+		Roles:          cmd.Roles(),
+		Deprecated:     cmd.Deprecated,
+		Flags:          describeFlags(cmd.LocalFlags()),
+		InheritedFlags: describeFlags(cmd.InheritedFlags()),
+	}
+	if cmd.Runnable() {
+		d.Usage = strings.TrimSpace(cmd.UseLine())
+	}
+	for _, sub := range visibleCommands(cmd) {
+		d.Commands = append(d.Commands, Describe(sub))
+	}
+	return d
+}
+
+// GenJSON writes the description of the tree rooted at cmd as indented JSON.
+func GenJSON(cmd *cli.Command, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Describe(cmd))
+}
+
+// Title returns the name of the command as typed at the prompt, the root is the path separator.
+func (d *Command) Title() string {
+	if d.anonymous() {
+		return cli.PathSeparator
+	}
+	if d.Path == cli.PathSeparator {
+		return d.Name
+	}
+	return strings.Join(strings.Split(strings.TrimPrefix(d.Path, cli.PathSeparator), cli.PathSeparator), " ")
+}
+
+// Walk calls fn for d and for every subcommand, depth first.
+func (d *Command) Walk(fn func(d *Command) error) error {
+	if err := fn(d); err != nil {
+		return err
+	}
+	for _, sub := range d.Commands {
+		if err := sub.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// anonymous determines if d is a root without a name
+func (d *Command) anonymous() bool {
+	return d.Path == cli.PathSeparator && len(d.Name) == 0
+}
+
+// baseName is the file name of the page of d, without extension.
+func (d *Command) baseName() string {
+	if d.anonymous() {
+		return "index"
+	}
+	if d.Path == cli.PathSeparator {
+		return d.Name
+	}
+	return strings.ReplaceAll(strings.TrimPrefix(d.Path, cli.PathSeparator), cli.PathSeparator, "_")
+}
+
+func visibleCommands(cmd *cli.Command) []*cli.Command {
+	var out []*cli.Command
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() || sub.IsAdditionalHelpTopicCommand() {
+			out = append(out, sub)
+		}
+	}
+	return out
+}
+
+func describeFlags(flags *mflag.FlagSet) []Flag {
+	var out []Flag
+	flags.VisitAll(func(f *mflag.Flag) {
+		if f.Hidden || len(f.Deprecated) > 0 {
+			return
+		}
+		flag := Flag{
+			Name:     f.Name,
+			Type:     f.Value.Type(),
+			Default:  f.DefValue,
+			Usage:    f.Usage,
+			Required: len(f.Annotations[cli.CompOneRequiredFlag]) > 0 && f.Annotations[cli.CompOneRequiredFlag][0] == "true",
+		}
+		if len(f.ShorthandDeprecated) == 0 {
+			flag.Shorthand = f.Shorthand
+		}
+		if enum, ok := f.Value.(interface{ Allowed() []string }); ok {
+			flag.Allowed = enum.Allowed()
+		}
+		out = append(out, flag)
+	})
+	return out
+}
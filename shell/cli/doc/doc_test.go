@@ -0,0 +1,148 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/markel1974/goshell/shell/cli"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newDocTree() *cli.Command {
+	run := func(cmd *cli.Command, pid int, args []string) {}
+
+	root := cli.NewCommand()
+	stats := cli.NewCommand()
+	stats.Use = "stats"
+	stats.Short = "System stats"
+	stats.Aliases = []string{"st"}
+	stats.PersistentFlags().BoolP("verbose", "v", false, "verbose | detailed output")
+
+	memory := cli.NewCommand()
+	memory.Use = "memory [pid]"
+	memory.Short = "Memory usage"
+	memory.Long = "Print the memory usage.\n\n.dot lines are escaped"
+	memory.Example = "stats memory 12"
+	memory.Annotations = map[string]string{cli.AnnotationRoles: "admin, operator"}
+	memory.Run = run
+	var format string
+	memory.Flags().EnumVarP(&format, "format", "f", "table", []string{"table", "json"}, "output format")
+	memory.Flags().String("unit", "", "unit of the sizes")
+	_ = memory.Flags().SetAnnotation("unit", cli.CompOneRequiredFlag, []string{"true"})
+
+	secret := cli.NewCommand()
+	secret.Use = "secret"
+	secret.Hidden = true
+	secret.Run = run
+
+	_ = stats.AddCommand(memory)
+	_ = stats.AddCommand(secret)
+	_ = root.AddCommand(stats)
+	return root
+}
+
+func TestDescribe(t *testing.T) {
+	d := Describe(newDocTree())
+	if len(d.Commands) != 1 || len(d.Commands[0].Commands) != 1 {
+		t.Fatalf("expected the hidden command to be skipped, got %+v", d)
+	}
+	memory := d.Commands[0].Commands[0]
+	if memory.Path != "/stats/memory" || memory.Title() != "stats memory" || memory.Usage != "stats memory [pid] [flags]" {
+		t.Errorf("unexpected names %q %q %q", memory.Path, memory.Title(), memory.Usage)
+	}
+	if strings.Join(memory.Roles, ",") != "admin,operator" {
+		t.Errorf("unexpected roles %v", memory.Roles)
+	}
+	if len(memory.Flags) != 2 || len(memory.InheritedFlags) != 1 {
+		t.Fatalf("unexpected flags %+v %+v", memory.Flags, memory.InheritedFlags)
+	}
+	format := memory.Flags[0]
+	if format.Name != "format" || format.Shorthand != "f" || format.Default != "table" || strings.Join(format.Allowed, ",") != "table,json" {
+		t.Errorf("unexpected flag %+v", format)
+	}
+	if !memory.Flags[1].Required {
+		t.Errorf("expected unit to be required")
+	}
+}
+
+func TestGenJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := GenJSON(newDocTree(), &b); err != nil {
+		t.Fatal(err)
+	}
+	var d Command
+	if err := json.Unmarshal(b.Bytes(), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Path != "/" || d.Commands[0].Aliases[0] != "st" || d.Commands[0].Commands[0].Example != "stats memory 12" {
+		t.Errorf("unexpected schema %s", b.String())
+	}
+}
+
+func TestGenMarkdown(t *testing.T) {
+	var b bytes.Buffer
+	if err := GenMarkdown(newDocTree(), &b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, expected := range []string{
+		"## stats memory\n",
+		"* [stats memory](#stats-memory) - Memory usage",
+		"* [stats](#stats) - System stats",
+		"### Required roles\n\n`admin`, `operator`",
+		"| `-f`, `--format` | enum | `table` | output format (one of: table, json) |",
+		"| `--unit` | string |  | unit of the sizes (required) |",
+		"| `-v`, `--verbose` | bool | `false` | verbose \\| detailed output |",
+		"### Examples\n\n```\nstats memory 12\n```",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "secret") {
+		t.Errorf("unexpected hidden command in\n%s", out)
+	}
+}
+
+func TestGenMan(t *testing.T) {
+	root := newDocTree()
+	memory, _ := root.Resolve("/stats/memory")
+	var b bytes.Buffer
+	if err := GenMan(memory, &ManHeader{Source: "goshell"}, &b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, expected := range []string{
+		".TH \"STATS-MEMORY\" \"1\" \"\" \"goshell\" \"Shell Commands\"\n",
+		"stats\\-memory \\- Memory usage\n",
+		".PP\n\\&.dot lines are escaped\n",
+		"\\fB\\-f\\fP, \\fB\\-\\-format\\fP \\fIenum\\fP\noutput format One of: table, json. Default: table.\n",
+		".SH REQUIRED ROLES\nadmin, operator\n",
+		".SH SEE ALSO\n\\fBstats\\fP(1)\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+}
+
+func TestGenTree(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenMarkdownTree(newDocTree(), dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenManTree(newDocTree(), nil, dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"index.md", "stats.md", "stats_memory.md", "stats.1", "stats-memory.1"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "stats.md"))
+	if !strings.Contains(string(data), "* [stats memory](stats_memory.md) - Memory usage") {
+		t.Errorf("unexpected page\n%s", data)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package doc
+
+import (
+	"fmt"
+	"github.com/markel1974/goshell/shell/cli"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManHeader is the title line of the generated man pages, the empty fields take a default.
+type ManHeader struct {
+	Section string
+	Date    string
+	Source  string
+	Manual  string
+}
+
+// GenMan writes the roff man page of cmd.
+func GenMan(cmd *cli.Command, header *ManHeader, w io.Writer) error {
+	d := Describe(cmd)
+	var parent *Command
+	if cmd.HasParent() {
+		if parent = Describe(cmd.Parent()); parent.anonymous() {
+			parent = nil
+		}
+	}
+	return writeMan(w, d, parent, fillHeader(header))
+}
+
+// GenManTree writes a man page for cmd and one for every subcommand into dir.
+func GenManTree(cmd *cli.Command, header *ManHeader, dir string) error {
+	return genManTree(Describe(cmd), nil, fillHeader(header), dir)
+}
+
+// genManTree writes the pages of the tree rooted at d, a root without a name has no page
+func genManTree(d *Command, parent *Command, header ManHeader, dir string) error {
+	if d.anonymous() {
+		for _, sub := range d.Commands {
+			if err := genManTree(sub, nil, header, dir); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	f, err := os.Create(filepath.Join(dir, manName(d)+"."+header.Section))
+	if err != nil {
+		return err
+	}
+	err = writeMan(f, d, parent, header)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	for _, sub := range d.Commands {
+		if err := genManTree(sub, d, header, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fillHeader(header *ManHeader) ManHeader {
+	var h ManHeader
+	if header != nil {
+		h = *header
+	}
+	if len(h.Section) == 0 {
+		h.Section = "1"
+	}
+	if len(h.Manual) == 0 {
+		h.Manual = "Shell Commands"
+	}
+	return h
+}
+
+// manName is the page name of d, the words of the path joined by a dash
+func manName(d *Command) string {
+	return strings.ReplaceAll(d.baseName(), "_", "-")
+}
+
+func writeMan(w io.Writer, d *Command, parent *Command, header ManHeader) error {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(".TH %q %q %q %q %q\n", strings.ToUpper(manName(d)), header.Section, header.Date, header.Source, header.Manual))
+	b.WriteString(".SH NAME\n")
+	name := roffEscape(manName(d))
+	if len(d.Short) > 0 {
+		name += " \\- " + roffEscape(d.Short)
+	}
+	b.WriteString(name + "\n")
+	if len(d.Usage) > 0 {
+		b.WriteString(".SH SYNOPSIS\n.B " + roffEscape(d.Usage) + "\n")
+	}
+	description := d.Long
+	if len(description) == 0 {
+		description = d.Short
+	}
+	if len(description) > 0 {
+		b.WriteString(".SH DESCRIPTION\n" + roffText(description, true) + "\n")
+	}
+	if len(d.Deprecated) > 0 {
+		b.WriteString(".PP\nDeprecated: " + roffEscape(d.Deprecated) + "\n")
+	}
+	if len(d.Aliases) > 0 {
+		b.WriteString(".SH ALIASES\n" + roffEscape(strings.Join(d.Aliases, ", ")) + "\n")
+	}
+	if len(d.Roles) > 0 {
+		b.WriteString(".SH REQUIRED ROLES\n" + roffEscape(strings.Join(d.Roles, ", ")) + "\n")
+	}
+	writeManFlags(&b, "OPTIONS", d.Flags)
+	writeManFlags(&b, "OPTIONS INHERITED FROM PARENT COMMANDS", d.InheritedFlags)
+	if len(d.Example) > 0 {
+		b.WriteString(".SH EXAMPLES\n.nf\n" + roffText(strings.TrimRight(d.Example, "\n"), false) + "\n.fi\n")
+	}
+	if len(d.Commands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, sub := range d.Commands {
+			b.WriteString(".TP\n.B " + roffEscape(sub.Name) + "\n" + roffEscape(sub.Short) + "\n")
+		}
+	}
+	var seeAlso []string
+	if parent != nil {
+		seeAlso = append(seeAlso, fmt.Sprintf("\\fB%s\\fP(%s)", roffEscape(manName(parent)), header.Section))
+	}
+	for _, sub := range d.Commands {
+		seeAlso = append(seeAlso, fmt.Sprintf("\\fB%s\\fP(%s)", roffEscape(manName(sub)), header.Section))
+	}
+	if len(seeAlso) > 0 {
+		b.WriteString(".SH SEE ALSO\n" + strings.Join(seeAlso, ", ") + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeManFlags(b *strings.Builder, title string, flags []Flag) {
+	if len(flags) == 0 {
+		return
+	}
+	b.WriteString(".SH " + title + "\n")
+	for _, f := range flags {
+		name := "\\fB\\-\\-" + roffEscape(f.Name) + "\\fP"
+		if len(f.Shorthand) > 0 {
+			name = "\\fB\\-" + roffEscape(f.Shorthand) + "\\fP, " + name
+		}
+		if f.Type != "bool" {
+			name += " \\fI" + roffEscape(f.Type) + "\\fP"
+		}
+		usage := roffEscape(f.Usage)
+		if len(f.Allowed) > 0 {
+			usage += " One of: " + roffEscape(strings.Join(f.Allowed, ", ")) + "."
+		}
+		if len(f.Default) > 0 {
+			usage += " Default: " + roffEscape(f.Default) + "."
+		}
+		if f.Required {
+			usage += " Required."
+		}
+		b.WriteString(".TP\n" + name + "\n" + strings.TrimSpace(usage) + "\n")
+	}
+}
+
+// roffText escapes a block of text, the lines starting with a control character are protected
+// and the blank lines break the paragraphs when paragraphs is set
+func roffText(s string, paragraphs bool) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = roffEscape(line)
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			line = "\\&" + line
+		}
+		if paragraphs && len(strings.TrimSpace(line)) == 0 {
+			line = ".PP"
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	return strings.ReplaceAll(s, "-", "\\-")
+}
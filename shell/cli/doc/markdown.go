@@ -0,0 +1,165 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package doc
+
+import (
+	"fmt"
+	"github.com/markel1974/goshell/shell/cli"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// linker returns the link target of the page of a command
+type linker func(d *Command) string
+
+// GenMarkdown writes the pages of cmd and of its subcommands as a single Markdown document.
+func GenMarkdown(cmd *cli.Command, w io.Writer) error {
+	return genMarkdown(Describe(cmd), nil, w)
+}
+
+func genMarkdown(d *Command, parent *Command, w io.Writer) error {
+	if err := writeMarkdown(w, d, parent, anchorLink); err != nil {
+		return err
+	}
+	for _, sub := range d.Commands {
+		if err := genMarkdown(sub, d, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenMarkdownTree writes a Markdown file for cmd and one for every subcommand into dir.
+func GenMarkdownTree(cmd *cli.Command, dir string) error {
+	return genMarkdownTree(Describe(cmd), nil, dir)
+}
+
+func genMarkdownTree(d *Command, parent *Command, dir string) error {
+	f, err := os.Create(filepath.Join(dir, d.baseName()+".md"))
+	if err != nil {
+		return err
+	}
+	err = writeMarkdown(f, d, parent, fileLink)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	for _, sub := range d.Commands {
+		if err := genMarkdownTree(sub, d, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func anchorLink(d *Command) string {
+	return "#" + strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ' || r == '-' || r == '_':
+			return '-'
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, d.Title()), "-")
+}
+
+func fileLink(d *Command) string {
+	return d.baseName() + ".md"
+}
+
+func writeMarkdown(w io.Writer, d *Command, parent *Command, link linker) error {
+	var b strings.Builder
+	b.WriteString("## " + d.Title() + "\n\n")
+	if len(d.Short) > 0 {
+		b.WriteString(d.Short + "\n\n")
+	}
+	if len(d.Deprecated) > 0 {
+		b.WriteString("**Deprecated:** " + d.Deprecated + "\n\n")
+	}
+	if len(d.Long) > 0 && d.Long != d.Short {
+		b.WriteString("### Synopsis\n\n" + d.Long + "\n\n")
+	}
+	if len(d.Usage) > 0 {
+		b.WriteString("```\n" + d.Usage + "\n```\n\n")
+	}
+	if len(d.Aliases) > 0 {
+		b.WriteString("### Aliases\n\n" + codeList(d.Aliases) + "\n\n")
+	}
+	if len(d.Roles) > 0 {
+		b.WriteString("### Required roles\n\n" + codeList(d.Roles) + "\n\n")
+	}
+	if len(d.Example) > 0 {
+		b.WriteString("### Examples\n\n```\n" + strings.TrimRight(d.Example, "\n") + "\n```\n\n")
+	}
+	writeFlagTable(&b, "Options", d.Flags)
+	writeFlagTable(&b, "Options inherited from parent commands", d.InheritedFlags)
+	if len(d.Commands) > 0 {
+		b.WriteString("### Commands\n\n")
+		for _, sub := range d.Commands {
+			b.WriteString(fmt.Sprintf("* [%s](%s) - %s\n", sub.Title(), link(sub), sub.Short))
+		}
+		b.WriteString("\n")
+	}
+	if parent != nil {
+		b.WriteString("### See also\n\n")
+		b.WriteString(fmt.Sprintf("* [%s](%s) - %s\n\n", parent.Title(), link(parent), parent.Short))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeFlagTable(b *strings.Builder, title string, flags []Flag) {
+	if len(flags) == 0 {
+		return
+	}
+	b.WriteString("### " + title + "\n\n")
+	b.WriteString("| Flag | Type | Default | Description |\n")
+	b.WriteString("|------|------|---------|-------------|\n")
+	for _, f := range flags {
+		name := "`--" + f.Name + "`"
+		if len(f.Shorthand) > 0 {
+			name = "`-" + f.Shorthand + "`, " + name
+		}
+		def := ""
+		if len(f.Default) > 0 {
+			def = "`" + f.Default + "`"
+		}
+		usage := f.Usage
+		if len(f.Allowed) > 0 {
+			usage += " (one of: " + strings.Join(f.Allowed, ", ") + ")"
+		}
+		if f.Required {
+			usage += " (required)"
+		}
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", name, f.Type, tableCell(def), tableCell(usage)))
+	}
+	b.WriteString("\n")
+}
+
+func codeList(values []string) string {
+	return "`" + strings.Join(values, "`, `") + "`"
+}
+
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,267 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/markel1974/goshell/shell/cli/mflag"
+)
+
+// HighlightKind is the role of a part of a command line
+type HighlightKind int
+
+const (
+	HighlightPlain HighlightKind = iota
+	// HighlightCommand is a command found in the tree, an alias or a function
+	HighlightCommand
+	// HighlightError is a command not found or a syntax error
+	HighlightError
+	HighlightFlag
+	// HighlightInvalidFlag is a flag the command does not have
+	HighlightInvalidFlag
+	HighlightString
+	// HighlightVariable is a variable or a command substitution, its value is known only when the line runs
+	HighlightVariable
+	HighlightOperator
+)
+
+// Span is a part of a command line, Start and End are the positions of its first rune and after its last one
+type Span struct {
+	Start int
+	End   int
+	Kind  HighlightKind
+}
+
+// highlightWord is a word of a command line with its role
+type highlightWord struct {
+	Token
+	kind HighlightKind
+}
+
+// Highlight splits line in spans showing the commands, the flags, the quoted strings, the variables
+// and the operators. The commands and the flags are looked up in the tree like Find does, alias
+// returns the value of an alias. The line does not need to be complete, the spans never overlap
+// and the plain text between them is not returned.
+func (c *Command) Highlight(line string, alias func(string) (string, bool)) []Span {
+	root := c.Root()
+	root.InitDefaultHelpCmd()
+
+	var out []Span
+	var stage []*highlightWord
+	var words []*highlightWord
+	target := false
+
+	endStage := func() {
+		root.highlightStage(stage, alias)
+		stage = nil
+	}
+
+	for _, t := range Lex(line) {
+		switch t.Kind {
+		case TokenRedirect, TokenInput:
+			kind := HighlightOperator
+			if t.Kind == TokenInput {
+				kind = HighlightError
+			}
+			out = append(out, Span{Start: t.Start, End: t.End, Kind: kind})
+			target = true
+		case TokenOperator:
+			out = append(out, Span{Start: t.Start, End: t.End, Kind: HighlightOperator})
+			endStage()
+			target = false
+		default:
+			w := &highlightWord{Token: t}
+			words = append(words, w)
+			if target {
+				target = false
+			} else {
+				stage = append(stage, w)
+			}
+		}
+	}
+	endStage()
+
+	for _, w := range words {
+		out = append(out, w.highlight()...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	return out
+}
+
+// highlightStage sets the kind of the words of a single command, the first is the command
+func (c *Command) highlightStage(words []*highlightWord, alias func(string) (string, bool)) {
+	if len(words) == 0 || words[0].Expanded {
+		return
+	}
+	name := words[0].Text
+	if alias != nil {
+		if value, ok := alias(name); ok {
+			words[0].kind = HighlightCommand
+			args := strings.Fields(value)
+			if len(args) == 0 {
+				return
+			}
+			kinds := c.highlightArgs(append(args, wordTexts(words[1:])...), wordsExpanded(len(args), words[1:]))
+			for i, w := range words[1:] {
+				w.kind = kinds[len(args)+i]
+			}
+			return
+		}
+	}
+	for _, u := range c.UserCommands() {
+		if u.Name == name {
+			words[0].kind = HighlightCommand
+			return
+		}
+	}
+	kinds := c.highlightArgs(wordTexts(words), wordsExpanded(0, words))
+	for i, w := range words {
+		w.kind = kinds[i]
+	}
+}
+
+// highlightArgs returns the kinds of the args of a command, it follows the tree from the root
+// with the first arg and the subcommands, the flags are checked against the command reached.
+func (c *Command) highlightArgs(args []string, expanded []bool) []HighlightKind {
+	kinds := make([]HighlightKind, len(args))
+	cmd := c
+	for _, name := range c.resolveArgs(args[:1]) {
+		if cmd = cmd.findNext(name); cmd == nil {
+			kinds[0] = HighlightError
+			return kinds
+		}
+	}
+	kinds[0] = HighlightCommand
+
+	var pending *mflag.Flag
+	positional := 0
+	dashed := false
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case pending != nil:
+			pending = nil
+		case expanded[i] || dashed:
+			positional++
+		case arg == "--":
+			kinds[i] = HighlightFlag
+			dashed = true
+		case len(arg) > 1 && strings.HasPrefix(arg, "-") && !cmd.DisableFlagParsing:
+			f, ok := cmd.checkFlagArg(arg)
+			if !ok {
+				kinds[i] = HighlightInvalidFlag
+			} else {
+				kinds[i] = HighlightFlag
+				pending = f
+			}
+		default:
+			if positional == 0 && cmd.HasSubCommands() {
+				if next := cmd.findNext(arg); next != nil {
+					cmd = next
+					kinds[i] = HighlightCommand
+					continue
+				}
+				if !cmd.Runnable() {
+					// a group runs only its subcommands
+					kinds[i] = HighlightError
+				}
+			}
+			positional++
+		}
+	}
+	return kinds
+}
+
+// checkFlagArg reports if the flags of arg exist, the flag returned is waiting for its value in the next arg.
+// A group of shorthands like -abc is checked up to the first one taking a value.
+func (c *Command) checkFlagArg(arg string) (*mflag.Flag, bool) {
+	c.mergePersistentFlags()
+	if strings.HasPrefix(arg, "--") {
+		name := arg[2:]
+		value := false
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name = name[:idx]
+			value = true
+		}
+		f := c.Flags().Lookup(name)
+		if f == nil {
+			// the help flag is added when the command runs
+			return nil, name == "help"
+		}
+		if value || f.NoOptDefVal != "" {
+			return nil, true
+		}
+		return f, true
+	}
+	shorthands := arg[1:]
+	for i, r := range shorthands {
+		f := c.Flags().ShorthandLookup(string(r))
+		if f == nil {
+			if r == 'h' && c.Flags().Lookup("help") == nil {
+				continue
+			}
+			return nil, false
+		}
+		rest := shorthands[i+len(string(r)):]
+		if f.NoOptDefVal == "" {
+			if rest == "" {
+				return f, true
+			}
+			return nil, true
+		}
+		if strings.HasPrefix(rest, "=") {
+			return nil, true
+		}
+	}
+	return nil, true
+}
+
+// highlight returns the spans of the word, the quotes and the variables inside are kept
+func (w *highlightWord) highlight() []Span {
+	var out []Span
+	add := func(start int, end int, kind HighlightKind) {
+		if start < end && kind != HighlightPlain {
+			out = append(out, Span{Start: start, End: end, Kind: kind})
+		}
+	}
+	kind := w.kind
+	pos := w.Start
+	for _, s := range w.Parts {
+		add(pos, s.Start, kind)
+		add(s.Start, s.End, s.Kind)
+		pos = s.End
+	}
+	add(pos, w.End, kind)
+	return out
+}
+
+func wordTexts(words []*highlightWord) []string {
+	out := make([]string, len(words))
+	for i, w := range words {
+		out[i] = w.Text
+	}
+	return out
+}
+
+// wordsExpanded returns which args carry variables, the first skip args are known
+func wordsExpanded(skip int, words []*highlightWord) []bool {
+	out := make([]bool, skip+len(words))
+	for i, w := range words {
+		out[skip+i] = w.Expanded
+	}
+	return out
+}
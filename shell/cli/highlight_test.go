@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+// highlightMarks returns a letter for every rune of line showing the kind of its span
+func highlightMarks(line string, spans []Span) string {
+	marks := []rune(strings.Repeat(".", len([]rune(line))))
+	for _, s := range spans {
+		for i := s.Start; i < s.End; i++ {
+			marks[i] = rune(".CEFISVO"[s.Kind])
+		}
+	}
+	return string(marks)
+}
+
+func TestCommand_Highlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{"command", "kill 12", "CCCC..."},
+		{"unknown command", "kil 12", "EEE..."},
+		{"subcommand", "stats memory heap", "CCCCC.CCCCCC....."},
+		{"unknown subcommand", "stats memroy", "CCCCC.EEEEEE"},
+		{"path", "/stats/memory -v", "CCCCCCCCCCCCC.FF"},
+		{"unknown path", "/stats/nothing", "EEEEEEEEEEEEEE"},
+		{"flags", "stats memory -u kb --format=json", "CCCCC.CCCCCC.FF....FFFFFFFFFFFFF"},
+		{"invalid flags", "stats memory -x --unknown", "CCCCC.CCCCCC.II.IIIIIIIII"},
+		{"shorthands", "stats memory -vukb -vx", "CCCCC.CCCCCC.FFFFF.III"},
+		{"help flag", "kill --help -h", "CCCC.FFFFFF.FF"},
+		{"dash", "kill -- -x", "CCCC.FF..."},
+		{"strings", `kill 'a b' "c"`, `CCCC.SSSSS.SSS`},
+		{"quoted command", `'kill' 1`, `SSSSSS..`},
+		{"variables", `kill $PID "x $y" ${z}`, `CCCC.VVVV.SSSVVS.VVVV`},
+		{"substitution", "kill $(jobs -p) `x`", "CCCC.VVVVVVVVVV.VVV"},
+		{"unknown variable command", "$CMD -x", "VVVV..."},
+		{"operators", "kill 1 | stats memory && nothing", "CCCC...O.CCCCC.CCCCCC.OO.EEEEEEE"},
+		{"redirection", "kill 1 >> out 2> err", "CCCC...OO.....OO...."},
+		{"parenthesis", "kill (1)", "CCCC.E.E"},
+		{"incomplete", `kill "a b`, `CCCC.SSSS`},
+		{"alias", "ll -v heap", "CC.FF....."},
+		{"user command", "fn -x", "CC..."},
+		{"help", "help kill", "CCCC....."},
+	}
+
+	alias := func(name string) (string, bool) {
+		if name == "ll" {
+			return "stats memory", true
+		}
+		return "", false
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := newCompletionTree()
+			root.SetUserCommandsFunc(func() []UserCommand { return []UserCommand{{Name: "fn"}} })
+			result := highlightMarks(tc.line, root.Highlight(tc.line, alias))
+			if result != tc.expected {
+				t.Errorf("expected\n%s\n%s\ngot\n%s", tc.line, tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TokenKind is the kind of a token of a command line
+type TokenKind int
+
+const (
+	// TokenWord is a word, Text has its quotes and escapes removed
+	TokenWord TokenKind = iota
+	// TokenOperator is ';', '&', '|', '&&' or '||', it ends a command
+	TokenOperator
+	// TokenRedirect is '>', '>>', 'N>' or 'N>>', the next word is its target
+	TokenRedirect
+	// TokenInput is '<', an input redirection the shell does not support, the next word is its target
+	TokenInput
+)
+
+// Token is a part of a command line, Start and End are the positions of its first rune and after its last one
+type Token struct {
+	Kind  TokenKind
+	Start int
+	End   int
+	// Text is the word without quotes and escapes, the variables and the substitutions are left out
+	Text string
+	// Parts are the quoted strings, the variables, the substitutions and the misplaced parentheses of a word
+	Parts []Span
+	// Expanded is set when the word has a variable or a substitution
+	Expanded bool
+	// Open is the position of the content of a command substitution left open at the end of the line, -1 if none
+	Open int
+}
+
+// Lex splits line in words and operators. The line does not need to be complete: the quotes and the
+// substitutions left open run to the end of the line.
+func Lex(line string) []Token {
+	rs := []rune(line)
+	var out []Token
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '<':
+			out = append(out, Token{Kind: TokenInput, Start: i, End: i + 1, Text: "<", Open: -1})
+			i++
+		case r == '>' || (r >= '0' && r <= '9' && i+1 < len(rs) && rs[i+1] == '>'):
+			size := 1
+			for size < 3 && i+size < len(rs) && rs[i+size] == '>' {
+				size++
+			}
+			out = append(out, Token{Kind: TokenRedirect, Start: i, End: i + size, Text: string(rs[i : i+size]), Open: -1})
+			i += size
+		case r == ';' || r == '&' || r == '|':
+			size := 1
+			if r != ';' && i+1 < len(rs) && rs[i+1] == r {
+				size++
+			}
+			out = append(out, Token{Kind: TokenOperator, Start: i, End: i + size, Text: string(rs[i : i+size]), Open: -1})
+			i += size
+		default:
+			t := lexWord(rs, i)
+			out = append(out, t)
+			i = t.End
+		}
+	}
+	return out
+}
+
+// lexWord reads the word starting at i
+func lexWord(rs []rune, i int) Token {
+	t := Token{Kind: TokenWord, Start: i, Open: -1}
+	var text strings.Builder
+	for i < len(rs) {
+		r := rs[i]
+		if unicode.IsSpace(r) || r == ';' || r == '&' || r == '|' || r == '<' || r == '>' {
+			break
+		}
+		switch r {
+		case '\\':
+			if i+1 < len(rs) {
+				text.WriteRune(rs[i+1])
+			}
+			i += 2
+		case '\'':
+			end := i + 1
+			for end < len(rs) && rs[end] != '\'' {
+				end++
+			}
+			text.WriteString(string(rs[i+1 : end]))
+			end = minInt(end+1, len(rs))
+			t.Parts = append(t.Parts, Span{Start: i, End: end, Kind: HighlightString})
+			i = end
+		case '"':
+			start := i
+			i++
+			for i < len(rs) && rs[i] != '"' {
+				switch {
+				case rs[i] == '\\' && i+1 < len(rs):
+					text.WriteRune(rs[i+1])
+					i += 2
+				case rs[i] == '$' || rs[i] == '`':
+					if end, open := lexExpansion(rs, i); end > i+1 {
+						if start < i {
+							t.Parts = append(t.Parts, Span{Start: start, End: i, Kind: HighlightString})
+						}
+						t.Parts = append(t.Parts, Span{Start: i, End: end, Kind: HighlightVariable})
+						t.Expanded, t.Open = true, open
+						start, i = end, end
+						continue
+					}
+					text.WriteRune(rs[i])
+					i++
+				default:
+					text.WriteRune(rs[i])
+					i++
+				}
+			}
+			i = minInt(i+1, len(rs))
+			if start < i {
+				t.Parts = append(t.Parts, Span{Start: start, End: i, Kind: HighlightString})
+			}
+		case '$', '`':
+			if end, open := lexExpansion(rs, i); end > i+1 {
+				t.Parts = append(t.Parts, Span{Start: i, End: end, Kind: HighlightVariable})
+				t.Expanded, t.Open = true, open
+				i = end
+				continue
+			}
+			text.WriteRune(r)
+			i++
+		case '(', ')':
+			// a parenthesis is an error outside a command substitution
+			t.Parts = append(t.Parts, Span{Start: i, End: i + 1, Kind: HighlightError})
+			text.WriteRune(r)
+			i++
+		default:
+			text.WriteRune(r)
+			i++
+		}
+	}
+	t.End = minInt(i, len(rs))
+	t.Text = text.String()
+	return t
+}
+
+// lexExpansion returns the end of the variable or the command substitution starting at i, i+1 if there
+// is none, and the position of the content of a command substitution left open, -1 if it is closed
+func lexExpansion(rs []rune, i int) (int, int) {
+	if rs[i] == '`' {
+		end := i + 1
+		for end < len(rs) && rs[end] != '`' {
+			end++
+		}
+		if end == len(rs) {
+			return end, i + 1
+		}
+		return end + 1, -1
+	}
+	if i+1 >= len(rs) {
+		return i + 1, -1
+	}
+	switch rs[i+1] {
+	case '(':
+		depth := 0
+		for end := i + 1; end < len(rs); end++ {
+			switch rs[end] {
+			case '\\':
+				end++
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return end + 1, -1
+				}
+			}
+		}
+		return len(rs), i + 2
+	case 0x7b:
+		if end := scanBrace(rs, i+2); end >= 0 {
+			return end + 1, -1
+		}
+		return len(rs), -1
+	}
+	return scanEnvName(rs, i+1), -1
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
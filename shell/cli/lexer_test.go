@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// describeTokens returns a line for every token: its kind, its position, its text, then '$' when it is
+// expanded and the position of the substitution left open
+func describeTokens(tokens []Token) string {
+	var out []string
+	for _, t := range tokens {
+		s := fmt.Sprintf("%s %d-%d %q", []string{"word", "op", "redirect", "input"}[t.Kind], t.Start, t.End, t.Text)
+		if t.Expanded {
+			s += " $"
+		}
+		if t.Open >= 0 {
+			s += fmt.Sprintf(" open %d", t.Open)
+		}
+		out = append(out, s)
+	}
+	return strings.Join(out, ", ")
+}
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"blanks", " \t ", ""},
+		{"words", "kill  12", `word 0-4 "kill", word 6-8 "12"`},
+		{"quotes", `echo 'a b' "c d"`, `word 0-4 "echo", word 5-10 "a b", word 11-16 "c d"`},
+		{"quotes in a word", `a'b c'"d"e`, `word 0-10 "ab cde"`},
+		{"escapes", `a\ b "c\"d" 'e\'`, `word 0-4 "a b", word 5-11 "c\"d", word 12-16 "e\\"`},
+		{"trailing escape", `a\`, `word 0-2 "a"`},
+		{"open quote", `echo "a b`, `word 0-4 "echo", word 5-9 "a b"`},
+		{"open single quote", `echo 'a b`, `word 0-4 "echo", word 5-9 "a b"`},
+		{"operators", "a|b||c&d&&e;f&", `word 0-1 "a", op 1-2 "|", word 2-3 "b", op 3-5 "||", word 5-6 "c", op 6-7 "&", ` +
+			`word 7-8 "d", op 8-10 "&&", word 10-11 "e", op 11-12 ";", word 12-13 "f", op 13-14 "&"`},
+		{"quoted operators", `echo '|' "&&" \;`, `word 0-4 "echo", word 5-8 "|", word 9-13 "&&", word 14-16 ";"`},
+		{"redirections", "ps >> out 2> err 2>>e <in", `word 0-2 "ps", redirect 3-5 ">>", word 6-9 "out", redirect 10-12 "2>", ` +
+			`word 13-16 "err", redirect 17-20 "2>>", word 20-21 "e", input 22-23 "<", word 23-25 "in"`},
+		{"digit in a word", "echo a2>x 2", `word 0-4 "echo", word 5-7 "a2", redirect 7-8 ">", word 8-9 "x", word 10-11 "2"`},
+		{"variable", "echo $HOME/x", `word 0-4 "echo", word 5-12 "/x" $`},
+		{"braces", "echo ${A:-b}c", `word 0-4 "echo", word 5-13 "c" $`},
+		{"open braces", "echo ${HO", `word 0-4 "echo", word 5-9 "" $`},
+		{"lone dollar", "echo $ a$", `word 0-4 "echo", word 5-6 "$", word 7-9 "a$"`},
+		{"special parameter", "echo $?", `word 0-4 "echo", word 5-7 "" $`},
+		{"substitution", "echo $(ls -l | x) `ps`", `word 0-4 "echo", word 5-17 "" $, word 18-22 "" $`},
+		{"nested substitution", "echo $(a $(b) c)", `word 0-4 "echo", word 5-16 "" $`},
+		{"open substitution", "echo $(ls -", `word 0-4 "echo", word 5-11 "" $ open 7`},
+		{"open nested substitution", "echo $(a $(b", `word 0-4 "echo", word 5-12 "" $ open 7`},
+		{"open backtick", "echo x`ps", `word 0-4 "echo", word 5-9 "x" $ open 7`},
+		{"open substitution in quotes", `echo "a $(ls`, `word 0-4 "echo", word 5-12 "a " $ open 10`},
+		{"variable in quotes", `echo "$x"`, `word 0-4 "echo", word 5-9 "" $`},
+		{"single quoted variable", `echo '$x'`, `word 0-4 "echo", word 5-9 "$x"`},
+		{"parenthesis", "kill (1)", `word 0-4 "kill", word 5-8 "(1)"`},
+		{"multibyte", "echo è|x", `word 0-4 "echo", word 5-6 "è", op 6-7 "|", word 7-8 "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := describeTokens(Lex(tt.line)); result != tt.expected {
+				t.Errorf("\ngot  %s\nwant %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLex_Parts(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{"plain", "abc", "..."},
+		{"single quotes", "a'b c'd", ".SSSSS."},
+		{"double quotes", `"a b"`, "SSSSS"},
+		{"variable", "a$b/c", ".VV.."},
+		{"variables in quotes", `x"a $y b"'c'(`, ".SSSVVSSSSSSE"},
+		{"substitution in quotes", `"$(a "b")"`, "SVVVVVVVVS"},
+		{"backtick", "`a b`c", "VVVVV."},
+		{"open quote", `"a $(b`, "SSSVVV"},
+		{"parentheses", "(a)", "E.E"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := Lex(tt.line)
+			if len(tokens) != 1 {
+				t.Fatalf("expected a word, got %s", describeTokens(tokens))
+			}
+			if marks := highlightMarks(tt.line, tokens[0].Parts); marks != tt.expected {
+				t.Errorf("got %s, want %s", marks, tt.expected)
+			}
+		})
+	}
+}
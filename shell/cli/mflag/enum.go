@@ -0,0 +1,119 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package mflag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// -- enum Value
+type enumValue struct {
+	value   *string
+	def     string
+	allowed []string
+}
+
+func newEnumValue(val string, allowed []string, p *string) *enumValue {
+	*p = val
+	return &enumValue{value: p, def: val, allowed: allowed}
+}
+
+// Set accepts only one of the allowed values.
+func (e *enumValue) Set(s string) error {
+	for _, a := range e.allowed {
+		if a == s {
+			*e.value = s
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(e.allowed, ", "))
+}
+
+func (e *enumValue) Type() string {
+	return "enum"
+}
+
+func (e *enumValue) String() string { return *e.value }
+
+func (e *enumValue) reset() {
+	*e.value = e.def
+}
+
+// Allowed returns the values accepted by the flag.
+func (e *enumValue) Allowed() []string {
+	return e.allowed
+}
+
+func enumConv(sval string) (interface{}, error) {
+	return sval, nil
+}
+
+// GetEnum return the string value of an enum flag with the given name
+func (f *FlagSet) GetEnum(name string) (string, error) {
+	val, err := f.getFlagType(name, "enum", enumConv)
+	if err != nil {
+		return "", err
+	}
+	return val.(string), nil
+}
+
+// EnumVar defines an enum flag with specified name, default value, allowed values and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+// Setting a value outside of allowed fails the parsing.
+func (f *FlagSet) EnumVar(p *string, name string, value string, allowed []string, usage string) {
+	f.VarP(newEnumValue(value, allowed, p), name, "", usage)
+}
+
+// EnumVarP is like EnumVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) EnumVarP(p *string, name, shorthand string, value string, allowed []string, usage string) {
+	f.VarP(newEnumValue(value, allowed, p), name, shorthand, usage)
+}
+
+// EnumVar defines an enum flag with specified name, default value, allowed values and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func EnumVar(p *string, name string, value string, allowed []string, usage string) {
+	CommandLine.VarP(newEnumValue(value, allowed, p), name, "", usage)
+}
+
+// EnumVarP is like EnumVar, but accepts a shorthand letter that can be used after a single dash.
+func EnumVarP(p *string, name, shorthand string, value string, allowed []string, usage string) {
+	CommandLine.VarP(newEnumValue(value, allowed, p), name, shorthand, usage)
+}
+
+// Enum defines an enum flag with specified name, default value, allowed values and usage string.
+// The return value is the address of a string variable that stores the value of the flag.
+func (f *FlagSet) Enum(name string, value string, allowed []string, usage string) *string {
+	p := new(string)
+	f.EnumVarP(p, name, "", value, allowed, usage)
+	return p
+}
+
+// EnumP is like Enum, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) EnumP(name, shorthand string, value string, allowed []string, usage string) *string {
+	p := new(string)
+	f.EnumVarP(p, name, shorthand, value, allowed, usage)
+	return p
+}
+
+// Enum defines an enum flag with specified name, default value, allowed values and usage string.
+// The return value is the address of a string variable that stores the value of the flag.
+func Enum(name string, value string, allowed []string, usage string) *string {
+	return CommandLine.EnumP(name, "", value, allowed, usage)
+}
+
+// EnumP is like Enum, but accepts a shorthand letter that can be used after a single dash.
+func EnumP(name, shorthand string, value string, allowed []string, usage string) *string {
+	return CommandLine.EnumP(name, shorthand, value, allowed, usage)
+}
@@ -419,6 +419,34 @@ func (f *FlagSet) SetAnnotation(name, key string, values []string) error {
 	return nil
 }
 
+// resettable is implemented by values that keep more state than their
+// default text, like slices and maps appending after the first Set.
+type resettable interface {
+	reset()
+}
+
+// ResetFlags restores every flag changed by a previous Parse to its default value,
+// so the same FlagSet can be parsed again for a new invocation.
+// A flag shared with another FlagSet, like a persistent one, is reset when the other set changed it too.
+func (f *FlagSet) ResetFlags() {
+	for name, flag := range f.formal {
+		if !flag.Changed && f.actual[name] == nil {
+			continue
+		}
+		if r, ok := flag.Value.(resettable); ok {
+			r.reset()
+		} else {
+			_ = flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	f.actual = nil
+	f.orderedActual = nil
+	f.sortedActual = nil
+	f.args = nil
+	f.argsLenAtDash = -1
+}
+
 // Changed returns true if the flag was explicitly set during Parse() and false
 // otherwise
 func (f *FlagSet) Changed(name string) bool {
@@ -447,19 +475,17 @@ func (f *Flag) defaultIsZeroValue() bool {
 	switch f.Value.(type) {
 	case boolFlag:
 		return f.DefValue == "false"
-		/*
-			case *durationValue:
-				// Beginning in Go 1.7, duration zero values are "0s"
-				return f.DefValue == "0" || f.DefValue == "0s"
-			case *intValue, *int8Value, *int32Value, *int64Value, *uintValue, *uint8Value, *uint16Value, *uint32Value, *uint64Value, *countValue, *float32Value, *float64Value:
-				return f.DefValue == "0"
-			case *stringValue:
-				return f.DefValue == ""
-			case *ipValue, *ipMaskValue, *ipNetValue:
-				return f.DefValue == "<nil>"
-			case *intSliceValue, *stringSliceValue, *stringArrayValue:
-				return f.DefValue == "[]"
-		*/
+	case *durationValue:
+		// Beginning in Go 1.7, duration zero values are "0s"
+		return f.DefValue == "0" || f.DefValue == "0s"
+	case *intValue, *int8Value, *int16Value, *int32Value, *int64Value, *uintValue, *uint8Value, *uint16Value, *uint32Value, *uint64Value, *countValue, *float64Value:
+		return f.DefValue == "0"
+	case *stringValue, *enumValue:
+		return f.DefValue == ""
+	case *ipValue, *ipNetValue:
+		return f.DefValue == "<nil>"
+	case *stringSliceValue, *stringToStringValue:
+		return f.DefValue == "[]"
 	default:
 		switch f.Value.String() {
 		case "false":
@@ -508,6 +534,12 @@ func UnquoteUsage(flag *Flag) (name string, usage string) {
 		name = "uint"
 	case "stringSlice":
 		name = "strings"
+	case "stringToString":
+		name = "key=value"
+	case "enum":
+		if e, ok := flag.Value.(*enumValue); ok {
+			name = strings.Join(e.Allowed(), "|")
+		}
 	case "intSlice":
 		name = "ints"
 	case "uintSlice":
@@ -0,0 +1,65 @@
+package mflag
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlagSet_TypedFlags(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	name := f.StringP("name", "n", "none", "name")
+	count := f.Int("count", 1, "count")
+	ratio := f.Float64("ratio", 0.5, "ratio")
+	wait := f.Duration("wait", time.Second, "wait")
+	tags := f.StringSlice("tag", []string{}, "tags")
+	labels := f.StringToString("label", map[string]string{}, "labels")
+	ip := f.IP("ip", nil, "ip")
+	verbose := f.CountP("verbose", "v", "verbosity")
+	format := f.Enum("format", "table", []string{"table", "json"}, "format")
+
+	args := []string{"-n", "x", "--count=3", "--ratio", "1.5", "--wait", "2m", "--tag", "a,b", "--tag", "c",
+		"--label", "k=v", "--ip", "10.0.0.1", "-vvv", "--format", "json", "rest"}
+	if err := f.Parse(args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if *name != "x" || *count != 3 || *ratio != 1.5 || *wait != 2*time.Minute || *verbose != 3 || *format != "json" {
+		t.Errorf("unexpected values: %s %d %f %s %d %s", *name, *count, *ratio, *wait, *verbose, *format)
+	}
+	if strings.Join(*tags, ",") != "a,b,c" {
+		t.Errorf("expected tags a,b,c, got: %v", *tags)
+	}
+	if (*labels)["k"] != "v" {
+		t.Errorf("expected label k=v, got: %v", *labels)
+	}
+	if ip.String() != "10.0.0.1" {
+		t.Errorf("expected ip 10.0.0.1, got: %s", ip.String())
+	}
+	if got, _ := f.GetStringSlice("tag"); len(got) != 3 {
+		t.Errorf("expected 3 tags from accessor, got: %v", got)
+	}
+	if got, _ := f.GetDuration("wait"); got != 2*time.Minute {
+		t.Errorf("expected wait 2m from accessor, got: %s", got)
+	}
+	if len(f.Args()) != 1 || f.Args()[0] != "rest" {
+		t.Errorf("expected remaining arg rest, got: %v", f.Args())
+	}
+
+	f.ResetFlags()
+	if *name != "none" || *count != 1 || *verbose != 0 || *format != "table" || len(*tags) != 0 || ip.String() != "<nil>" {
+		t.Errorf("flags not reset: %s %d %d %s %v %s", *name, *count, *verbose, *format, *tags, ip.String())
+	}
+}
+
+func TestFlagSet_EnumValidation(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Enum("format", "table", []string{"table", "json"}, "format")
+
+	if err := f.Parse([]string{"--format", "xml"}); err == nil {
+		t.Fatalf("expected error for value outside the allowed list")
+	}
+	if usage := f.FlagUsages(); !strings.Contains(usage, "--format table|json") {
+		t.Errorf("expected allowed values in usage, got: %s", usage)
+	}
+}
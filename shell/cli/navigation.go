@@ -0,0 +1,150 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PathSeparator separates the names of the commands in a path of the tree, like /stats/cpu
+const PathSeparator = "/"
+
+// SetBase sets the position in the tree used to resolve the relative commands, it is kept by the root.
+func (c *Command) SetBase(base *Command) {
+	c.Root().base = base
+}
+
+// Base returns the position in the tree used to resolve the relative commands, the root by default.
+func (c *Command) Base() *Command {
+	root := c.Root()
+	if root.base == nil {
+		return root
+	}
+	return root.base
+}
+
+// Path returns the absolute path of the command in its tree.
+func (c *Command) Path() string {
+	return PathSeparator + strings.Join(c.pathNames(), PathSeparator)
+}
+
+func (c *Command) pathNames() []string {
+	var names []string
+	for cmd := c; cmd.HasParent(); cmd = cmd.Parent() {
+		names = append([]string{cmd.Name()}, names...)
+	}
+	return names
+}
+
+// Resolve returns the command at path, absolute or relative to c. ".." is the parent and "." the command itself.
+func (c *Command) Resolve(path string) (*Command, error) {
+	cmd := c
+	if strings.HasPrefix(path, PathSeparator) {
+		cmd = c.Root()
+	}
+	for _, name := range strings.Split(path, PathSeparator) {
+		switch name {
+		case "", ".":
+		case "..":
+			if cmd.HasParent() {
+				cmd = cmd.Parent()
+			}
+		default:
+			next := cmd.child(name)
+			if next == nil {
+				return nil, fmt.Errorf("%s: no such command", path)
+			}
+			cmd = next
+		}
+	}
+	return cmd, nil
+}
+
+// Lookup returns the command named by args like in a command line, relative to the base or from the root.
+// Every arg can be a path.
+func (c *Command) Lookup(args []string) (*Command, error) {
+	cmd := c.Root()
+	for _, arg := range c.resolveArgs(args) {
+		next, err := cmd.Resolve(arg)
+		if err != nil {
+			return nil, err
+		}
+		cmd = next
+	}
+	return cmd, nil
+}
+
+// child returns the subcommand with the given name or alias.
+func (c *Command) child(name string) *Command {
+	for _, cmd := range c.commands {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// resolveArgs makes args relative to the root: an absolute path is split in its names,
+// a command found under the base is prefixed with the path of the base.
+// The other commands are looked up from the root.
+func (c *Command) resolveArgs(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	if strings.HasPrefix(args[0], PathSeparator) {
+		var names []string
+		for _, name := range strings.Split(args[0], PathSeparator) {
+			if len(name) > 0 {
+				names = append(names, name)
+			}
+		}
+		return append(names, args[1:]...)
+	}
+	base := c.Base()
+	if !base.HasParent() || base.child(args[0]) == nil {
+		return args
+	}
+	return append(base.pathNames(), args...)
+}
+
+// CompletePath completes a path of the tree, absolute or relative to the base.
+// The commands having subcommands end with the separator, groupsOnly discards the others.
+func (c *Command) CompletePath(toComplete string, groupsOnly bool) []Completion {
+	dir, name := "", toComplete
+	if idx := strings.LastIndex(toComplete, PathSeparator); idx >= 0 {
+		dir, name = toComplete[:idx+1], toComplete[idx+1:]
+	}
+	parent, err := c.Base().Resolve(dir)
+	if err != nil {
+		return nil
+	}
+	var out []Completion
+	for _, cmd := range parent.Commands() {
+		if !cmd.IsAvailableCommand() || !strings.HasPrefix(cmd.Name(), name) {
+			continue
+		}
+		group := cmd.HasAvailableSubCommands()
+		if groupsOnly && !group {
+			continue
+		}
+		value := dir + cmd.Name()
+		if group {
+			value += PathSeparator
+		}
+		out = append(out, Completion{Value: value, Description: cmd.Short})
+	}
+	return out
+}
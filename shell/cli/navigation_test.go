@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommand_Resolve(t *testing.T) {
+	root := newCompletionTree()
+	stats, _ := root.Resolve("stats")
+
+	tests := []struct {
+		from     *Command
+		path     string
+		expected string
+		err      bool
+	}{
+		{root, "stats", "/stats", false},
+		{root, "/stats/memory", "/stats/memory", false},
+		{stats, "memory", "/stats/memory", false},
+		{stats, "..", "/", false},
+		{stats, "../kill", "/kill", false},
+		{stats, "/", "/", false},
+		{stats, ".", "/stats", false},
+		{root, "..", "/", false},
+		{stats, "kill", "", true},
+	}
+	for _, tc := range tests {
+		cmd, err := tc.from.Resolve(tc.path)
+		if (err != nil) != tc.err {
+			t.Errorf("Resolve(%q): unexpected error %v", tc.path, err)
+			continue
+		}
+		if err == nil && cmd.Path() != tc.expected {
+			t.Errorf("Resolve(%q): expected %s, got %s", tc.path, tc.expected, cmd.Path())
+		}
+	}
+}
+
+func TestCommand_ResolveArgs(t *testing.T) {
+	root := newCompletionTree()
+	stats, _ := root.Resolve("/stats")
+	root.SetBase(stats)
+
+	tests := map[string][]string{
+		"memory -v":     {"stats", "memory", "-v"},
+		"kill 1":        {"kill", "1"},
+		"/stats/memory": {"stats", "memory"},
+		"/kill 2":       {"kill", "2"},
+		"--help":        {"--help"},
+	}
+	for line, expected := range tests {
+		result := root.resolveArgs(strings.Fields(line))
+		if len(result) != len(expected) {
+			t.Errorf("resolveArgs(%q): expected %v, got %v", line, expected, result)
+			continue
+		}
+		for i := range result {
+			if result[i] != expected[i] {
+				t.Errorf("resolveArgs(%q): expected %v, got %v", line, expected, result)
+				break
+			}
+		}
+	}
+
+	result := root.Complete(nil, "m")
+	if len(result) != 1 || result[0].Value != "memory" {
+		t.Errorf("expected the commands of the base, got %v", result)
+	}
+}
+
+func TestCommand_Lookup(t *testing.T) {
+	root := newCompletionTree()
+	stats, _ := root.Resolve("/stats")
+	root.SetBase(stats)
+
+	tests := map[string]string{
+		"memory":        "/stats/memory",
+		"stats memory":  "/stats/memory",
+		"/stats/memory": "/stats/memory",
+		"kill":          "/kill",
+		"":              "/",
+	}
+	for line, expected := range tests {
+		cmd, err := root.Lookup(strings.Fields(line))
+		if err != nil || cmd.Path() != expected {
+			t.Errorf("Lookup(%q): expected %s, got %v %v", line, expected, cmd, err)
+		}
+	}
+	if _, err := root.Lookup([]string{"memory", "nothing"}); err == nil {
+		t.Error("expected an error for an unknown command")
+	}
+}
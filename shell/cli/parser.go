@@ -14,12 +14,84 @@ func getEnv(_ string) string {
 	return ""
 }
 
+func isEnvNameRune(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || unicode.IsDigit(r)
+}
+
+// isSpecialParam reports if r is a single character parameter like $?, $# or $@
+func isSpecialParam(r rune) bool {
+	return r == '?' || r == '#' || r == '@'
+}
+
+// scanEnvName returns the end of the variable name starting at i.
+func scanEnvName(rs []rune, i int) int {
+	if i < len(rs) && isSpecialParam(rs[i]) {
+		return i + 1
+	}
+	for i < len(rs) && isEnvNameRune(rs[i]) {
+		i++
+	}
+	return i
+}
+
+// replaceEnv expands $NAME, ${NAME} and ${NAME:-default} references using env.
+// An unset variable expands to an empty string.
 func replaceEnv(env func(string) string, s string) string {
+	return lookupEnv(definedEnv(env), s)
+}
+
+// definedEnv adapts env to lookupEnv, an empty variable is undefined.
+func definedEnv(env func(string) string) func(string) (string, bool) {
 	if env == nil {
 		env = getEnv
 	}
+	return func(name string) (string, bool) {
+		value := env(name)
+		return value, len(value) > 0
+	}
+}
 
+// lookupEnv is like replaceEnv, env tells an empty variable from an undefined one.
+func lookupEnv(env func(string) (string, bool), s string) string {
 	var buf bytes.Buffer
+	if !walkEnv(env, s, func(r rune) { buf.WriteRune(r) }, func(value string) { buf.WriteString(value) }) {
+		return s
+	}
+	return buf.String()
+}
+
+// splitEnv is like lookupEnv, the values of the references are split in words on blanks.
+// The blanks of s are part of the words, the line has been split on the blanks already.
+func splitEnv(env func(string) (string, bool), s string) []string {
+	var words []string
+	var word []rune
+	started := false
+	literal := func(r rune) {
+		word = append(word, r)
+		started = true
+	}
+	value := func(value string) {
+		for _, r := range value {
+			if !unicode.IsSpace(r) {
+				literal(r)
+			} else if started {
+				words = append(words, string(word))
+				word, started = nil, false
+			}
+		}
+	}
+	if !walkEnv(env, s, literal, value) {
+		return []string{s}
+	}
+	if started {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// walkEnv reads s, literal receives its characters and value the values of its references.
+// A character escaped with '\' is literal. It returns false when a ${ reference is not closed.
+func walkEnv(env func(string) (string, bool), s string, literal func(rune), value func(string)) bool {
 	rs := []rune(s)
 	for i := 0; i < len(rs); i++ {
 		r := rs[i]
@@ -28,63 +100,74 @@ func replaceEnv(env func(string) string, s string) string {
 			if i == len(rs) {
 				break
 			}
-			buf.WriteRune(rs[i])
+			literal(rs[i])
 			continue
 		} else if r == '$' {
 			i++
 			if i == len(rs) {
-				buf.WriteRune(r)
+				literal(r)
 				break
 			}
 			if rs[i] == 0x7b {
 				i++
-				p := i
-				for ; i < len(rs); i++ {
-					r = rs[i]
-					if r == '\\' {
-						i++
-						if i == len(rs) {
-							return s
-						}
-						continue
+				end := scanEnvName(rs, i)
+				name := string(rs[i:end])
+				if end+1 < len(rs) && rs[end] == ':' && rs[end+1] == '-' {
+					close := scanBrace(rs, end+2)
+					if close < 0 || end == i {
+						return false
 					}
-					if r == 0x7d || (!unicode.IsLetter(r) && r != '_' && !unicode.IsDigit(r)) {
-						break
+					if v, _ := env(name); len(v) > 0 {
+						value(v)
+					} else {
+						value(lookupEnv(env, string(rs[end+2:close])))
 					}
+					i = close
+					continue
 				}
-				if r != 0x7d {
-					return s
+				if end == len(rs) || rs[end] != 0x7d {
+					return false
 				}
-				if i > p {
-					buf.WriteString(env(s[p:i]))
+				if end > i {
+					v, _ := env(name)
+					value(v)
 				}
+				i = end
 			} else {
-				p := i
-				for ; i < len(rs); i++ {
-					r := rs[i]
-					if r == '\\' {
-						i++
-						if i == len(rs) {
-							return s
-						}
-						continue
-					}
-					if !unicode.IsLetter(r) && r != '_' && !unicode.IsDigit(r) {
-						break
-					}
-				}
-				if i > p {
-					buf.WriteString(env(s[p:i]))
-					i--
+				end := scanEnvName(rs, i)
+				if end > i {
+					v, _ := env(string(rs[i:end]))
+					value(v)
 				} else {
-					buf.WriteString(s[p:])
+					literal('$')
 				}
+				i = end - 1
 			}
 		} else {
-			buf.WriteRune(r)
+			literal(r)
 		}
 	}
-	return buf.String()
+	return true
+}
+
+// scanBrace returns the position of the '}' closing the reference whose
+// default starts at i, nested references included. It returns -1 if it is missing.
+func scanBrace(rs []rune, i int) int {
+	depth := 0
+	for ; i < len(rs); i++ {
+		switch rs[i] {
+		case '\\':
+			i++
+		case 0x7b:
+			depth++
+		case 0x7d:
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
 }
 
 type Parser struct {
@@ -93,6 +176,10 @@ type Parser struct {
 	Position      int
 	Dir           string
 	GetEnv        func(string) string
+	// LookupEnv replaces GetEnv when set, it tells an empty variable from an undefined one
+	LookupEnv func(string) (string, bool)
+	// Substitute runs the command of a $(...) or backtick substitution and returns its output
+	Substitute func(string) (string, error)
 }
 
 func NewParser() *Parser {
@@ -104,6 +191,26 @@ func NewParser() *Parser {
 	}
 }
 
+// IncompleteError is the error of a line ending before the end of the command: inside quotes or
+// a command substitution, after a backslash or after an operator. The command continues on the next line.
+type IncompleteError struct {
+	What string
+	// Quoted is set when the line ends inside quotes, the new line is part of the quoted text
+	Quoted bool
+	// Escaped is set when the line ends with a backslash, the backslash and the new line are dropped
+	Escaped bool
+}
+
+func (e *IncompleteError) Error() string {
+	return e.What
+}
+
+// IsIncomplete reports if err is an IncompleteError
+func IsIncomplete(err error) bool {
+	var e *IncompleteError
+	return errors.As(err, &e)
+}
+
 type argType int
 
 const (
@@ -115,24 +222,34 @@ const (
 func (p *Parser) Parse(line string) ([]string, error) {
 	var args []string
 	buf := ""
-	var escaped, doubleQuoted, singleQuoted, backQuote, dollarQuote bool
-	backtick := ""
+	var escaped, doubleQuoted, singleQuoted, backQuote, dollarQuote, substituted bool
+	// quoted tells an argument with a double quoted part, its variables are not split
+	quoted := false
+	subStart := 0
+	parens := 0
 
 	pos := -1
 	got := argNo
 
 	i := -1
 loop:
-	for _, r := range line {
-		i++
+	for idx, r := range line {
+		i = idx
 		if escaped {
+			if backQuote || dollarQuote {
+				// the command substituted is parsed again when it runs
+				buf += "\\" + string(r)
+				escaped = false
+				continue
+			}
 			if r == 't' {
 				r = '\t'
 			}
 			if r == 'n' {
 				r = '\n'
 			}
-			buf += string(r)
+			// the escaped '$' and '\\' are kept until the variables expansion
+			buf += p.quote(r)
 			escaped = false
 			got = argSingle
 			continue
@@ -140,7 +257,7 @@ loop:
 
 		if r == '\\' {
 			if singleQuoted {
-				buf += string(r)
+				buf += p.quote(r)
 			} else {
 				escaped = true
 			}
@@ -150,24 +267,12 @@ loop:
 		if unicode.IsSpace(r) {
 			if singleQuoted || doubleQuoted || backQuote || dollarQuote {
 				buf += string(r)
-				backtick += string(r)
 			} else if got != argNo {
-				if p.ParseEnv {
-					if got == argSingle {
-						parser := &Parser{ParseEnv: false, ParseBacktick: false, Position: 0, Dir: p.Dir}
-						strs, err := parser.Parse(replaceEnv(p.GetEnv, buf))
-						if err != nil {
-							return nil, err
-						}
-						args = append(args, strs...)
-					} else {
-						args = append(args, replaceEnv(p.GetEnv, buf))
-					}
-				} else {
-					args = append(args, buf)
-				}
+				args = append(args, p.expand(buf, got, substituted, quoted)...)
 				buf = ""
 				got = argNo
+				substituted = false
+				quoted = false
 			}
 			continue
 		}
@@ -177,36 +282,54 @@ loop:
 			if !singleQuoted && !doubleQuoted && !dollarQuote {
 				if p.ParseBacktick {
 					if backQuote {
-						buf = buf[:len(buf)-len(backtick)]
+						out, err := p.substitute(buf[subStart:])
+						if err != nil {
+							return nil, err
+						}
+						buf = buf[:subStart] + out
+						substituted = true
+					} else {
+						subStart = len(buf)
 					}
-					backtick = ""
 					backQuote = !backQuote
+					got = argSingle
 					continue
 				}
-				backtick = ""
 				backQuote = !backQuote
 			}
 		case ')':
-			if !singleQuoted && !doubleQuoted && !backQuote {
-				if p.ParseBacktick {
+			if !singleQuoted && !backQuote && (dollarQuote || !doubleQuoted) {
+				if dollarQuote && parens > 0 {
+					parens--
+				} else if p.ParseBacktick {
 					if dollarQuote {
-						buf = buf[:len(buf)-len(backtick)-2]
+						out, err := p.substitute(buf[subStart:])
+						if err != nil {
+							return nil, err
+						}
+						buf = buf[:subStart-2] + out
+						substituted = true
 					}
-					backtick = ""
 					dollarQuote = !dollarQuote
+					got = argSingle
 					continue
+				} else {
+					dollarQuote = !dollarQuote
 				}
-				backtick = ""
-				dollarQuote = !dollarQuote
 			}
 		case '(':
-			if !singleQuoted && !doubleQuoted && !backQuote {
-				if !dollarQuote && strings.HasSuffix(buf, "$") {
+			// a command substitution in double quotes is a single argument
+			if !singleQuoted && !backQuote {
+				if dollarQuote {
+					parens++
+				} else if endsWithDollar(buf) {
 					dollarQuote = true
 					buf += "("
+					subStart = len(buf)
+					got = argSingle
 					continue
-				} else {
-					return nil, errors.New("invalid command line string")
+				} else if !doubleQuoted {
+					return nil, errors.New("unexpected '(', a command substitution is written $(command)")
 				}
 			}
 		case '"':
@@ -215,6 +338,7 @@ loop:
 					got = argQuoted
 				}
 				doubleQuoted = !doubleQuoted
+				quoted = true
 				continue
 			}
 		case '\'':
@@ -227,7 +351,8 @@ loop:
 			}
 		case ';', '&', '|', '<', '>':
 			if !(escaped || singleQuoted || doubleQuoted || backQuote || dollarQuote) {
-				if r == '>' && len(buf) > 0 {
+				// a single digit right before '>' is the file descriptor of the redirection
+				if r == '>' && len(buf) == 1 && got == argSingle {
 					if c := buf[0]; '0' <= c && c <= '9' {
 						i -= 1
 						got = argNo
@@ -239,31 +364,32 @@ loop:
 		}
 
 		got = argSingle
-		buf += string(r)
-		if backQuote || dollarQuote {
-			backtick += string(r)
+		if singleQuoted {
+			buf += p.quote(r)
+		} else {
+			buf += string(r)
 		}
 	}
 
 	if got != argNo {
-		if p.ParseEnv {
-			if got == argSingle {
-				parser := &Parser{ParseEnv: false, ParseBacktick: false, Position: 0, Dir: p.Dir}
-				strs, err := parser.Parse(replaceEnv(p.GetEnv, buf))
-				if err != nil {
-					return nil, err
-				}
-				args = append(args, strs...)
-			} else {
-				args = append(args, replaceEnv(p.GetEnv, buf))
-			}
-		} else {
-			args = append(args, buf)
-		}
+		args = append(args, p.expand(buf, got, substituted, quoted)...)
 	}
 
-	if escaped || singleQuoted || doubleQuoted || backQuote || dollarQuote {
-		return nil, errors.New("invalid command line string")
+	if singleQuoted || doubleQuoted || backQuote || dollarQuote || escaped {
+		e := &IncompleteError{Escaped: escaped, Quoted: singleQuoted || doubleQuoted || backQuote || dollarQuote}
+		switch {
+		case singleQuoted:
+			e.What = "unterminated single quote"
+		case doubleQuoted:
+			e.What = "unterminated double quote"
+		case backQuote:
+			e.What = "unterminated backquote"
+		case dollarQuote:
+			e.What = "unterminated command substitution"
+		default:
+			e.What = "unexpected end of line after '\\'"
+		}
+		return nil, e
 	}
 
 	p.Position = pos
@@ -271,6 +397,76 @@ loop:
 	return args, nil
 }
 
+func (p *Parser) replaceEnv(s string) string {
+	return lookupEnv(p.lookup(), s)
+}
+
+// lookup returns LookupEnv, or GetEnv where an empty variable is undefined.
+func (p *Parser) lookup() func(string) (string, bool) {
+	if p.LookupEnv != nil {
+		return p.LookupEnv
+	}
+	return definedEnv(p.GetEnv)
+}
+
+// endsWithDollar reports if buf ends with a '$' that is not escaped.
+func endsWithDollar(buf string) bool {
+	if !strings.HasSuffix(buf, "$") {
+		return false
+	}
+	n := 0
+	for i := len(buf) - 2; i >= 0 && buf[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 0
+}
+
+// quote protects the quoted and escaped characters from the variables expansion
+func (p *Parser) quote(r rune) string {
+	if p.ParseEnv && (r == '$' || r == '\\') {
+		return "\\" + string(r)
+	}
+	return string(r)
+}
+
+// substitute runs a command substitution. Without a Substitute function the
+// substitution is dropped.
+func (p *Parser) substitute(line string) (string, error) {
+	if p.Substitute == nil {
+		return "", nil
+	}
+	out, err := p.Substitute(line)
+	if err != nil {
+		return "", err
+	}
+	out = strings.TrimRight(out, "\n")
+	if p.ParseEnv {
+		// the output is not expanded again
+		out = strings.NewReplacer("\\", "\\\\", "$", "\\$").Replace(out)
+	}
+	return out, nil
+}
+
+// expand resolves the variables of a single argument. The values of the variables
+// of an unquoted argument are split in words on blanks, the whole argument when it
+// contains the output of a command substitution. Nothing else of a value is parsed.
+func (p *Parser) expand(buf string, got argType, substituted, quoted bool) []string {
+	split := got == argSingle && !quoted
+	if !p.ParseEnv {
+		if substituted && split {
+			return strings.Fields(buf)
+		}
+		return []string{buf}
+	}
+	if substituted && split {
+		return strings.Fields(p.replaceEnv(buf))
+	}
+	if split {
+		return splitEnv(p.lookup(), buf)
+	}
+	return []string{p.replaceEnv(buf)}
+}
+
 func (p *Parser) ParseWithEnvs(line string) (envs []string, args []string, err error) {
 	_args, err := p.Parse(line)
 	if err != nil {
@@ -293,7 +489,8 @@ func (p *Parser) ParseWithEnvs(line string) (envs []string, args []string, err e
 }
 
 func isEnv(arg string) bool {
-	return len(strings.Split(arg, "=")) == 2
+	parts := strings.Split(arg, "=")
+	return len(parts) == 2 && len(parts[0]) > 0
 }
 
 func Parse(line string) ([]string, error) {
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -118,7 +120,9 @@ func TestReplaceEnv(t *testing.T) {
 				return "bar"
 			}
 			return ""
-		}, "hello $UNDEFINED", "hello $UNDEFINED"},
+		}, "hello $UNDEFINED", "hello "},
+		{"undefined braces", nil, "[${UNDEFINED}]", "[]"},
+		{"lone dollar", nil, "a $ b$", "a $ b$"},
 		{"escaped dollar", nil, "price\\$100", "price$100"},
 	}
 
@@ -155,3 +159,375 @@ func TestIsEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_ParseLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		expectError bool
+		expected    []string
+	}{
+		{"empty line", "", false, []string{}},
+		{"single command", "ps", false, []string{"ps"}},
+		{"pipeline", "ps | grep invaders", false, []string{"ps | grep invaders"}},
+		{"quoted operators", `ps | grep "a|b;c"`, false, []string{`ps | grep "a|b;c"`}},
+		{"sequence", "a ; b;c", false, []string{"a", "; b", "; c"}},
+		{"trailing sequence", "a ;", false, []string{"a"}},
+		{"and or", "task restore layout && activate || echo failed", false, []string{"task restore layout", "&& activate", "|| echo failed"}},
+		{"pipeline in list", "ps | wc -l && echo ok", false, []string{"ps | wc -l", "&& echo ok"}},
+		{"missing command after pipe", "ps |", true, nil},
+		{"missing command after and", "ps &&", true, nil},
+		{"missing first command", "| grep x", true, nil},
+		{"leading sequence", "; ps", true, nil},
+		{"background", "stats usage &", false, []string{"stats usage &"}},
+		{"background in list", "stats usage & ps | wc -l & echo ok", false, []string{"stats usage &", "; ps | wc -l &", "; echo ok"}},
+		{"background and", "stats usage & && ps", true, nil},
+		{"leading background", "& ps", true, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewParser()
+			pipelines, err := p.ParseLine(tc.line)
+
+			if (err != nil) != tc.expectError {
+				t.Fatalf("expected error: %v, got error: %v", tc.expectError, err)
+			}
+
+			if !tc.expectError && len(pipelines) != len(tc.expected) {
+				t.Fatalf("expected pipelines: %d, got: %d", len(tc.expected), len(pipelines))
+			}
+
+			for i, pipeline := range pipelines {
+				var stages []string
+				for _, stage := range pipeline.Stages {
+					stages = append(stages, stage.Line)
+				}
+				got := strings.TrimSpace(pipeline.Op + " " + strings.Join(stages, " | "))
+				if pipeline.Background {
+					got += " " + OpBackground
+				}
+				if got != tc.expected[i] {
+					t.Errorf("expected pipeline[%d]: %s, got: %s", i, tc.expected[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestReplaceEnv_ExitStatus(t *testing.T) {
+	env := func(name string) string {
+		if name == "?" {
+			return "1"
+		}
+		return ""
+	}
+	tests := map[string]string{
+		"$?":         "1",
+		"${?}":       "1",
+		"status=$?.": "status=1.",
+		"$?x":        "1x",
+		"$UNSET":     "",
+	}
+	for in, expected := range tests {
+		if got := replaceEnv(env, in); got != expected {
+			t.Errorf("replaceEnv(%q): expected %q, got %q", in, expected, got)
+		}
+	}
+}
+
+func TestParser_ParseLineRedirects(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		expectError bool
+		expected    []Redirect
+	}{
+		{"no redirection", "ps", false, nil},
+		{"output", "stats memory > mem.txt", false, []Redirect{{1, false, "mem.txt"}}},
+		{"append", "ps >> audit.txt", false, []Redirect{{1, true, "audit.txt"}}},
+		{"errors", "ps 2> err.txt", false, []Redirect{{2, false, "err.txt"}}},
+		{"no spaces", "ps>a 2>>b", false, []Redirect{{1, false, "a"}, {2, true, "b"}}},
+		{"buffer", "ps > @procs", false, []Redirect{{1, false, "@procs"}}},
+		{"quoted target", `ps > "a b"`, false, []Redirect{{1, false, "a b"}}},
+		{"digit argument", "kill 12 > out", false, []Redirect{{1, false, "out"}}},
+		{"missing target", "ps >", true, nil},
+		{"argument after target", "ps > a b", true, nil},
+		{"unsupported descriptor", "ps 3> a", true, nil},
+		{"missing command", "> a", true, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewParser()
+			pipelines, err := p.ParseLine(tc.line)
+
+			if (err != nil) != tc.expectError {
+				t.Fatalf("expected error: %v, got error: %v", tc.expectError, err)
+			}
+			if tc.expectError {
+				return
+			}
+
+			stage := pipelines[0].Stages[0]
+			if len(stage.Redirects) != len(tc.expected) {
+				t.Fatalf("expected redirects: %d, got: %d", len(tc.expected), len(stage.Redirects))
+			}
+			for i, r := range stage.Redirects {
+				if *r != tc.expected[i] {
+					t.Errorf("expected redirect[%d]: %v, got: %v", i, tc.expected[i], *r)
+				}
+			}
+		})
+	}
+}
+
+func TestReplaceEnv_Default(t *testing.T) {
+	env := func(name string) string {
+		if name == "FOO" {
+			return "bar"
+		}
+		if name == "DIR" {
+			return "logs"
+		}
+		return ""
+	}
+	tests := map[string]string{
+		"${FOO:-x}":            "bar",
+		"${UNSET:-x}":          "x",
+		"${UNSET:-}":           "",
+		"${UNSET:-$DIR/a}":     "logs/a",
+		"${UNSET:-${DIR}}.txt": "logs.txt",
+		"a${UNSET:-b}c":        "abc",
+		"${UNSET:-x":           "${UNSET:-x",
+	}
+	for in, expected := range tests {
+		if got := replaceEnv(env, in); got != expected {
+			t.Errorf("replaceEnv(%q): expected %q, got %q", in, expected, got)
+		}
+	}
+}
+
+func TestParser_Substitute(t *testing.T) {
+	p := NewParser()
+	p.ParseBacktick = true
+	p.Substitute = func(line string) (string, error) {
+		if line == "fail" {
+			return "", errors.New("failed")
+		}
+		return "[" + line + "]\n", nil
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"kill $(ps --pid-only)", []string{"kill", "[ps", "--pid-only]"}},
+		{"fg `task last`", []string{"fg", "[task", "last]"}},
+		{"a$(b)c", []string{"a[b]c"}},
+		{"echo $(a $(b) (c))", []string{"echo", "[a", "$(b)", "(c)]"}},
+		{"echo '$(a)' \"`b`\"", []string{"echo", "$(a)", "`b`"}},
+		{`echo $(a \| b)`, []string{"echo", `[a`, `\|`, `b]`}},
+		{`echo "$(echo a   b)"`, []string{"echo", "[echo a   b]"}},
+		{`echo "x $(a) y" z`, []string{"echo", "x [a] y", "z"}},
+		{`echo "$(a "b  c")"`, []string{"echo", `[a "b  c"]`}},
+		{`echo "(a)" '$(b)'`, []string{"echo", "(a)", "$(b)"}},
+	}
+	for _, tc := range tests {
+		args, err := p.Parse(tc.input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tc.input, err)
+			continue
+		}
+		if strings.Join(args, ",") != strings.Join(tc.expected, ",") {
+			t.Errorf("Parse(%q): expected %q, got %q", tc.input, tc.expected, args)
+		}
+	}
+
+	if _, err := p.Parse("echo $(fail)"); err == nil {
+		t.Error("expected the error of the substitution")
+	}
+	if _, err := p.Parse("echo $(a"); err == nil {
+		t.Error("expected an error for an unterminated substitution")
+	}
+
+	p.ParseEnv = true
+	p.GetEnv = func(name string) string {
+		return "v"
+	}
+	args, err := p.Parse(`echo "$($X  b)  $X"`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if expected := []string{"echo", "[$X  b]  v"}; strings.Join(args, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %q, got %q", expected, args)
+	}
+}
+
+func TestParser_SingleQuotedEnv(t *testing.T) {
+	p := NewParser()
+	p.ParseEnv = true
+	p.GetEnv = func(name string) string {
+		if name == "USER" {
+			return "bob"
+		}
+		return ""
+	}
+	tests := map[string][]string{
+		`echo '$USER' "$USER" $USER`: {"echo", "$USER", "bob", "bob"},
+		`alias x='echo $1 a\b'`:      {"alias", "x=echo $1 a\\b"},
+	}
+	for in, expected := range tests {
+		args, err := p.Parse(in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", in, err)
+			continue
+		}
+		if strings.Join(args, ",") != strings.Join(expected, ",") {
+			t.Errorf("Parse(%q): expected %q, got %q", in, expected, args)
+		}
+	}
+}
+
+func TestParser_UnsetEnv(t *testing.T) {
+	p := NewParser()
+	p.ParseEnv = true
+	p.LookupEnv = func(name string) (string, bool) {
+		if name == "EMPTY" {
+			return "", true
+		}
+		return "", false
+	}
+	tests := map[string][]string{
+		`echo [$NOPE]`:           {"echo", "[]"},
+		`echo [${NOPE}]`:         {"echo", "[]"},
+		`echo $NOPE x`:           {"echo", "x"},
+		`echo "$NOPE" x`:         {"echo", "", "x"},
+		`echo ${NOPE:-d} $EMPTY`: {"echo", "d"},
+	}
+	for in, expected := range tests {
+		args, err := p.Parse(in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", in, err)
+			continue
+		}
+		if strings.Join(args, ",") != strings.Join(expected, ",") || len(args) != len(expected) {
+			t.Errorf("Parse(%q): expected %q, got %q", in, expected, args)
+		}
+	}
+}
+
+func TestCheckLine(t *testing.T) {
+	tests := []struct {
+		line       string
+		incomplete bool
+		quoted     bool
+		escaped    bool
+		message    string
+	}{
+		{"echo ok", false, false, false, ""},
+		{`echo 'a`, true, true, false, "unterminated single quote"},
+		{`echo "a`, true, true, false, "unterminated double quote"},
+		{`echo "a\`, true, true, true, "unterminated double quote"},
+		{`echo 'a\`, true, true, false, "unterminated single quote"},
+		{"echo `ls", true, true, false, "unterminated backquote"},
+		{"echo $(ls", true, true, false, "unterminated command substitution"},
+		{`echo a \`, true, false, true, `unexpected end of line after '\'`},
+		{"ps |", true, false, false, "missing command after '|'"},
+		{"ps ||", true, false, false, "missing command after '||'"},
+		{"| ps", false, false, false, "unexpected token '|'"},
+		{"echo (a)", false, false, false, "unexpected '(', a command substitution is written $(command)"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.line, func(t *testing.T) {
+			err := CheckLine(tc.line)
+			if len(tc.message) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.message {
+				t.Fatalf("expected error %q, got %v", tc.message, err)
+			}
+			if IsIncomplete(err) != tc.incomplete {
+				t.Fatalf("expected incomplete: %v", tc.incomplete)
+			}
+			if e, ok := err.(*IncompleteError); ok && (e.Quoted != tc.quoted || e.Escaped != tc.escaped) {
+				t.Errorf("expected quoted %v escaped %v, got %v %v", tc.quoted, tc.escaped, e.Quoted, e.Escaped)
+			}
+		})
+	}
+}
+
+func TestParser_SplitEnv(t *testing.T) {
+	p := NewParser()
+	p.ParseEnv = true
+	env := map[string]string{
+		"X":   "a;b",
+		"Y":   "it's",
+		"Z":   "a && b | c $(d)",
+		"W":   "  a   b ",
+		"Q":   `"a b"`,
+		"DIR": "/tmp",
+	}
+	p.GetEnv = func(name string) string {
+		return env[name]
+	}
+	tests := map[string][]string{
+		`echo $X`:          {"echo", "a;b"},
+		`echo $Y`:          {"echo", "it's"},
+		`echo $Z`:          {"echo", "a", "&&", "b", "|", "c", "$(d)"},
+		`echo x$W.y`:       {"echo", "x", "a", "b", ".y"},
+		`echo $Q`:          {"echo", `"a`, `b"`},
+		`echo "$W"`:        {"echo", "  a   b "},
+		`echo $DIR/'a b'`:  {"echo", "/tmp/a b"},
+		`echo $X; echo $Y`: {"echo", "a;b"},
+		`echo $NOPE x`:     {"echo", "x"},
+		`echo ${NOPE:-$Y}`: {"echo", "it's"},
+	}
+	for in, expected := range tests {
+		args, err := p.Parse(in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", in, err)
+			continue
+		}
+		if strings.Join(args, ",") != strings.Join(expected, ",") || len(args) != len(expected) {
+			t.Errorf("Parse(%q): expected %q, got %q", in, expected, args)
+		}
+	}
+}
+
+func TestParser_EscapedEnv(t *testing.T) {
+	p := NewParser()
+	p.ParseEnv = true
+	p.GetEnv = func(name string) string {
+		if name == "HOME" {
+			return "/home/user"
+		}
+		return ""
+	}
+	tests := map[string][]string{
+		`echo \$HOME`:       {"echo", "$HOME"},
+		`echo "\$HOME"`:     {"echo", "$HOME"},
+		`echo \\$HOME`:      {"echo", `\/home/user`},
+		`echo a\ $HOME`:     {"echo", "a /home/user"},
+		`echo \${HOME}`:     {"echo", "${HOME}"},
+		`echo $HOME\$`:      {"echo", "/home/user$"},
+		`echo '\$HOME'`:     {"echo", `\$HOME`},
+		`echo x\\\$HOME`:    {"echo", `x\$HOME`},
+		`echo "\\$HOME"`:    {"echo", `\/home/user`},
+		`echo \$HOME $HOME`: {"echo", "$HOME", "/home/user"},
+	}
+	for in, expected := range tests {
+		args, err := p.Parse(in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", in, err)
+			continue
+		}
+		if strings.Join(args, ",") != strings.Join(expected, ",") || len(args) != len(expected) {
+			t.Errorf("Parse(%q): expected %q, got %q", in, expected, args)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Operators joining the pipelines of a command line
+const (
+	OpNone = ""
+	OpSeq  = ";"
+	OpAnd  = "&&"
+	OpOr   = "||"
+	// OpBackground ends a pipeline that runs as a background job
+	OpBackground = "&"
+)
+
+// Redirect sends the output (Fd 1) or the errors (Fd 2) of a stage to Target
+type Redirect struct {
+	Fd     int
+	Append bool
+	Target string
+}
+
+// Stage is a single command of a pipeline
+type Stage struct {
+	Line      string
+	Args      []string
+	Redirects []*Redirect
+}
+
+// Pipeline is a list of stages joined by '|'. Op is the operator joining the
+// pipeline to the previous one and decides if it runs. Background is set by a trailing '&'.
+type Pipeline struct {
+	Op         string
+	Stages     []*Stage
+	Background bool
+}
+
+// ParseLine splits line in pipelines joined by ';', '&', '&&' and '||', every
+// pipeline is split on '|' and every stage is parsed on its own.
+// The '>', '>>', '2>' and '2>>' redirections are collected in the stage.
+func (p *Parser) ParseLine(line string) ([]*Pipeline, error) {
+	var pipelines []*Pipeline
+	var stage *Stage
+	var redirect *Redirect
+	current := &Pipeline{Op: OpNone}
+
+	for {
+		args, err := p.Parse(line)
+		if err != nil {
+			return nil, err
+		}
+
+		if redirect != nil {
+			if len(args) == 0 {
+				return nil, errors.New("missing target of the redirection")
+			}
+			if len(args) > 1 {
+				return nil, fmt.Errorf("unexpected argument %q after the redirection", args[1])
+			}
+			redirect.Target = args[0]
+			stage.Redirects = append(stage.Redirects, redirect)
+			redirect = nil
+		} else if len(args) > 0 {
+			text := line
+			if p.Position >= 0 {
+				text = line[:p.Position]
+			}
+			stage = &Stage{Line: strings.TrimSpace(text), Args: args}
+		}
+
+		if p.Position < 0 {
+			break
+		}
+
+		rest := line[p.Position:]
+
+		if r, size := parseRedirect(rest); size > 0 {
+			if stage == nil {
+				return nil, fmt.Errorf("unexpected token '%s'", rest[:size])
+			}
+			if r.Fd != 1 && r.Fd != 2 {
+				return nil, fmt.Errorf("unsupported redirection '%s'", rest[:size])
+			}
+			redirect = r
+			line = rest[size:]
+			continue
+		}
+
+		op := OpNone
+		switch {
+		case strings.HasPrefix(rest, OpAnd):
+			op = OpAnd
+		case strings.HasPrefix(rest, OpBackground):
+			op = OpBackground
+		case strings.HasPrefix(rest, OpOr):
+			op = OpOr
+		case strings.HasPrefix(rest, "|"):
+			op = "|"
+		case strings.HasPrefix(rest, OpSeq):
+			op = OpSeq
+		default:
+			return nil, fmt.Errorf("unsupported operator near %q", rest)
+		}
+
+		if stage == nil {
+			return nil, fmt.Errorf("unexpected token '%s'", op)
+		}
+		current.Stages = append(current.Stages, stage)
+		stage = nil
+		line = rest[len(op):]
+
+		if op == OpBackground {
+			// the next pipeline runs without waiting for the job
+			current.Background = true
+			pipelines = append(pipelines, current)
+			current = &Pipeline{Op: OpSeq}
+		} else if op != "|" {
+			pipelines = append(pipelines, current)
+			current = &Pipeline{Op: op}
+		}
+	}
+
+	if stage != nil {
+		current.Stages = append(current.Stages, stage)
+	} else if len(current.Stages) > 0 {
+		return nil, &IncompleteError{What: "missing command after '|'"}
+	} else if current.Op == OpAnd || current.Op == OpOr {
+		return nil, &IncompleteError{What: fmt.Sprintf("missing command after '%s'", current.Op)}
+	}
+
+	if len(current.Stages) > 0 {
+		pipelines = append(pipelines, current)
+	}
+
+	return pipelines, nil
+}
+
+// CheckLine parses line without expanding it or running its command substitutions,
+// it returns the syntax error of the line, an IncompleteError if the command continues on the next line.
+func CheckLine(line string) error {
+	p := NewParser()
+	p.ParseEnv = false
+	p.Substitute = nil
+	_, err := p.ParseLine(line)
+	return err
+}
+
+// parseRedirect recognizes a redirection operator at the beginning of s, optionally
+// preceded by a file descriptor. It returns the size of the operator, 0 if there is none.
+func parseRedirect(s string) (*Redirect, int) {
+	r := &Redirect{Fd: 1}
+	size := 0
+	if len(s) > 0 && s[0] >= '0' && s[0] <= '9' {
+		r.Fd = int(s[0] - '0')
+		size++
+	}
+	if size >= len(s) || s[size] != '>' {
+		return nil, 0
+	}
+	size++
+	if size < len(s) && s[size] == '>' {
+		r.Append = true
+		size++
+	}
+	return r, size
+}
@@ -0,0 +1,306 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OutputFlag is the flag of the commands printing a Result choosing how it is rendered,
+// OutputShorthand is its short form.
+const (
+	OutputFlag      = "output"
+	OutputShorthand = "o"
+)
+
+// Output formats of a Result
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputCSV   = "csv"
+)
+
+// OutputFormats lists the formats accepted by the output flag
+var OutputFormats = []string{OutputTable, OutputJSON, OutputYAML, OutputCSV}
+
+// Result is the structured output of a command: rows sharing the same columns, or a single object.
+type Result struct {
+	columns []string
+	rows    [][]interface{}
+	object  bool
+}
+
+// NewTable returns an empty table with the given columns.
+func NewTable(columns ...string) *Result {
+	return &Result{columns: columns}
+}
+
+// NewObject returns an object without fields, they are added with Set.
+func NewObject() *Result {
+	return &Result{object: true, rows: [][]interface{}{nil}}
+}
+
+// AddRow appends a row to a table, the values are in the order of the columns.
+func (r *Result) AddRow(values ...interface{}) {
+	row := make([]interface{}, len(r.columns))
+	copy(row, values)
+	r.rows = append(r.rows, row)
+}
+
+// Set adds a field to an object.
+func (r *Result) Set(name string, value interface{}) {
+	r.columns = append(r.columns, name)
+	r.rows[0] = append(r.rows[0], value)
+}
+
+func (r *Result) Columns() []string {
+	return r.columns
+}
+
+func (r *Result) Rows() [][]interface{} {
+	return r.rows
+}
+
+func (r *Result) IsObject() bool {
+	return r.object
+}
+
+// Render returns the result in the given format, the lines end with '\n'.
+func (r *Result) Render(format string) (string, error) {
+	switch format {
+	case OutputTable, "":
+		return r.renderTable(), nil
+	case OutputJSON:
+		return r.renderJSON()
+	case OutputYAML:
+		return r.renderYAML(), nil
+	case OutputCSV:
+		return r.renderCSV()
+	}
+	return "", fmt.Errorf("unknown output format %q", format)
+}
+
+// renderTable aligns the columns under an upper case header, an object is a list of name and value.
+func (r *Result) renderTable() string {
+	var lines [][]string
+	if r.object {
+		for i, name := range r.columns {
+			lines = append(lines, []string{name + ":", formatValue(r.rows[0][i])})
+		}
+	} else {
+		header := make([]string, len(r.columns))
+		for i, name := range r.columns {
+			header[i] = strings.ToUpper(name)
+		}
+		lines = append(lines, header)
+		for _, row := range r.rows {
+			line := make([]string, len(row))
+			for i, value := range row {
+				line[i] = formatValue(value)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	widths := make([]int, len(r.columns))
+	for _, line := range lines {
+		for i, cell := range line {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		var cells []string
+		for i, cell := range line {
+			cells = append(cells, fmt.Sprintf("%-*s", widths[i], cell))
+		}
+		b.WriteString(strings.TrimRight(strings.Join(cells, "  "), " ") + "\n")
+	}
+	return b.String()
+}
+
+func (r *Result) renderJSON() (string, error) {
+	var b strings.Builder
+	writeObject := func(row []interface{}, indent string) error {
+		if len(row) == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		b.WriteString("{\n")
+		for i, value := range row {
+			name, _ := json.Marshal(r.columns[i])
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			b.WriteString(indent + "  " + string(name) + ": " + string(data))
+			if i < len(row)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "}")
+		return nil
+	}
+
+	if r.object {
+		if err := writeObject(r.rows[0], ""); err != nil {
+			return "", err
+		}
+		b.WriteString("\n")
+		return b.String(), nil
+	}
+	if len(r.rows) == 0 {
+		return "[]\n", nil
+	}
+	b.WriteString("[\n")
+	for i, row := range r.rows {
+		b.WriteString("  ")
+		if err := writeObject(row, "  "); err != nil {
+			return "", err
+		}
+		if i < len(r.rows)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	return b.String(), nil
+}
+
+func (r *Result) renderYAML() string {
+	var b strings.Builder
+	if r.object {
+		if len(r.columns) == 0 {
+			return "{}\n"
+		}
+		for i, name := range r.columns {
+			b.WriteString(yamlScalar(name) + ": " + yamlValue(r.rows[0][i]) + "\n")
+		}
+		return b.String()
+	}
+	if len(r.rows) == 0 {
+		return "[]\n"
+	}
+	for _, row := range r.rows {
+		prefix := "- "
+		for i, value := range row {
+			b.WriteString(prefix + yamlScalar(r.columns[i]) + ": " + yamlValue(value) + "\n")
+			prefix = "  "
+		}
+		if len(row) == 0 {
+			b.WriteString("- {}\n")
+		}
+	}
+	return b.String()
+}
+
+func (r *Result) renderCSV() (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write(r.columns); err != nil {
+		return "", err
+	}
+	for _, row := range r.rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = formatValue(value)
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return b.String(), w.Error()
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return fmt.Sprint(value)
+}
+
+// yamlValue renders numbers and booleans as they are and the other values as strings
+func yamlValue(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return formatValue(value)
+	}
+	return yamlScalar(formatValue(value))
+}
+
+// yamlScalar quotes the strings a YAML parser would read as something else
+func yamlScalar(s string) string {
+	if len(s) == 0 || strings.TrimSpace(s) != s || strings.ContainsAny(s, ":#\n\t\"'") ||
+		strings.ContainsAny(s[:1], "-?[]{},&*!|>%@`") {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// InitOutputFlag adds the output flag to c, a command printing its results with PrintResult.
+func (c *Command) InitOutputFlag() {
+	if c.Flags().Lookup(OutputFlag) == nil {
+		var format string
+		c.Flags().EnumVarP(&format, OutputFlag, OutputShorthand, OutputTable, OutputFormats, "output format of the results")
+	}
+}
+
+// OutputFormat returns the format chosen with the output flag, table when the flag is not defined.
+func (c *Command) OutputFormat() string {
+	if f := c.Flags().Lookup(OutputFlag); f != nil {
+		return f.Value.String()
+	}
+	return OutputTable
+}
+
+// PrintResult writes res in the format chosen with the output flag.
+func (c *Command) PrintResult(res *Result) {
+	text, err := res.Render(c.OutputFormat())
+	if err != nil {
+		c.PrintErrf(DefaultEol+"%s: %s"+DefaultEol, c.Name(), err.Error())
+		c.SetExitCode(ExitFailure)
+		return
+	}
+	text = strings.ReplaceAll(text, "\n", DefaultEol)
+	if r := c.GetRootContext(); r != nil {
+		r.WriteLn("")
+		r.Write(text)
+		return
+	}
+	c.Print(text)
+}
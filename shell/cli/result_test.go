@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"testing"
+)
+
+func newProcessTable() *Result {
+	res := NewTable("pid", "name", "command")
+	res.AddRow(1, "snake", "snake --speed 2")
+	res.AddRow(12, "ps", "ps")
+	return res
+}
+
+func TestResult_Render(t *testing.T) {
+	object := NewObject()
+	object.Set("cpus", 4)
+	object.Set("alloc_mb", 1.5)
+	object.Set("version", "1.0")
+
+	tests := []struct {
+		res      *Result
+		format   string
+		expected string
+	}{
+		{newProcessTable(), OutputTable, "PID  NAME   COMMAND\n1    snake  snake --speed 2\n12   ps     ps\n"},
+		{newProcessTable(), OutputJSON, "[\n  {\n    \"pid\": 1,\n    \"name\": \"snake\",\n    \"command\": \"snake --speed 2\"\n  },\n" +
+			"  {\n    \"pid\": 12,\n    \"name\": \"ps\",\n    \"command\": \"ps\"\n  }\n]\n"},
+		{newProcessTable(), OutputYAML, "- pid: 1\n  name: snake\n  command: snake --speed 2\n- pid: 12\n  name: ps\n  command: ps\n"},
+		{newProcessTable(), OutputCSV, "pid,name,command\n1,snake,snake --speed 2\n12,ps,ps\n"},
+		{NewTable("name"), OutputJSON, "[]\n"},
+		{NewTable("name"), OutputYAML, "[]\n"},
+		{NewTable("name"), OutputTable, "NAME\n"},
+		{object, OutputTable, "cpus:      4\nalloc_mb:  1.5\nversion:   1.0\n"},
+		{object, OutputJSON, "{\n  \"cpus\": 4,\n  \"alloc_mb\": 1.5,\n  \"version\": \"1.0\"\n}\n"},
+		{object, OutputYAML, "cpus: 4\nalloc_mb: 1.5\nversion: \"1.0\"\n"},
+		{object, OutputCSV, "cpus,alloc_mb,version\n4,1.5,1.0\n"},
+	}
+	for _, tc := range tests {
+		out, err := tc.res.Render(tc.format)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.format, err)
+			continue
+		}
+		if out != tc.expected {
+			t.Errorf("%s: expected\n%q\ngot\n%q", tc.format, tc.expected, out)
+		}
+	}
+	if _, err := newProcessTable().Render("xml"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestYamlScalar(t *testing.T) {
+	tests := map[string]string{
+		"plain":      "plain",
+		"":           `""`,
+		"true":       `"true"`,
+		"12":         `"12"`,
+		"a: b":       `"a: b"`,
+		"- item":     `"- item"`,
+		" padded":    `" padded"`,
+		"say \"hi\"": `"say \"hi\""`,
+	}
+	for in, expected := range tests {
+		if out := yamlScalar(in); out != expected {
+			t.Errorf("yamlScalar(%q): expected %s, got %s", in, expected, out)
+		}
+	}
+}
+
+func TestCommand_OutputFormat(t *testing.T) {
+	root := newCompletionTree()
+	memory, _ := root.Resolve("/stats/memory")
+	kill, _ := root.Resolve("/kill")
+	memory.InitOutputFlag()
+
+	if err := memory.ParseFlags([]string{"--output", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	if format := memory.OutputFormat(); format != OutputJSON {
+		t.Errorf("expected json, got %s", format)
+	}
+	if err := memory.ParseFlags([]string{"-o", "yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	if format := memory.OutputFormat(); format != OutputYAML {
+		t.Errorf("expected yaml, got %s", format)
+	}
+	// the next invocation starts from the default
+	if err := memory.ParseFlags(nil); err != nil {
+		t.Fatal(err)
+	}
+	if format := memory.OutputFormat(); format != OutputTable {
+		t.Errorf("expected table, got %s", format)
+	}
+	if err := memory.ParseFlags([]string{"--output", "xml"}); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+
+	// only the commands printing a result have the flag
+	if err := kill.ParseFlags([]string{"--output", "json"}); err == nil {
+		t.Errorf("expected an error for a command without results")
+	}
+	if format := kill.OutputFormat(); format != OutputTable {
+		t.Errorf("expected table, got %s", format)
+	}
+}
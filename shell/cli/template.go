@@ -0,0 +1,110 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"github.com/markel1974/goshell/shell/cli/mflag"
+)
+
+// TemplateFunc returns a function building a copy of the commands of template at every call,
+// it adapts a template to the servers building the commands of every session.
+// The copies share the flags of template and the variables bound to them: a flag set
+// by a session is seen by the others. Build the commands in the function instead.
+func TemplateFunc(template *Command) func() *Command {
+	if template == nil {
+		return nil
+	}
+	return func() *Command {
+		return cloneTree(template)
+	}
+}
+
+func cloneTree(src *Command) *Command {
+	dst := cloneCommand(src)
+	for _, child := range src.Childs() {
+		if child != nil {
+			_ = dst.AddCommand(cloneTree(child))
+		}
+	}
+	return dst
+}
+
+func cloneCommand(src *Command) *Command {
+	dst := NewCommand()
+
+	dst.Use = src.Use
+	dst.Aliases = src.Aliases
+	dst.SuggestFor = src.SuggestFor
+	dst.Short = src.Short
+	dst.Long = src.Long
+	dst.Example = src.Example
+	dst.ValidArgs = src.ValidArgs
+	dst.ValidArgsFunction = src.ValidArgsFunction
+	dst.Args = src.Args
+	dst.ArgAliases = src.ArgAliases
+	dst.Deprecated = src.Deprecated
+	dst.Hidden = src.Hidden
+	dst.Annotations = src.Annotations
+	dst.Version = src.Version
+	dst.SilenceErrors = src.SilenceErrors
+	dst.SilenceUsage = src.SilenceUsage
+	dst.DisableFlagParsing = src.DisableFlagParsing
+	dst.DisableAutoGenTag = src.DisableAutoGenTag
+	dst.DisableFlagsInUseLine = src.DisableFlagsInUseLine
+	dst.DisableSuggestions = src.DisableSuggestions
+	dst.SuggestionsMinimumDistance = src.SuggestionsMinimumDistance
+	dst.TraverseChildren = src.TraverseChildren
+	dst.FParseErrWhitelist = src.FParseErrWhitelist
+	dst.Activate = src.Activate
+	dst.Background = src.Background
+	dst.Paged = src.Paged
+	dst.PersistentPreRun = src.PersistentPreRun
+	dst.PersistentPreRunE = src.PersistentPreRunE
+	dst.PreRun = src.PreRun
+	dst.PreRunE = src.PreRunE
+	dst.Run = src.Run
+	dst.RunContext = src.RunContext
+	dst.TimerEvent = src.TimerEvent
+	dst.ReadEvent = src.ReadEvent
+	dst.PaintEvent = src.PaintEvent
+	dst.RunE = src.RunE
+	dst.PostRun = src.PostRun
+	dst.PostRunE = src.PostRunE
+	dst.PersistentPostRun = src.PersistentPostRun
+	dst.PersistentPostRunE = src.PersistentPostRunE
+	dst.usageFunc = src.usageFunc
+	dst.usageTemplate = src.usageTemplate
+	dst.flagErrorFunc = src.flagErrorFunc
+	dst.helpFunc = src.helpFunc
+	dst.helpTemplate = src.helpTemplate
+	dst.versionTemplate = src.versionTemplate
+	if src.globNormFunc != nil {
+		dst.SetGlobalNormalizationFunc(src.globNormFunc)
+	}
+	dst.Flags().AddFlagSet(src.Flags())
+	dst.PersistentFlags().AddFlagSet(src.PersistentFlags())
+	copyFlagCompletions(dst, src, src.Flags())
+	copyFlagCompletions(dst, src, src.PersistentFlags())
+
+	return dst
+}
+
+func copyFlagCompletions(dst *Command, src *Command, flags *mflag.FlagSet) {
+	flags.VisitAll(func(f *mflag.Flag) {
+		if fn, ok := src.GetFlagCompletionFunc(f.Name); ok {
+			_ = dst.RegisterFlagCompletionFunc(f.Name, fn)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestTemplateFunc(t *testing.T) {
+	if TemplateFunc(nil) != nil {
+		t.Error("expected no function for a nil template")
+	}
+
+	template := NewCommand()
+	foo := &Command{Use: "foo", Short: "foo command"}
+	foo.Flags().Int("speed", 1, "speed")
+	_ = foo.RegisterFlagCompletionFunc("speed", func(cmd *Command, args []string, toComplete string) []Completion {
+		return nil
+	})
+	_ = foo.AddCommand(&Command{Use: "bar"})
+	_ = template.AddCommand(foo)
+
+	f := TemplateFunc(template)
+	a, b := f(), f()
+	if a == template || a == b {
+		t.Fatal("expected a new root at every call")
+	}
+	for _, root := range []*Command{a, b} {
+		childs := root.Childs()
+		if len(childs) != 1 || childs[0] == foo || childs[0].Name() != "foo" || childs[0].Short != "foo command" {
+			t.Fatalf("expected a copy of foo, got %v", childs)
+		}
+		if childs[0].Flags().Lookup("speed") == nil {
+			t.Error("expected the flags of foo")
+		}
+		if _, ok := childs[0].GetFlagCompletionFunc("speed"); !ok {
+			t.Error("expected the completion of the flags of foo")
+		}
+		if sub := childs[0].Childs(); len(sub) != 1 || sub[0].Name() != "bar" || sub[0].Parent() != childs[0] {
+			t.Errorf("expected a copy of bar under foo, got %v", sub)
+		}
+	}
+	if a.Childs()[0] == b.Childs()[0] {
+		t.Error("expected the sessions to get their own commands")
+	}
+}
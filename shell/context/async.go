@@ -0,0 +1,247 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"context"
+	"github.com/markel1974/goshell/shell/cli"
+)
+
+const (
+	spinnerFrames   = `|/-\`
+	spinnerDelay    = 150
+	spinnerInterval = 100
+)
+
+// asyncKey holds the pid in the context of a command running off the event loop
+type asyncKey struct{}
+
+// asyncPid returns the pid of the command running off the event loop with ctx
+func asyncPid(ctx context.Context) (int, bool) {
+	pid, ok := ctx.Value(asyncKey{}).(int)
+	return pid, ok
+}
+
+// asyncWriter sends the output of a command running off the event loop to the event loop, in order.
+// The output is dropped when the session ends.
+type asyncWriter struct {
+	pid    int
+	stderr bool
+	ch     chan iMessage
+	closed chan struct{}
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	if !send(w.ch, w.closed, newMessageOutput(w.pid, string(p), w.stderr)) {
+		return 0, context.Canceled
+	}
+	return len(p), nil
+}
+
+// send posts m to the event loop keeping the order of the messages, it returns false when the session has ended
+func send(ch chan iMessage, closed chan struct{}, m iMessage) bool {
+	select {
+	case ch <- m:
+		return true
+	case <-closed:
+		return false
+	}
+}
+
+// ExecuteInteractive runs a command line typed at the prompt, its RunContext commands run off the event loop.
+// The rest of the line waits for such a command, or for an activated task, and runs when it ends.
+func (c *TaskManager) ExecuteInteractive(line string) int {
+	pipelines, err := c.root.ParseLine(line)
+	if err != nil {
+		c.root.PrintErrf(cli.DefaultEol+"Error %s"+cli.DefaultEol, err.Error())
+		c.status = cli.ExitUsage
+		return c.status
+	}
+	return c.ContinueLine(pipelines)
+}
+
+// ContinueLine runs the pipelines left by the foreground task, when it ends or it is stopped.
+func (c *TaskManager) ContinueLine(pipelines []*cli.Pipeline) int {
+	// a line run from a command, like history exec, waits for its commands
+	c.interactive = c.stages == 0
+	defer func() { c.interactive = false }()
+	return c.executePipelines(pipelines, nil, nil)
+}
+
+// canRunAsync determines if a stage has been typed at the prompt, or starts a job, and writes to the terminal:
+// not a part of a pipeline, a redirection, a substitution, an alias or a function.
+func (c *TaskManager) canRunAsync(input bool) bool {
+	if input || c.depth > 0 || len(c.expanding) > 0 || len(c.params) > 0 {
+		return false
+	}
+	// the stage starting a job, its output is kept with the job
+	if job := c.starting; job != nil {
+		return c.stages == job.stages && c.output.Captures() == job.captures
+	}
+	return c.interactive && c.stages == 0 && !c.output.IsCapturing()
+}
+
+// runContext starts the RunContext of a command: off the event loop, holding the prompt, when
+// the stage allows it, in place otherwise. The context is cancelled when the task is killed.
+func (c *TaskManager) runContext(cmd *cli.Command, pid int, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t, ok := c.ids.Get(pid)
+	if !ok || pid != c.asyncPid {
+		run(ctx)
+		cancel()
+		return
+	}
+
+	task := t.(*Task)
+	ctx = context.WithValue(ctx, asyncKey{}, pid)
+	task.cancel = cancel
+	task.async = true
+	task.state = taskStateRunning
+	c.foreground = task
+	c.waiting = task
+	c.lastPid = pid
+
+	c.asyncCmds[cmd] = pid
+	cmd.SetOut(&asyncWriter{pid: pid, ch: c.messageChan, closed: c.closed})
+	cmd.SetErr(&asyncWriter{pid: pid, stderr: true, ch: c.messageChan, closed: c.closed})
+	c.startSpinner(task)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				cmd.PrintErrf(cli.DefaultEol+"%s: %v"+cli.DefaultEol, cmd.Name(), r)
+				cmd.SetExitCode(cli.ExitFailure)
+			}
+			send(c.messageChan, c.closed, newMessageDone(pid, cmd))
+		}()
+		run(ctx)
+	}()
+}
+
+// AsyncOutput writes the output of the command running off the event loop as the task pid.
+// The output of a killed task is dropped.
+func (c *TaskManager) AsyncOutput(pid int, data string, stderr bool) {
+	t, ok := c.ids.Get(pid)
+	if !ok || !t.(*Task).async {
+		return
+	}
+	task := t.(*Task)
+	if c.isBackground(task) {
+		task.job.output.WriteString(data)
+		return
+	}
+	c.eraseSpinner(task)
+	if stderr {
+		_, _ = c.output.Stderr().Write([]byte(data))
+		return
+	}
+	c.output.WriteString(data)
+}
+
+// AsyncDone ends the task pid once its command, run off the event loop, has returned.
+// It returns the rest of the command line, unless the task has been killed.
+// The prompt is not resumed: the caller runs the rest of the line first.
+func (c *TaskManager) AsyncDone(pid int, cmd *cli.Command) ([]*cli.Pipeline, bool) {
+	delete(c.asyncCmds, cmd)
+	cmd.SetOut(nil)
+	cmd.SetErr(nil)
+
+	t, ok := c.ids.Get(pid)
+	if !ok {
+		return nil, false
+	}
+	task := t.(*Task)
+	if !task.async || task.cmd != cmd {
+		return nil, false
+	}
+	c.eraseSpinner(task)
+	task.async = false
+	if c.isBackground(task) {
+		c.Kill(pid)
+		return nil, false
+	}
+	c.status = cmd.ExitCode()
+	if c.foreground == task {
+		c.foreground = nil
+	}
+	c.Kill(pid)
+	return task.rest, true
+}
+
+// Close cancels the commands running off the event loop, their output is dropped.
+func (c *TaskManager) Close() {
+	// the rest of the line of the foreground task is not run
+	c.foreground = nil
+	c.KillAll("")
+	close(c.closed)
+}
+
+// Ask reports if the task pid can ask the user: only the foreground task running off the event loop can.
+// The spinner is hidden until Answered.
+func (c *TaskManager) Ask(pid int) bool {
+	t, ok := c.ids.Get(pid)
+	if !ok {
+		return false
+	}
+	task := t.(*Task)
+	if !task.async || c.foreground != task {
+		return false
+	}
+	c.eraseSpinner(task)
+	task.asking = true
+	return true
+}
+
+// Answered ends the question of the task pid
+func (c *TaskManager) Answered(pid int) {
+	if t, ok := c.ids.Get(pid); ok {
+		t.(*Task).asking = false
+	}
+}
+
+// IsAsync reports if the task pid runs off the event loop
+func (c *TaskManager) IsAsync(pid int) bool {
+	t, ok := c.ids.Get(pid)
+	return ok && t.(*Task).async
+}
+
+func (c *TaskManager) startSpinner(task *Task) {
+	m := newMessageTimer(task.pid, spinnerInterval)
+	m.tid = c.ticker.Create(c.timersChan, m, spinnerDelay, spinnerInterval, -1)
+	if m.tid > -1 {
+		task.timers = append(task.timers, m.tid)
+	}
+}
+
+// spin draws the next frame of the spinner at the cursor
+func (c *TaskManager) spin(task *Task) {
+	if c.output.IsCapturing() || c.isBackground(task) || task.asking {
+		return
+	}
+	frame := string(spinnerFrames[task.spinner%len(spinnerFrames)])
+	if task.spinning {
+		frame = "\b" + frame
+	}
+	c.output.WriteString(frame)
+	task.spinner++
+	task.spinning = true
+}
+
+func (c *TaskManager) eraseSpinner(task *Task) {
+	if task.spinning {
+		c.output.WriteString("\b \b")
+		task.spinning = false
+	}
+}
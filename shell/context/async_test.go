@@ -0,0 +1,149 @@
+package context
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markel1974/goshell/shell/adaptiveticker"
+	"github.com/markel1974/goshell/shell/authenticator"
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"github.com/markel1974/goshell/shell/terminal"
+)
+
+// testSession is a session without a terminal client, the messages of the event loop are handled by pump
+type testSession struct {
+	t       *testing.T
+	ctx     *Context
+	out     *bytes.Buffer
+	release chan struct{}
+	// question is run by the command ask, its result is sent to answers
+	question func(ctx context.Context, r interfaces.IContext) (interface{}, error)
+	answers  chan askResult
+}
+
+type askResult struct {
+	value interface{}
+	err   error
+}
+
+// newTestSession creates a session having the command work: it prints its tag, waits for release
+// or for its cancellation, then prints its tag again and ends with the status given by --status.
+// The command ask runs the question of the session.
+func newTestSession(t *testing.T) *testSession {
+	s := &testSession{t: t, out: &bytes.Buffer{}, release: make(chan struct{}), answers: make(chan askResult, 1)}
+	template := func() *cli.Command {
+		root := cli.NewCommand()
+		work := &cli.Command{Use: "work", Short: "Work"}
+		tag := work.Flags().String("tag", "", "tag")
+		status := work.Flags().Int("status", 0, "exit status")
+		work.RunContext = func(ctx context.Context, cmd *cli.Command, pid int, args []string) {
+			cmd.Print("start " + *tag + cli.DefaultEol)
+			select {
+			case <-s.release:
+			case <-ctx.Done():
+				return
+			}
+			cmd.Print("end " + *tag + cli.DefaultEol)
+			cmd.SetExitCode(*status)
+		}
+		ask := &cli.Command{Use: "ask", Short: "Ask"}
+		ask.RunContext = func(ctx context.Context, cmd *cli.Command, pid int, args []string) {
+			value, err := s.question(ctx, cmd.GetRootContext())
+			s.answers <- askResult{value: value, err: err}
+		}
+		_ = root.AddCommand(work)
+		_ = root.AddCommand(ask)
+		return root
+	}
+	s.ctx = NewContext(adaptiveticker.NewAdaptiveTicker(), strings.NewReader(""), s.out, authenticator.NewSimpleAuthenticator(), terminal.NewEquipmentFactory(), template, "> ", false)
+	s.ctx.SetDataDir(t.TempDir())
+	s.ctx.SetUser("bob")
+	s.ctx.Setup()
+	t.Cleanup(s.ctx.tasks.Close)
+	return s
+}
+
+// run executes line as typed at the prompt and returns its output
+func (s *testSession) run(line string) string {
+	s.out.Reset()
+	s.ctx.execCommand(line)
+	return s.out.String()
+}
+
+// keys sends data as typed by the client
+func (s *testSession) keys(data string) {
+	s.ctx.terminal.Scan([]byte(data))
+}
+
+// pump handles the messages of the event loop until done reports true
+func (s *testSession) pump(done func() bool) {
+	timeout := time.After(2 * time.Second)
+	for !done() {
+		select {
+		case m := <-s.ctx.messageChan:
+			s.ctx.messageEventHandler(m)
+		case tm := <-s.ctx.timersChan:
+			s.ctx.messageEventHandler(tm.Event.(iMessage))
+		case <-timeout:
+			s.t.Fatal("timeout waiting the event loop")
+		}
+	}
+}
+
+// job returns the job with the given id
+func (s *testSession) job(id int) *Job {
+	for _, job := range s.ctx.tasks.jobs {
+		if job.id == id {
+			return job
+		}
+	}
+	s.t.Fatalf("job %d not found", id)
+	return nil
+}
+
+func TestAsync_CommandRunsOnceAtATime(t *testing.T) {
+	s := newTestSession(t)
+	s.run("work --tag a --status 3 &")
+	s.pump(func() bool { return strings.Contains(s.job(1).output.String(), "start a") })
+
+	// the second job ends at once, the flags of the first one are not parsed again
+	s.run("work --tag b &")
+	s.pump(func() bool { return s.job(2).state == interfaces.JobDone })
+	if output := s.job(2).output.String(); !strings.Contains(output, "already running") || s.job(2).status != cli.ExitFailure {
+		t.Fatalf("expected the second job to be refused, got %q status %d", output, s.job(2).status)
+	}
+
+	close(s.release)
+	s.pump(func() bool { return s.job(1).state == interfaces.JobDone })
+	if output := s.job(1).output.String(); output != "start a"+cli.DefaultEol+"end a"+cli.DefaultEol {
+		t.Errorf("expected the whole output in the first job, got %q", output)
+	}
+	if s.job(1).status != 3 {
+		t.Errorf("expected the status of the first job, got %d", s.job(1).status)
+	}
+
+	// the command runs again once the first job has returned
+	s.run("work --tag c &")
+	s.pump(func() bool { return len(s.ctx.tasks.jobs) == 3 && s.job(3).state == interfaces.JobDone })
+	if output := s.job(3).output.String(); output != "start c"+cli.DefaultEol+"end c"+cli.DefaultEol {
+		t.Errorf("expected the output of the second job, got %q", output)
+	}
+}
+
+func TestAsync_KilledCommandRunsAgainWhenItReturns(t *testing.T) {
+	s := newTestSession(t)
+	s.run("work --tag a &")
+	s.pump(func() bool { return strings.Contains(s.job(1).output.String(), "start a") })
+	s.run("kill %1")
+	// the goroutine of the killed command returns on the cancellation
+	s.pump(func() bool { return len(s.ctx.tasks.asyncCmds) == 0 })
+
+	s.run("work --tag b &")
+	s.pump(func() bool {
+		return len(s.ctx.tasks.jobs) > 0 && strings.Contains(s.ctx.tasks.jobs[len(s.ctx.tasks.jobs)-1].output.String(), "start b")
+	})
+}
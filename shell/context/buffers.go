@@ -0,0 +1,79 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"errors"
+	"sort"
+)
+
+// BufferPrefix marks a redirection target as a session buffer, e.g. ps > @procs
+const BufferPrefix = "@"
+
+// Buffers are the named in-memory outputs of a session
+type Buffers struct {
+	data map[string]string
+}
+
+func NewBuffers() *Buffers {
+	return &Buffers{
+		data: make(map[string]string),
+	}
+}
+
+func (b *Buffers) Write(name string, data string, append bool) error {
+	if !isBufferName(name) {
+		return errors.New("invalid buffer name: " + name)
+	}
+	if append {
+		data = b.data[name] + data
+	}
+	b.data[name] = data
+	return nil
+}
+
+func (b *Buffers) Get(name string) (string, bool) {
+	data, ok := b.data[name]
+	return data, ok
+}
+
+func (b *Buffers) Delete(name string) bool {
+	if _, ok := b.data[name]; !ok {
+		return false
+	}
+	delete(b.data, name)
+	return true
+}
+
+func (b *Buffers) List() []string {
+	var out []string
+	for name := range b.data {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func isBufferName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import "github.com/markel1974/goshell/shell/cli"
+
+// completionWords splits the last command of line in words, the last word is the one being completed.
+// Quotes are removed, a word with a variable or a substitution is kept as typed and a command substitution
+// still open is completed as a command on its own. target is set when the word being completed is the
+// target of a redirection, the targets are not words of the command.
+func completionWords(line string) (words []string, data string, target bool) {
+	rs := []rune(line)
+	tokens := cli.Lex(line)
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].Kind == cli.TokenOperator {
+			tokens = tokens[i+1:]
+			break
+		}
+	}
+	if size := len(tokens); size > 0 && tokens[size-1].Open >= 0 {
+		return completionWords(string(rs[tokens[size-1].Open:]))
+	}
+
+	redirect := false
+	for i, t := range tokens {
+		if t.Kind != cli.TokenWord {
+			redirect = true
+			continue
+		}
+		word := t.Text
+		if t.Expanded {
+			word = string(rs[t.Start:t.End])
+		}
+		if i == len(tokens)-1 && t.End == len(rs) {
+			return words, word, redirect
+		}
+		if !redirect {
+			words = append(words, word)
+		}
+		redirect = false
+	}
+	return words, "", redirect
+}
@@ -0,0 +1,49 @@
+package context
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompletionWords(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		words  []string
+		data   string
+		target bool
+	}{
+		{"empty", "", nil, "", false},
+		{"command", "kil", nil, "kil", false},
+		{"after the command", "kill ", []string{"kill"}, "", false},
+		{"flag", "kill -", []string{"kill"}, "-", false},
+		{"arguments", "stats memory -u k", []string{"stats", "memory", "-u"}, "k", false},
+		{"pipeline", "ps | gr", nil, "gr", false},
+		{"last command", "ps; kill 1 && stats m", []string{"stats"}, "m", false},
+		{"quotes", `echo 'a b' c`, []string{"echo", "a b"}, "c", false},
+		{"open quote", `echo "a b`, []string{"echo"}, "a b", false},
+		{"escaped blank", `echo a\ b`, []string{"echo"}, "a b", false},
+		{"quoted operator", `echo '|' x`, []string{"echo", "|"}, "x", false},
+		{"variable", "echo $HO", []string{"echo"}, "$HO", false},
+		{"variable argument", "echo $HOME x", []string{"echo", "$HOME"}, "x", false},
+		{"substitution", "echo $(ls) -", []string{"echo", "$(ls)"}, "-", false},
+		{"open substitution", "echo $(ls -", []string{"ls"}, "-", false},
+		{"open substitution after a blank", "echo $(ls ", []string{"ls"}, "", false},
+		{"open substitution in quotes", `echo "$(kill -`, []string{"kill"}, "-", false},
+		{"open substitution pipeline", "echo $(ps | gr", nil, "gr", false},
+		{"open nested substitution", "echo $(a $(stats m", []string{"stats"}, "m", false},
+		{"open backtick", "echo `stats m", []string{"stats"}, "m", false},
+		{"redirection target", "ps > ou", []string{"ps"}, "ou", true},
+		{"empty redirection target", "ps 2>> ", []string{"ps"}, "", true},
+		{"after the redirection", "ps > out -", []string{"ps"}, "-", false},
+		{"redirection without blanks", "ps 2>err -v", []string{"ps"}, "-v", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, data, target := completionWords(tt.line)
+			if strings.Join(words, "|") != strings.Join(tt.words, "|") || len(words) != len(tt.words) || data != tt.data || target != tt.target {
+				t.Errorf("got %q %q %v, want %q %q %v", words, data, target, tt.words, tt.data, tt.target)
+			}
+		})
+	}
+}
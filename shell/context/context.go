@@ -15,6 +15,7 @@
 package context
 
 import (
+	"errors"
 	"github.com/markel1974/goshell/shell/adaptiveticker"
 	"github.com/markel1974/goshell/shell/apps"
 	"github.com/markel1974/goshell/shell/apps/shell"
@@ -22,19 +23,30 @@ import (
 	"github.com/markel1974/goshell/shell/interfaces"
 	"github.com/markel1974/goshell/shell/terminal"
 	"io"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 )
 
 const (
 	contextMaQueueLen = 1024
 )
 
+var sessionCounter uint64
+
+// readOnlyVars are the built-in variables, provided by the session
+var readOnlyVars = map[string]bool{"?": true, "USER": true, "SESSION": true, "PWD": true}
+
 type Context struct {
 	Exit        bool
 	ticker      *adaptiveticker.AdaptiveTicker
 	reader      io.Reader
 	writer      io.Writer
 	factory     *terminal.EquipmentFactory
-	template    *cli.Command
+	template    func() *cli.Command
 	terminal    interfaces.ITerminal
 	auth        interfaces.IAuthenticator
 	defaultApp  *shell.Shell
@@ -44,9 +56,19 @@ type Context struct {
 	timersChan  chan *adaptiveticker.TimerHandler
 	prompt      string
 	autosave    bool
-}
-
-func NewContext(ticker *adaptiveticker.AdaptiveTicker, reader io.Reader, writer io.Writer, auth interfaces.IAuthenticator, factory *terminal.EquipmentFactory, template *cli.Command, prompt string, autosave bool) *Context {
+	env         *Environment
+	output      *Output
+	storage     *Storage
+	buffers     *Buffers
+	vars        *Variables
+	shortcuts   *Shortcuts
+	scripts     *Scripts
+	session     string
+	login       bool
+	historySize uint
+}
+
+func NewContext(ticker *adaptiveticker.AdaptiveTicker, reader io.Reader, writer io.Writer, auth interfaces.IAuthenticator, factory *terminal.EquipmentFactory, template func() *cli.Command, prompt string, autosave bool) *Context {
 	ctx := &Context{
 		ticker:      ticker,
 		reader:      reader,
@@ -61,39 +83,63 @@ func NewContext(ticker *adaptiveticker.AdaptiveTicker, reader io.Reader, writer
 		timersChan:  make(chan *adaptiveticker.TimerHandler, contextMaQueueLen),
 		tasks:       nil,
 		autosave:    autosave,
+		env:         NewEnvironment(nil),
+		storage:     NewStorage(""),
+		buffers:     NewBuffers(),
+		vars:        NewVariables(),
+		shortcuts:   NewShortcuts(),
+		scripts:     NewScripts(""),
+		session:     strconv.FormatUint(atomic.AddUint64(&sessionCounter, 1), 10),
 	}
 	return ctx
 }
 
 func (c *Context) Setup() {
 	c.terminal = c.factory.Create("VT100", c.writer)
+	c.output = NewOutput(c.terminal)
 	c.terminal.SetKeyFunc(c.keyHandler)
 	if c.enterKey > -1 {
 		c.terminal.SetEnterKey(c.enterKey)
 	}
 
-	template := apps.NewTemplate(c, c.writer)
+	template := apps.NewTemplate(c, c.output, c.output.Stderr())
 	root := template.Run(c.template)
 
-	c.tasks = NewTaskManager(c.ticker, c.timersChan, root)
+	root.SetEnvFunc(c.lookupVar)
+
+	c.tasks = NewTaskManager(c.ticker, c.timersChan, c.messageChan, root, c.output, c.storage, c.buffers, c.shortcuts)
 
 	c.defaultApp = shell.NewShell(c.auth, c.terminal, c.prompt, c.autosave)
+	c.defaultApp.SetSession(c.session)
+	if c.historySize > 0 {
+		c.defaultApp.SetHistorySize(c.historySize)
+	}
+	// a session without login has the history of the user given by the server, or the anonymous one
+	if len(c.storage.User()) > 0 || c.auth.IsAuthenticated() {
+		c.defaultApp.SetHistoryFile(c.storage.HistoryPath())
+	}
 	c.defaultApp.ExecCommand = c.execCommand
 	c.defaultApp.ExecSuggestion = c.execSuggestion
+	c.defaultApp.ExecHighlight = c.tasks.Highlight
+	c.defaultApp.ExecLogin = c.execLogin
+	c.defaultApp.ExecNotify = c.tasks.NotifyJobs
+
+	c.tasks.SetForegroundExitFunc(c.continueLine)
 }
 
 func (c *Context) SetScreenSize(width int, height int) {
 	c.terminal.SetSize(width, height)
 	c.tasks.SetScreenSize(width, height)
+	c.defaultApp.SetScreenSize(width, height)
 }
 
 func (c *Context) keyHandler(event *interfaces.KeyData) {
-	if event.GetType() == interfaces.KeyTypeCtrl {
-		c.ctrlPressed(event.Key)
+	if event.GetType() == interfaces.KeyTypeCtrl && c.ctrlPressed(event.Key) {
 		return
 	}
 
-	if fgPid := c.tasks.GetForegroundPid(); fgPid != adaptiveticker.UnknownId {
+	// a question of the foreground task is answered with the line editor
+	if fgPid := c.tasks.GetForegroundPid(); fgPid != adaptiveticker.UnknownId && !c.defaultApp.IsAsking() {
 		c.tasks.ExecRead(fgPid, int(event.GetType()), event.Key)
 		return
 	}
@@ -104,6 +150,89 @@ func (c *Context) keyHandler(event *interfaces.KeyData) {
 	}
 }
 
+func (c *Context) SetEnvAllowlist(allowlist []string) {
+	c.env = NewEnvironment(allowlist)
+}
+
+// SetEnv stores a variable received from the client, it returns false if the variable is not allowed.
+func (c *Context) SetEnv(name string, value string) bool {
+	return c.env.Set(name, value)
+}
+
+// SetDataDir sets the directory holding the data directories of the users
+func (c *Context) SetDataDir(dir string) {
+	c.storage.SetDir(dir)
+}
+
+// SetHistorySize sets the number of commands kept in the history, shell.DefaultHistorySize when not set
+func (c *Context) SetHistorySize(size uint) {
+	c.historySize = size
+}
+
+// SetHistoryDir sets the directory of the history files of the users
+func (c *Context) SetHistoryDir(dir string) {
+	c.storage.SetHistoryDir(dir)
+}
+
+// SetScriptsDir sets the directory of the scripts run with source and run
+func (c *Context) SetScriptsDir(dir string) {
+	c.scripts.SetDir(dir)
+}
+
+// SetScripts sets the scripts embedded by the host, they take precedence over the scripts directory
+func (c *Context) SetScripts(fsys fs.FS) {
+	c.scripts.SetEmbedded(fsys)
+}
+
+// SetUser sets the authenticated user of the session and restores its settings,
+// the login script runs before the first prompt. The variables, the aliases and the
+// functions of the previous user are dropped, they are not saved with the new user settings.
+func (c *Context) SetUser(user string) {
+	c.login = true
+	c.storage.SetUser(user)
+	if c.defaultApp != nil {
+		c.defaultApp.SetHistoryFile(c.storage.HistoryPath())
+	}
+	c.vars.Clear()
+	c.shortcuts.Clear()
+	settings, err := LoadSettings(c.storage.SettingsPath())
+	if err != nil {
+		log.Println("Failed to load the settings of", user, err)
+	}
+	for name, value := range settings.Variables {
+		if c.vars.Set(name, value) == nil {
+			_ = c.vars.Export(name)
+		}
+	}
+	for name, value := range settings.Aliases {
+		_ = c.shortcuts.SetAlias(name, value)
+	}
+	for name, body := range settings.Functions {
+		_ = c.shortcuts.SetFunction(name, body)
+	}
+}
+
+func (c *Context) execLogin(user string) {
+	c.SetUser(user)
+	c.runLogin()
+}
+
+// runLogin sources the login script of the user, if any
+func (c *Context) runLogin() {
+	if !c.login {
+		return
+	}
+	c.login = false
+	if name, ok := c.scripts.Login(c.storage.User()); ok {
+		c.execCommand("source " + name)
+	}
+}
+
+// updateSettings saves a change of the settings of the user, merged with the changes of its other sessions
+func (c *Context) updateSettings(update func(s *Settings)) error {
+	return UpdateSettings(c.storage.SettingsPath(), update)
+}
+
 func (c *Context) SetEnterKey(key rune) {
 	c.enterKey = key
 }
@@ -135,43 +264,89 @@ func (c *Context) Exec() {
 }
 
 func (c *Context) execCommand(line string) bool {
-	return c.tasks.Execute(line, nil)
+	status := c.tasks.ExecuteInteractive(line)
+	c.defaultApp.SetStatus(status)
+	if c.tasks.HoldsPrompt() {
+		c.defaultApp.Suspend()
+	}
+	return status == cli.ExitOK
+}
+
+// asyncDone runs the rest of the line of a command that ran off the event loop, then resumes the prompt
+func (c *Context) asyncDone(pid int, cmd *cli.Command) {
+	if rest, ok := c.tasks.AsyncDone(pid, cmd); ok {
+		c.continueLine(rest)
+	}
 }
 
-func (c *Context) ctrlPressed(key rune) {
+// continueLine runs the rest of a line left by the foreground task, then resumes the prompt
+func (c *Context) continueLine(rest []*cli.Pipeline) {
+	c.defaultApp.CancelAsk()
+	c.defaultApp.SetStatus(c.tasks.ContinueLine(rest))
+	if c.tasks.HoldsPrompt() {
+		c.defaultApp.Suspend()
+	} else {
+		c.defaultApp.Resume()
+	}
+}
+
+// lookupVar resolves the variables referenced by a command line: the built-in
+// variables first, the parameters of the running function, then the shell variables
+// and the environment of the client.
+func (c *Context) lookupVar(name string) (string, bool) {
+	switch name {
+	case "?":
+		return strconv.Itoa(c.tasks.GetStatus()), true
+	case "USER":
+		return c.storage.User(), true
+	case "SESSION":
+		return c.session, true
+	case "PWD":
+		return c.tasks.GetBasePath(), true
+	}
+	if value, ok := c.tasks.GetParam(name); ok {
+		return value, true
+	}
+	if value, ok := c.vars.Lookup(name); ok {
+		return value, true
+	}
+	return c.env.Lookup(name)
+}
+
+// ctrlPressed handles the control keys acting on the tasks, it returns false for the keys
+// left to the foreground task or to the line editor
+func (c *Context) ctrlPressed(key rune) bool {
 	switch key {
 	case 3:
+		suspended := c.defaultApp.IsSuspended()
 		c.tasks.SetSelectionDisabled()
 		c.tasks.KillForeground()
-		c.defaultApp.DoNext()
+		if !suspended {
+			c.defaultApp.DoNext()
+		}
 	case 4:
+		// without a foreground task ctrl-D deletes the character under the cursor
+		if c.tasks.GetForegroundPid() == adaptiveticker.UnknownId {
+			return false
+		}
 		c.tasks.ExecActivate()
+	case 26:
+		if rest, ok := c.tasks.StopForeground(); ok {
+			c.continueLine(rest)
+		}
+	default:
+		return false
 	}
+	return true
 }
 
-func (c *Context) execSuggestion(in string, count int) bool {
-	ret := false
-	data, suggestions, found := c.tasks.GetSuggestion(in, count)
-	if found {
-		sLen := len(suggestions)
-		if sLen > 0 {
-			idx := count % sLen
-			if idx < sLen {
-				complete := suggestions[idx]
-				if len(complete) > len(data) {
-					tabLine := in + complete[len(data):]
-					c.defaultApp.DoRedraw(tabLine)
-					c.defaultApp.SetHistoryDefault(tabLine)
-					ret = true
-				}
-			}
-		}
-	}
-	return ret
+func (c *Context) execSuggestion(in string) (string, []cli.Completion) {
+	return c.tasks.GetSuggestion(in)
 }
 
 func (c *Context) eventLoop() {
 	_, _ = c.terminal.WriteColor("Admin Console Ready", interfaces.ColorBlueDef, interfaces.ColorRedDef, interfaces.ModeNormal)
+	c.runLogin()
 	c.defaultApp.DoNext()
 	for {
 		select {
@@ -209,38 +384,212 @@ func (c *Context) messageEventHandler(m iMessage) {
 			if _, ok := m.(*MessageQuit); ok {
 				c.Exit = true
 			}
+
+		case MessageTypeOutput:
+			if mo, ok := m.(*MessageOutput); ok {
+				c.tasks.AsyncOutput(mo.pid, mo.data, mo.stderr)
+			}
+
+		case MessageTypeDone:
+			if md, ok := m.(*MessageDone); ok {
+				c.asyncDone(md.pid, md.cmd)
+			}
+
+		case MessageTypeInput:
+			if mi, ok := m.(*MessageInput); ok {
+				c.askInput(mi)
+			}
+
+		case MessageTypeCall:
+			if mc, ok := m.(*MessageCall); ok {
+				c.call(mc)
+			}
 		}
 	}
 }
 
 func (c *Context) shutdown() {
-	c.tasks.KillAll("")
+	c.tasks.Close()
 }
 
 //CLI INTERFACE
 
+func (c *Context) GetEnv(name string) string {
+	value, _ := c.lookupVar(name)
+	return value
+}
+
+func (c *Context) Environ() []string {
+	vars := make(map[string]string)
+	for _, list := range [][]string{c.env.List(), c.vars.List(false)} {
+		for _, v := range list {
+			if idx := strings.Index(v, "="); idx > 0 {
+				vars[v[:idx]] = v[idx+1:]
+			}
+		}
+	}
+	for name := range readOnlyVars {
+		if name != "?" {
+			vars[name], _ = c.lookupVar(name)
+		}
+	}
+	var out []string
+	for name, value := range vars {
+		out = append(out, name+"="+value)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (c *Context) SetVar(name string, value string) error {
+	if readOnlyVars[name] {
+		return errors.New("read-only variable: " + name)
+	}
+	if err := c.vars.Set(name, value); err != nil {
+		return err
+	}
+	if c.vars.IsExported(name) {
+		return c.updateSettings(func(s *Settings) { s.Variables[name] = value })
+	}
+	return nil
+}
+
+func (c *Context) UnsetVar(name string) error {
+	if readOnlyVars[name] {
+		return errors.New("read-only variable: " + name)
+	}
+	if c.vars.Unset(name) {
+		return c.updateSettings(func(s *Settings) { delete(s.Variables, name) })
+	}
+	return nil
+}
+
+func (c *Context) ExportVar(name string) error {
+	if readOnlyVars[name] {
+		return errors.New("read-only variable: " + name)
+	}
+	if err := c.vars.Export(name); err != nil {
+		return err
+	}
+	value, _ := c.vars.Lookup(name)
+	return c.updateSettings(func(s *Settings) { s.Variables[name] = value })
+}
+
+func (c *Context) ListVars(exported bool) []string {
+	return c.vars.List(exported)
+}
+
+func (c *Context) SetAlias(name string, value string) error {
+	if err := c.shortcuts.SetAlias(name, value); err != nil {
+		return err
+	}
+	return c.updateSettings(func(s *Settings) { s.Aliases[name] = value })
+}
+
+func (c *Context) UnsetAlias(name string) error {
+	if !c.shortcuts.UnsetAlias(name) {
+		return errors.New("alias not found: " + name)
+	}
+	return c.updateSettings(func(s *Settings) { delete(s.Aliases, name) })
+}
+
+func (c *Context) GetAlias(name string) (string, bool) {
+	return c.shortcuts.Alias(name)
+}
+
+func (c *Context) ListAliases() []string {
+	return sortedKeys(c.shortcuts.Aliases())
+}
+
+func (c *Context) SetFunction(name string, body string) error {
+	if err := c.shortcuts.SetFunction(name, body); err != nil {
+		return err
+	}
+	return c.updateSettings(func(s *Settings) { s.Functions[name] = body })
+}
+
+func (c *Context) UnsetFunction(name string) error {
+	if !c.shortcuts.UnsetFunction(name) {
+		return errors.New("function not found: " + name)
+	}
+	return c.updateSettings(func(s *Settings) { delete(s.Functions, name) })
+}
+
+func (c *Context) GetFunction(name string) (string, bool) {
+	return c.shortcuts.Function(name)
+}
+
+func (c *Context) ListFunctions() []string {
+	return sortedKeys(c.shortcuts.Functions())
+}
+
+func (c *Context) ListBuffers() []string {
+	return c.buffers.List()
+}
+
+func (c *Context) GetBuffer(name string) (string, bool) {
+	return c.buffers.Get(name)
+}
+
+func (c *Context) DeleteBuffer(name string) bool {
+	return c.buffers.Delete(name)
+}
+
+func (c *Context) ExecLine(line string) int {
+	return c.tasks.Execute(line, nil)
+}
+
+func (c *Context) ExpandArgs(text string) ([]string, error) {
+	return c.tasks.root.ParseArgs(text)
+}
+
+func (c *Context) LoadScript(name string) (string, error) {
+	return c.scripts.Load(name)
+}
+
 func (c *Context) SetFg(pid int) bool {
 	return c.tasks.SetFg(pid)
 }
 
-func (c *Context) TaskList() string {
-	return c.tasks.List()
+func (c *Context) SetBg(pid int) bool {
+	return c.tasks.SetBg(pid)
+}
+
+func (c *Context) Jobs() []interfaces.JobInfo {
+	return c.tasks.Jobs()
+}
+
+func (c *Context) FindJob(spec string) (interfaces.JobInfo, bool) {
+	return c.tasks.FindJob(spec)
+}
+
+func (c *Context) Processes() []interfaces.ProcessInfo {
+	return c.tasks.Processes()
+}
+
+func (c *Context) LastPid() int {
+	return c.tasks.GetLastPid()
+}
+
+// IsOutputCaptured determines if the output goes to a pipeline, a redirection or a substitution instead of the terminal
+func (c *Context) IsOutputCaptured() bool {
+	return c.output.IsCapturing()
 }
 
 func (c *Context) Write(data string) {
-	_, _ = c.terminal.Write(data)
+	c.output.WriteString(data)
 }
 func (c *Context) WriteLn(data string) {
-	_, _ = c.terminal.Write(data + "\r\n")
+	c.output.WriteString(data + "\r\n")
 }
 
 func (c *Context) WriteColor(data string, fg interfaces.ColorDef, bg interfaces.ColorDef, mode interfaces.ColorMode) {
-	_, _ = c.terminal.WriteColor(data, fg, bg, mode)
+	c.output.WriteColor(data, fg, bg, mode)
 }
 
 func (c *Context) WriteColorLn(data string, fg interfaces.ColorDef, bg interfaces.ColorDef, mode interfaces.ColorMode) {
-	_, _ = c.terminal.WriteColor(data, fg, bg, mode)
-	_, _ = c.terminal.Write("\r\n")
+	c.output.WriteColor(data, fg, bg, mode)
+	c.output.WriteString("\r\n")
 }
 
 func (c *Context) ClearScreen() {
@@ -299,8 +648,17 @@ func (c *Context) SetExit() {
 	c.Exit = true
 }
 
-func (c *Context) SetBasePath(arg string) {
-	c.tasks.SetBasePath(arg)
+// SetBasePath moves the position in the command tree, shown in the prompt
+func (c *Context) SetBasePath(arg string) error {
+	if err := c.tasks.SetBasePath(arg); err != nil {
+		return err
+	}
+	c.defaultApp.SetPath(c.tasks.GetBasePath())
+	return nil
+}
+
+func (c *Context) GetBasePath() string {
+	return c.tasks.GetBasePath()
 }
 
 func (c *Context) SetSelectionMode(pid int) {
@@ -319,6 +677,10 @@ func (c *Context) SetSelectionOptions(option rune, value float64) bool {
 	return c.tasks.SetSelectionOptions(option, value)
 }
 
+func (c *Context) GetHistory() []interfaces.HistoryEntry {
+	return c.defaultApp.GetHistory()
+}
+
 func (c *Context) History(verb interfaces.HistoryAction, idx int) {
 	switch verb {
 	case interfaces.HistoryActionClear:
@@ -327,7 +689,5 @@ func (c *Context) History(verb interfaces.HistoryAction, idx int) {
 		if arg, found := c.defaultApp.GetHistoryAtPos(idx); found {
 			c.execCommand(arg)
 		}
-	case interfaces.HistoryActionList:
-		_, _ = c.terminal.Write(c.defaultApp.GetHistory())
 	}
 }
@@ -0,0 +1,93 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultEnvAllowlist is used when the server has not been configured with an explicit allowlist.
+// An entry ending with '*' matches every variable with that prefix.
+var DefaultEnvAllowlist = []string{"LANG", "LC_*", "TERM", "TZ"}
+
+// Environment holds the variables sent by the client during the session negotiation.
+// Variables can be received from the transport goroutines, so every access is guarded.
+type Environment struct {
+	lock      sync.RWMutex
+	vars      map[string]string
+	allowlist []string
+}
+
+func NewEnvironment(allowlist []string) *Environment {
+	if allowlist == nil {
+		allowlist = DefaultEnvAllowlist
+	}
+	return &Environment{
+		vars:      make(map[string]string),
+		allowlist: allowlist,
+	}
+}
+
+func (e *Environment) IsAllowed(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for _, allowed := range e.allowlist {
+		if strings.HasSuffix(allowed, "*") {
+			if strings.HasPrefix(name, allowed[:len(allowed)-1]) {
+				return true
+			}
+		} else if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Environment) Set(name string, value string) bool {
+	if !e.IsAllowed(name) {
+		return false
+	}
+	e.lock.Lock()
+	e.vars[name] = value
+	e.lock.Unlock()
+	return true
+}
+
+func (e *Environment) Get(name string) string {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.vars[name]
+}
+
+func (e *Environment) Lookup(name string) (string, bool) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	value, ok := e.vars[name]
+	return value, ok
+}
+
+func (e *Environment) List() []string {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	var out []string
+	for name, value := range e.vars {
+		out = append(out, name+"="+value)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,52 @@
+package context
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvironment_Allowlist(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowlist []string
+		variable  string
+		allowed   bool
+	}{
+		{"default", nil, "LANG", true},
+		{"default prefix", nil, "LC_ALL", true},
+		{"default rejects", nil, "PATH", false},
+		{"default rejects prefix only", nil, "LC", false},
+		{"empty name", nil, "", false},
+		{"explicit", []string{"FOO"}, "FOO", true},
+		{"explicit is exact", []string{"FOO"}, "FOOBAR", false},
+		{"explicit replaces default", []string{"FOO"}, "LANG", false},
+		{"prefix", []string{"APP_*"}, "APP_MODE", true},
+		{"empty allowlist", []string{}, "LANG", false},
+		{"everything", []string{"*"}, "PATH", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEnvironment(tc.allowlist)
+			if e.IsAllowed(tc.variable) != tc.allowed {
+				t.Errorf("expected IsAllowed(%q) %v", tc.variable, tc.allowed)
+			}
+			if e.Set(tc.variable, "v") != tc.allowed {
+				t.Errorf("expected Set(%q) %v", tc.variable, tc.allowed)
+			}
+			if _, ok := e.Lookup(tc.variable); ok != tc.allowed {
+				t.Errorf("expected Lookup(%q) %v", tc.variable, tc.allowed)
+			}
+		})
+	}
+}
+
+func TestEnvironment_List(t *testing.T) {
+	e := NewEnvironment(nil)
+	e.Set("TERM", "xterm")
+	e.Set("LANG", "C")
+	e.Set("PATH", "/bin")
+	if list := strings.Join(e.List(), " "); list != "LANG=C TERM=xterm" {
+		t.Errorf("unexpected list %q", list)
+	}
+}
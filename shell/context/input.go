@@ -0,0 +1,162 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"context"
+	"fmt"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"strconv"
+	"strings"
+)
+
+// ask shows text and prompt for the command running off the event loop with ctx and waits for the line typed
+func (c *Context) ask(ctx context.Context, text string, prompt string, echo bool) (string, error) {
+	pid, ok := asyncPid(ctx)
+	if !ok {
+		return "", interfaces.ErrNotInteractive
+	}
+	m := newMessageInput(pid, text, prompt, echo)
+	if !send(c.messageChan, c.tasks.closed, m) {
+		return "", context.Canceled
+	}
+	select {
+	case answer := <-m.reply:
+		return answer.text, answer.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// askInput opens the question of m with the line editor, if its task holds the prompt
+func (c *Context) askInput(m *MessageInput) {
+	if c.defaultApp.IsAsking() || !c.tasks.Ask(m.pid) {
+		m.reply <- inputAnswer{err: interfaces.ErrNotInteractive}
+		return
+	}
+	c.output.WriteString(m.text)
+	c.defaultApp.Ask(m.prompt, m.echo, func(answer string, ok bool) {
+		c.tasks.Answered(m.pid)
+		if !ok {
+			m.reply <- inputAnswer{err: interfaces.ErrCancelled}
+			return
+		}
+		m.reply <- inputAnswer{text: answer}
+	})
+}
+
+// Confirm asks a yes or no question, an empty answer is def
+func (c *Context) Confirm(ctx context.Context, question string, def bool) (bool, error) {
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+	for {
+		answer, err := c.ask(ctx, "", question+" "+hint+" ", true)
+		if err != nil {
+			return def, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// Prompt asks for a line, an empty answer is def
+func (c *Context) Prompt(ctx context.Context, question string, def string) (string, error) {
+	prompt := question + ": "
+	if len(def) > 0 {
+		prompt = fmt.Sprintf("%s [%s]: ", question, def)
+	}
+	answer, err := c.ask(ctx, "", prompt, true)
+	if err != nil {
+		return def, err
+	}
+	if len(answer) == 0 {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// Password asks for a line without showing it
+func (c *Context) Password(ctx context.Context, question string) (string, error) {
+	return c.ask(ctx, "", question+": ", false)
+}
+
+// Select asks to choose one of the options by number or by name and returns its index.
+// An empty answer is def, when def is a valid index.
+func (c *Context) Select(ctx context.Context, question string, options []string, def int) (int, error) {
+	if len(options) == 0 {
+		return def, fmt.Errorf("no options for %q", question)
+	}
+	var text strings.Builder
+	for i, option := range options {
+		text.WriteString(fmt.Sprintf("\r\n  %d) %s", i+1, option))
+	}
+	prompt := fmt.Sprintf("%s [1-%d]: ", question, len(options))
+	if def >= 0 && def < len(options) {
+		prompt = fmt.Sprintf("%s [1-%d, default %d]: ", question, len(options), def+1)
+	}
+	for {
+		answer, err := c.ask(ctx, text.String(), prompt, true)
+		if err != nil {
+			return def, err
+		}
+		answer = strings.TrimSpace(answer)
+		if len(answer) == 0 && def >= 0 && def < len(options) {
+			return def, nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		for i, option := range options {
+			if answer == option {
+				return i, nil
+			}
+		}
+	}
+}
+
+// Call runs f on the event loop and waits for it. A command running in place is on the event loop already.
+func (c *Context) Call(ctx context.Context, f func()) error {
+	pid, ok := asyncPid(ctx)
+	if !ok {
+		f()
+		return nil
+	}
+	m := newMessageCall(pid, f)
+	if !send(c.messageChan, c.tasks.closed, m) {
+		return context.Canceled
+	}
+	select {
+	case <-m.done:
+		return ctx.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// call runs the function of m, unless its task has been killed
+func (c *Context) call(m *MessageCall) {
+	if c.tasks.IsAsync(m.pid) {
+		m.f()
+	}
+	close(m.done)
+}
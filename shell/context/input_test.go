@@ -0,0 +1,182 @@
+package context
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+)
+
+// answer runs the command ask with question, types each answer once the question is shown and
+// returns the result with the output of the session
+func (s *testSession) answer(question func(ctx context.Context, r interfaces.IContext) (interface{}, error), answers ...string) (askResult, string) {
+	s.question = question
+	s.run("ask")
+	var result askResult
+	ended := false
+	done := func() bool {
+		select {
+		case result = <-s.answers:
+			ended = true
+			return true
+		default:
+			return s.ctx.defaultApp.IsAsking()
+		}
+	}
+	for _, answer := range answers {
+		s.pump(done)
+		if ended {
+			s.t.Fatalf("expected a question for %q", answer)
+		}
+		s.keys(answer)
+	}
+	s.pump(func() bool { return done() && ended })
+	return result, s.out.String()
+}
+
+func TestInput_Confirm(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      bool
+		answers  []string
+		expected bool
+		err      error
+	}{
+		{"y", false, []string{"y\r"}, true, nil},
+		{"yes", false, []string{"yes\r"}, true, nil},
+		{"no", true, []string{"No\r"}, false, nil},
+		{"default yes", true, []string{"\r"}, true, nil},
+		{"default no", false, []string{"\r"}, false, nil},
+		{"asked again", false, []string{"maybe\r", " Y \r"}, true, nil},
+		{"cancelled", true, []string{"y\x1b"}, true, interfaces.ErrCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSession(t)
+			result, output := s.answer(func(ctx context.Context, r interfaces.IContext) (interface{}, error) {
+				return r.Confirm(ctx, "Kill all?", tt.def)
+			}, tt.answers...)
+			if result.value != tt.expected || result.err != tt.err {
+				t.Errorf("got %v %v, want %v %v", result.value, result.err, tt.expected, tt.err)
+			}
+			hint := "Kill all? [y/N] "
+			if tt.def {
+				hint = "Kill all? [Y/n] "
+			}
+			if !strings.Contains(output, hint) {
+				t.Errorf("expected the question in %q", output)
+			}
+		})
+	}
+}
+
+func TestInput_Prompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      string
+		answers  []string
+		expected string
+		prompt   string
+	}{
+		{"answer", "", []string{"alice\r"}, "alice", "Name: "},
+		{"default", "bob", []string{"\r"}, "bob", "Name [bob]: "},
+		{"answer over the default", "bob", []string{"alice\r"}, "alice", "Name [bob]: "},
+		{"edited", "", []string{"alicx\x7fe\r"}, "alice", "Name: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSession(t)
+			result, output := s.answer(func(ctx context.Context, r interfaces.IContext) (interface{}, error) {
+				return r.Prompt(ctx, "Name", tt.def)
+			}, tt.answers...)
+			if result.value != tt.expected || result.err != nil {
+				t.Errorf("got %v %v, want %v", result.value, result.err, tt.expected)
+			}
+			if !strings.Contains(output, tt.prompt) {
+				t.Errorf("expected %q in %q", tt.prompt, output)
+			}
+		})
+	}
+}
+
+func TestInput_Password(t *testing.T) {
+	s := newTestSession(t)
+	result, output := s.answer(func(ctx context.Context, r interfaces.IContext) (interface{}, error) {
+		return r.Password(ctx, "Password")
+	}, "secret\r")
+	if result.value != "secret" || result.err != nil {
+		t.Errorf("got %v %v, want secret", result.value, result.err)
+	}
+	if !strings.Contains(output, "Password: ") || strings.Contains(output, "secret") {
+		t.Errorf("expected the password not shown, got %q", output)
+	}
+}
+
+func TestInput_Select(t *testing.T) {
+	options := []string{"red", "green", "blue"}
+	tests := []struct {
+		name     string
+		def      int
+		answers  []string
+		expected int
+		prompt   string
+	}{
+		{"number", -1, []string{"2\r"}, 1, "Color [1-3]: "},
+		{"name", -1, []string{"blue\r"}, 2, "Color [1-3]: "},
+		{"default", 0, []string{"\r"}, 0, "Color [1-3, default 1]: "},
+		{"out of range", 0, []string{"4\r", "0\r", "3\r"}, 2, "Color [1-3, default 1]: "},
+		{"no default", -1, []string{"\r", "red\r"}, 0, "Color [1-3]: "},
+		{"unknown name", -1, []string{"Red\r", "1\r"}, 0, "Color [1-3]: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSession(t)
+			result, output := s.answer(func(ctx context.Context, r interfaces.IContext) (interface{}, error) {
+				return r.Select(ctx, "Color", options, tt.def)
+			}, tt.answers...)
+			if result.value != tt.expected || result.err != nil {
+				t.Errorf("got %v %v, want %v", result.value, result.err, tt.expected)
+			}
+			if !strings.Contains(output, "  1) red\r\n  2) green\r\n  3) blue") || !strings.Contains(output, tt.prompt) {
+				t.Errorf("expected the options and %q in %q", tt.prompt, output)
+			}
+		})
+	}
+}
+
+func TestInput_NotInteractive(t *testing.T) {
+	s := newTestSession(t)
+	s.question = func(ctx context.Context, r interfaces.IContext) (interface{}, error) {
+		return r.Confirm(ctx, "Kill all?", true)
+	}
+	// a command in a pipeline runs in place, no user can answer it
+	s.run("ask | grep x")
+	result := <-s.answers
+	if result.value != true || result.err != interfaces.ErrNotInteractive {
+		t.Errorf("got %v %v, want the default and ErrNotInteractive", result.value, result.err)
+	}
+}
+
+func TestInput_KillallWithoutPrompt(t *testing.T) {
+	s := newTestSession(t)
+	s.run("work --tag a &")
+	s.pump(func() bool { return strings.Contains(s.job(1).output.String(), "start a") })
+
+	// nobody can confirm the kill of a background job
+	s.run("killall work &")
+	s.pump(func() bool { return s.job(2).state == interfaces.JobDone })
+	if output := s.job(2).output.String(); !strings.Contains(output, "use --yes") || s.job(2).status != cli.ExitFailure {
+		t.Fatalf("expected killall to fail, got %q status %d", output, s.job(2).status)
+	}
+	if s.job(1).state != interfaces.JobRunning {
+		t.Fatalf("expected the job alive")
+	}
+
+	s.run("killall --yes work &")
+	s.pump(func() bool { return s.job(3).state == interfaces.JobDone })
+	if output := s.job(3).output.String(); !strings.Contains(output, "Task deactivated: 1") || s.job(1).state != interfaces.JobDone {
+		t.Errorf("expected the job killed, got %q", output)
+	}
+}
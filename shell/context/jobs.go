@@ -0,0 +1,298 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"fmt"
+	"github.com/markel1974/goshell/shell/adaptiveticker"
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Job is a command line run in the background with '&', or a foreground task stopped with ctrl-z.
+// The output written while the job is in the background is kept, it is shown when the job
+// is brought to the foreground or when it ends.
+type Job struct {
+	id         int
+	pid        int
+	line       string
+	state      string
+	status     int
+	start      time.Time
+	end        time.Time
+	foreground bool
+	output     strings.Builder
+	// stages and captures tell the stages starting the job from the ones they run
+	stages   int
+	captures int
+	// pending are the timers fired while the job was stopped, they fire again when it continues
+	pending map[int]int
+}
+
+func (c *TaskManager) newJob(line string) *Job {
+	id := 1
+	if size := len(c.jobs); size > 0 {
+		id = c.jobs[size-1].id + 1
+	}
+	job := &Job{
+		id:      id,
+		pid:     adaptiveticker.UnknownId,
+		line:    line,
+		state:   interfaces.JobRunning,
+		start:   time.Now(),
+		pending: make(map[int]int),
+	}
+	c.jobs = append(c.jobs, job)
+	return job
+}
+
+// executeJob starts a pipeline as a background job. Its output is kept with the job
+// and the next pipeline runs at once: a command running off the event loop or an activated
+// task keeps the job running, any other command ends it before executeJob returns.
+func (c *TaskManager) executeJob(pipeline *cli.Pipeline, template *Task, input io.Reader) int {
+	var lines []string
+	for _, stage := range pipeline.Stages {
+		lines = append(lines, stage.Line)
+	}
+	job := c.newJob(strings.Join(lines, " | "))
+
+	parent := c.starting
+	c.starting = job
+	c.output.Capture()
+	c.output.CaptureErr()
+	job.stages = c.stages
+	job.captures = c.output.Captures()
+
+	status := c.executePipeline(pipeline.Stages, template, input)
+
+	job.output.WriteString(c.output.ReleaseErr())
+	job.output.WriteString(c.output.Release())
+	c.starting = parent
+
+	if t, ok := c.ids.Get(job.pid); !ok || t.(*Task).job != job {
+		c.endJob(job, status)
+	}
+	c.output.WriteString(fmt.Sprintf("%s[%d] %d%s", cli.DefaultEol, job.id, job.pid, cli.DefaultEol))
+	return cli.ExitOK
+}
+
+// attachJob makes task the task of job, it runs in the background
+func (c *TaskManager) attachJob(job *Job, task *Task) {
+	job.pid = task.pid
+	task.job = job
+	if c.foreground == task {
+		c.foreground = nil
+	}
+	if c.waiting == task {
+		c.waiting = nil
+	}
+	c.lastPid = task.pid
+}
+
+// endJob records the exit status of job. A job ended in the foreground is forgotten,
+// the others are reported by NotifyJobs.
+func (c *TaskManager) endJob(job *Job, status int) {
+	if job.state == interfaces.JobDone {
+		return
+	}
+	job.state = interfaces.JobDone
+	job.status = status
+	job.end = time.Now()
+	if job.foreground {
+		c.removeJob(job)
+	}
+}
+
+func (c *TaskManager) removeJob(job *Job) {
+	for i, j := range c.jobs {
+		if j == job {
+			c.jobs = append(c.jobs[:i], c.jobs[i+1:]...)
+			return
+		}
+	}
+}
+
+// continueJob runs a stopped job again, the timers fired while it was stopped fire now
+func (c *TaskManager) continueJob(job *Job) {
+	if job.state != interfaces.JobStopped {
+		return
+	}
+	job.state = interfaces.JobRunning
+	pending := job.pending
+	job.pending = make(map[int]int)
+	for tid, interval := range pending {
+		c.ExecTimer(job.pid, tid, interval)
+	}
+}
+
+// isBackground reports if the output of task is kept with its job
+func (c *TaskManager) isBackground(task *Task) bool {
+	return task.job != nil && !task.job.foreground && task.job.state != interfaces.JobDone
+}
+
+// runBackground runs an event of a task, the output of a background job is kept with the job
+func (c *TaskManager) runBackground(task *Task, event func()) {
+	if !c.isBackground(task) {
+		event()
+		return
+	}
+	job := task.job
+	c.output.Capture()
+	c.output.CaptureErr()
+	event()
+	job.output.WriteString(c.output.ReleaseErr())
+	job.output.WriteString(c.output.Release())
+}
+
+// StopForeground moves the foreground task to a job in the background, stopped unless it runs
+// off the event loop. It returns the rest of the command line waiting for the task.
+func (c *TaskManager) StopForeground() ([]*cli.Pipeline, bool) {
+	task := c.foreground
+	if task == nil {
+		return nil, false
+	}
+	job := task.job
+	if job == nil {
+		job = c.newJob(task.Line)
+		c.attachJob(job, task)
+	}
+	job.foreground = false
+	if !task.async {
+		job.state = interfaces.JobStopped
+	}
+	c.foreground = nil
+	c.eraseSpinner(task)
+	c.status = cli.ExitStopped
+
+	c.output.WriteString(fmt.Sprintf("%s[%d]%s  %-8s  %s%s", cli.DefaultEol, job.id, c.jobMark(job), job.state, job.line, cli.DefaultEol))
+
+	rest := task.rest
+	task.rest = nil
+	return rest, true
+}
+
+// SetBg runs the stopped job of the task pid in the background
+func (c *TaskManager) SetBg(pid int) bool {
+	t, ok := c.ids.Get(pid)
+	if !ok {
+		return false
+	}
+	job := t.(*Task).job
+	if job == nil || job.foreground {
+		return false
+	}
+	c.continueJob(job)
+	c.output.WriteString(fmt.Sprintf("[%d]%s %s &%s", job.id, c.jobMark(job), job.line, cli.DefaultEol))
+	return true
+}
+
+// NotifyJobs writes the output and the exit status of the jobs ended in the background, then forgets them.
+func (c *TaskManager) NotifyJobs() {
+	var running []*Job
+	for _, job := range c.jobs {
+		if job.state != interfaces.JobDone {
+			running = append(running, job)
+			continue
+		}
+		if output := strings.TrimLeft(job.output.String(), cli.DefaultEol); len(output) > 0 {
+			if !strings.HasSuffix(output, cli.DefaultEol) {
+				output += cli.DefaultEol
+			}
+			c.output.WriteString(output)
+		}
+		c.output.WriteString(fmt.Sprintf("[%d]%s  %-8s  %s%s", job.id, c.jobMark(job), jobResult(job.status), job.line, cli.DefaultEol))
+	}
+	c.jobs = running
+}
+
+// jobResult describes the exit status of a job
+func jobResult(status int) string {
+	switch status {
+	case cli.ExitOK:
+		return interfaces.JobDone
+	case cli.ExitInterrupt:
+		return "Killed"
+	}
+	return "Exit " + strconv.Itoa(status)
+}
+
+// jobMark is '+' for the current job, the last one started, and '-' for the previous one
+func (c *TaskManager) jobMark(job *Job) string {
+	size := len(c.jobs)
+	if size > 0 && c.jobs[size-1] == job {
+		return "+"
+	}
+	if size > 1 && c.jobs[size-2] == job {
+		return "-"
+	}
+	return " "
+}
+
+// Jobs returns the jobs of the session, the ended ones are kept until they are reported
+func (c *TaskManager) Jobs() []interfaces.JobInfo {
+	var out []interfaces.JobInfo
+	now := time.Now()
+	for _, job := range c.jobs {
+		end := now
+		if job.state == interfaces.JobDone {
+			end = job.end
+		}
+		out = append(out, interfaces.JobInfo{
+			Id:      job.id,
+			Pid:     job.pid,
+			State:   job.state,
+			Status:  job.status,
+			Runtime: end.Sub(job.start),
+			Line:    job.line,
+			Current: c.jobMark(job) == "+",
+		})
+	}
+	return out
+}
+
+// FindJob resolves a job specification: %n is the job n, %% or %+ the current job and %- the previous one.
+// An empty specification is the current job.
+func (c *TaskManager) FindJob(spec string) (interfaces.JobInfo, bool) {
+	jobs := c.Jobs()
+	size := len(jobs)
+	switch spec {
+	case "", "%", "%%", "%+":
+		if size > 0 {
+			return jobs[size-1], true
+		}
+	case "%-":
+		if size > 1 {
+			return jobs[size-2], true
+		}
+	default:
+		if !strings.HasPrefix(spec, "%") {
+			break
+		}
+		id, err := strconv.Atoi(spec[1:])
+		if err != nil {
+			break
+		}
+		for _, job := range jobs {
+			if job.Id == id {
+				return job, true
+			}
+		}
+	}
+	return interfaces.JobInfo{}, false
+}
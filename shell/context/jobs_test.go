@@ -0,0 +1,129 @@
+package context
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/markel1974/goshell/shell/cli"
+	"github.com/markel1974/goshell/shell/interfaces"
+)
+
+func TestJobs_FgReplaysTheOutput(t *testing.T) {
+	s := newTestSession(t)
+	if output := s.run("work --tag a &"); !strings.Contains(output, "[1] ") {
+		t.Fatalf("expected the job to be announced, got %q", output)
+	}
+	s.pump(func() bool { return strings.Contains(s.job(1).output.String(), "start a") })
+	if state := s.job(1).state; state != interfaces.JobRunning {
+		t.Fatalf("expected the job running, got %s", state)
+	}
+	if output := s.run("jobs"); !strings.Contains(output, interfaces.JobRunning) || !strings.Contains(output, "work --tag a") {
+		t.Errorf("expected the running job in the list, got %q", output)
+	}
+
+	// the output kept in the background is written once the job is in the foreground
+	if output := s.run("fg %1"); !strings.Contains(output, "start a") {
+		t.Fatalf("expected the kept output, got %q", output)
+	}
+	if !s.job(1).foreground || s.job(1).output.Len() != 0 {
+		t.Fatalf("expected the job in the foreground without kept output")
+	}
+
+	s.out.Reset()
+	close(s.release)
+	// a job ended in the foreground is forgotten
+	s.pump(func() bool { return len(s.ctx.tasks.jobs) == 0 })
+	if output := s.out.String(); !strings.Contains(output, "end a") {
+		t.Errorf("expected the output of the foreground job, got %q", output)
+	}
+}
+
+func TestJobs_StopBgAndWait(t *testing.T) {
+	s := newTestSession(t)
+	s.run("work --tag a --status 4 &")
+	s.pump(func() bool { return strings.Contains(s.job(1).output.String(), "start a") })
+
+	// wait holds the prompt until it is stopped, its job does not run while stopped
+	s.run("wait %1")
+	if _, ok := s.ctx.tasks.StopForeground(); !ok {
+		t.Fatal("expected wait in the foreground")
+	}
+	if state := s.job(2).state; state != interfaces.JobStopped {
+		t.Fatalf("expected wait stopped, got %s", state)
+	}
+	if output := s.run("jobs"); !strings.Contains(output, interfaces.JobStopped) {
+		t.Errorf("expected the stopped job in the list, got %q", output)
+	}
+
+	if output := s.run("bg %2"); !strings.Contains(output, "[2]+ wait %1 &") {
+		t.Errorf("expected the job continued, got %q", output)
+	}
+	if state := s.job(2).state; state != interfaces.JobRunning {
+		t.Fatalf("expected wait running, got %s", state)
+	}
+
+	close(s.release)
+	s.pump(func() bool { return s.job(2).state == interfaces.JobDone })
+	if s.job(1).state != interfaces.JobDone || s.job(1).status != 4 {
+		t.Errorf("expected the first job ended with 4, got %s %d", s.job(1).state, s.job(1).status)
+	}
+	// wait ends with the status of the job it waited for
+	if s.job(2).status != 4 {
+		t.Errorf("expected wait to end with 4, got %d", s.job(2).status)
+	}
+
+	s.out.Reset()
+	s.ctx.tasks.NotifyJobs()
+	if output := s.out.String(); !strings.Contains(output, "end a") || !strings.Contains(output, "Exit 4") {
+		t.Errorf("expected the ended jobs to be reported, got %q", output)
+	}
+	if len(s.ctx.tasks.jobs) != 0 {
+		t.Errorf("expected the reported jobs to be forgotten")
+	}
+}
+
+func TestJobs_WaitInTheForeground(t *testing.T) {
+	s := newTestSession(t)
+	s.run("work --tag a --status 5 &")
+	// without arguments wait is for the running jobs
+	s.run("wait")
+	if s.ctx.tasks.foreground == nil {
+		t.Fatal("expected wait to hold the prompt")
+	}
+	close(s.release)
+	s.pump(func() bool { return s.ctx.tasks.foreground == nil })
+	if s.ctx.tasks.status != 5 {
+		t.Errorf("expected the status of the job, got %d", s.ctx.tasks.status)
+	}
+
+	if output := s.run("wait %9"); !strings.Contains(output, "Unknown job: %9") || s.ctx.tasks.status != cli.ExitNotFound {
+		t.Errorf("expected an unknown job, got %q status %d", output, s.ctx.tasks.status)
+	}
+}
+
+func TestJobs_KillBackground(t *testing.T) {
+	s := newTestSession(t)
+	s.run("work --tag a &")
+	s.pump(func() bool { return strings.Contains(s.job(1).output.String(), "start a") })
+
+	s.run("kill %1")
+	if state := s.job(1).state; state != interfaces.JobDone {
+		t.Fatalf("expected the killed job ended, got %s", state)
+	}
+	if s.job(1).status != cli.ExitInterrupt {
+		t.Errorf("expected the killed job interrupted, got %d", s.job(1).status)
+	}
+	// the job does not run anymore
+	if output := s.run("fg %1"); !strings.Contains(output, "Job has terminated: %1") {
+		t.Errorf("expected the ended job refused, got %q", output)
+	}
+
+	s.out.Reset()
+	s.ctx.tasks.NotifyJobs()
+	if output := s.out.String(); !strings.Contains(output, "Killed") {
+		t.Errorf("expected the killed job to be reported, got %q", output)
+	}
+	if output := s.run("kill %1"); !strings.Contains(output, "Unknown job: %1") {
+		t.Errorf("expected the reported job to be forgotten, got %q", output)
+	}
+}
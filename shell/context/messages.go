@@ -14,13 +14,19 @@
 
 package context
 
+import "github.com/markel1974/goshell/shell/cli"
+
 type MessageType int
 
 const (
-	MessageTypeRead  MessageType = iota
-	MessageTypeTimer MessageType = iota
-	MessageTypePaint MessageType = iota
-	MessageTypeQuit  MessageType = iota
+	MessageTypeRead   MessageType = iota
+	MessageTypeTimer  MessageType = iota
+	MessageTypePaint  MessageType = iota
+	MessageTypeQuit   MessageType = iota
+	MessageTypeOutput MessageType = iota
+	MessageTypeDone   MessageType = iota
+	MessageTypeInput  MessageType = iota
+	MessageTypeCall   MessageType = iota
 )
 
 type iMessage interface {
@@ -87,3 +93,77 @@ func (m *MessagePaint) getType() MessageType {
 func (m *MessagePaint) postEvent(ch chan iMessage) {
 	go func() { ch <- m }()
 }
+
+// MessageOutput carries the output of a command running off the event loop
+type MessageOutput struct {
+	pid    int
+	data   string
+	stderr bool
+}
+
+func newMessageOutput(pid int, data string, stderr bool) *MessageOutput {
+	return &MessageOutput{pid: pid, data: data, stderr: stderr}
+}
+func (m *MessageOutput) getType() MessageType {
+	return MessageTypeOutput
+}
+func (m *MessageOutput) postEvent(ch chan iMessage) {
+	go func() { ch <- m }()
+}
+
+// MessageDone tells the end of a command running off the event loop
+type MessageDone struct {
+	pid int
+	cmd *cli.Command
+}
+
+func newMessageDone(pid int, cmd *cli.Command) *MessageDone {
+	return &MessageDone{pid: pid, cmd: cmd}
+}
+func (m *MessageDone) getType() MessageType {
+	return MessageTypeDone
+}
+func (m *MessageDone) postEvent(ch chan iMessage) {
+	go func() { ch <- m }()
+}
+
+// MessageInput asks a question for a command running off the event loop, the answer is sent to reply
+type MessageInput struct {
+	pid    int
+	text   string
+	prompt string
+	echo   bool
+	reply  chan inputAnswer
+}
+
+type inputAnswer struct {
+	text string
+	err  error
+}
+
+func newMessageInput(pid int, text string, prompt string, echo bool) *MessageInput {
+	return &MessageInput{pid: pid, text: text, prompt: prompt, echo: echo, reply: make(chan inputAnswer, 1)}
+}
+func (m *MessageInput) getType() MessageType {
+	return MessageTypeInput
+}
+func (m *MessageInput) postEvent(ch chan iMessage) {
+	go func() { ch <- m }()
+}
+
+// MessageCall runs a function on the event loop for a command running off it, done is closed when it returns
+type MessageCall struct {
+	pid  int
+	f    func()
+	done chan struct{}
+}
+
+func newMessageCall(pid int, f func()) *MessageCall {
+	return &MessageCall{pid: pid, f: f, done: make(chan struct{})}
+}
+func (m *MessageCall) getType() MessageType {
+	return MessageTypeCall
+}
+func (m *MessageCall) postEvent(ch chan iMessage) {
+	go func() { ch <- m }()
+}
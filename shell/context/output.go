@@ -0,0 +1,128 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"bytes"
+	"github.com/markel1974/goshell/shell/interfaces"
+	"io"
+)
+
+// Output is the destination of everything written by the commands.
+// It writes to the terminal, unless a capture is active: in that case the
+// plain text (without colors) is collected and can be fed to the next pipeline stage.
+// Errors are written through Stderr, they are never captured with the output.
+type Output struct {
+	terminal    interfaces.ITerminal
+	captures    []*bytes.Buffer
+	errCaptures []*bytes.Buffer
+}
+
+func NewOutput(terminal interfaces.ITerminal) *Output {
+	return &Output{
+		terminal: terminal,
+	}
+}
+
+func (o *Output) Write(p []byte) (int, error) {
+	if c := o.current(); c != nil {
+		return c.Write(p)
+	}
+	return o.terminal.Write(string(p))
+}
+
+func (o *Output) WriteString(data string) {
+	_, _ = o.Write([]byte(data))
+}
+
+func (o *Output) WriteColor(data string, fg interfaces.ColorDef, bg interfaces.ColorDef, mode interfaces.ColorMode) {
+	if c := o.current(); c != nil {
+		c.WriteString(data)
+		return
+	}
+	_, _ = o.terminal.WriteColor(data, fg, bg, mode)
+}
+
+// Stderr returns the writer used for the errors
+func (o *Output) Stderr() io.Writer {
+	return &errorOutput{o: o}
+}
+
+// Capture starts collecting the output, captures can be nested.
+func (o *Output) Capture() {
+	o.captures = push(o.captures)
+}
+
+// Release stops the innermost capture and returns what has been collected.
+func (o *Output) Release() string {
+	var data string
+	o.captures, data = pop(o.captures)
+	return data
+}
+
+// CaptureErr starts collecting the errors, captures can be nested.
+func (o *Output) CaptureErr() {
+	o.errCaptures = push(o.errCaptures)
+}
+
+// ReleaseErr stops the innermost errors capture and returns what has been collected.
+func (o *Output) ReleaseErr() string {
+	var data string
+	o.errCaptures, data = pop(o.errCaptures)
+	return data
+}
+
+func (o *Output) IsCapturing() bool {
+	return len(o.captures) > 0
+}
+
+// Captures returns the number of nested captures of the output
+func (o *Output) Captures() int {
+	return len(o.captures)
+}
+
+func (o *Output) current() *bytes.Buffer {
+	return top(o.captures)
+}
+
+type errorOutput struct {
+	o *Output
+}
+
+func (e *errorOutput) Write(p []byte) (int, error) {
+	if c := top(e.o.errCaptures); c != nil {
+		return c.Write(p)
+	}
+	return e.o.terminal.Write(string(p))
+}
+
+func push(stack []*bytes.Buffer) []*bytes.Buffer {
+	return append(stack, &bytes.Buffer{})
+}
+
+func pop(stack []*bytes.Buffer) ([]*bytes.Buffer, string) {
+	size := len(stack)
+	if size == 0 {
+		return stack, ""
+	}
+	return stack[:size-1], stack[size-1].String()
+}
+
+func top(stack []*bytes.Buffer) *bytes.Buffer {
+	if size := len(stack); size > 0 {
+		return stack[size-1]
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+)
+
+const (
+	defaultScriptsDir = "scripts"
+	scriptsExtension  = ".sh"
+	loginScript       = "login"
+)
+
+// Scripts finds the scripts run with source and run: the ones embedded by
+// the host first, then the ones in the scripts directory.
+type Scripts struct {
+	dir      string
+	embedded fs.FS
+}
+
+func NewScripts(dir string) *Scripts {
+	if len(dir) == 0 {
+		dir = defaultScriptsDir
+	}
+	return &Scripts{
+		dir: dir,
+	}
+}
+
+func (s *Scripts) SetDir(dir string) {
+	if len(dir) == 0 {
+		dir = defaultScriptsDir
+	}
+	s.dir = dir
+}
+
+func (s *Scripts) SetEmbedded(fsys fs.FS) {
+	s.embedded = fsys
+}
+
+// Load returns the source of the script, the extension .sh can be omitted
+func (s *Scripts) Load(name string) (string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return "", errors.New("invalid script name: " + name)
+	}
+	for _, fsys := range []fs.FS{s.embedded, os.DirFS(s.dir)} {
+		if fsys == nil {
+			continue
+		}
+		for _, candidate := range []string{name, name + scriptsExtension} {
+			if data, err := fs.ReadFile(fsys, candidate); err == nil {
+				return string(data), nil
+			}
+		}
+	}
+	return "", errors.New("script not found: " + name)
+}
+
+// Login returns the name of the login script of the user: login/<user>, or login for everyone
+func (s *Scripts) Login(user string) (string, bool) {
+	for _, name := range []string{path.Join(loginScript, userDirName(user)), loginScript} {
+		if _, err := s.Load(name); err == nil {
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,122 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Settings are the preferences of a user kept across the sessions
+type Settings struct {
+	Variables map[string]string `json:"variables,omitempty"`
+	Aliases   map[string]string `json:"aliases,omitempty"`
+	Functions map[string]string `json:"functions,omitempty"`
+}
+
+// settingsLocks serialize the updates of a settings file by the sessions of this process
+var settingsLocks = struct {
+	sync.Mutex
+	paths map[string]*sync.Mutex
+}{paths: make(map[string]*sync.Mutex)}
+
+func settingsLock(path string) *sync.Mutex {
+	settingsLocks.Lock()
+	defer settingsLocks.Unlock()
+	l, ok := settingsLocks.paths[path]
+	if !ok {
+		l = &sync.Mutex{}
+		settingsLocks.paths[path] = l
+	}
+	return l
+}
+
+func NewSettings() *Settings {
+	return &Settings{
+		Variables: make(map[string]string),
+		Aliases:   make(map[string]string),
+		Functions: make(map[string]string),
+	}
+}
+
+// LoadSettings reads the settings stored at path, a missing file gives empty settings
+func LoadSettings(path string) (*Settings, error) {
+	s := NewSettings()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	if err = json.Unmarshal(data, s); err != nil {
+		return NewSettings(), err
+	}
+	if s.Variables == nil {
+		s.Variables = make(map[string]string)
+	}
+	if s.Aliases == nil {
+		s.Aliases = make(map[string]string)
+	}
+	if s.Functions == nil {
+		s.Functions = make(map[string]string)
+	}
+	return s, nil
+}
+
+// UpdateSettings applies update to the settings stored at path and saves them. The file is read
+// again before the update, the changes saved by the other sessions of the user are kept.
+func UpdateSettings(path string, update func(s *Settings)) error {
+	l := settingsLock(path)
+	l.Lock()
+	defer l.Unlock()
+
+	s, err := LoadSettings(path)
+	if err != nil {
+		return err
+	}
+	update(s)
+	return s.Save(path)
+}
+
+// Save replaces the file at path with the settings, through a temporary file of the same directory
+func (s *Settings) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
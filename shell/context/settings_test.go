@@ -0,0 +1,142 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSettings_UpdateKeepsOtherSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".settings", "bob.json")
+
+	// two sessions of the same user, each changing its own names
+	if err := UpdateSettings(path, func(s *Settings) { s.Aliases["mem"] = "stats memory" }); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateSettings(path, func(s *Settings) { s.Variables["PID"] = "12" }); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateSettings(path, func(s *Settings) { delete(s.Aliases, "missing") }); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Aliases["mem"] != "stats memory" || s.Variables["PID"] != "12" {
+		t.Errorf("expected the changes of both sessions, got %v %v", s.Aliases, s.Variables)
+	}
+}
+
+func TestSettings_ConcurrentUpdates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bob.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("V%d", i)
+			if err := UpdateSettings(path, func(s *Settings) { s.Variables[name] = name }); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Variables) != 20 {
+		t.Errorf("expected 20 variables, got %v", s.Variables)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the settings file, got %d files", len(entries))
+	}
+}
+
+func TestSettings_SessionsOfTheSameUser(t *testing.T) {
+	dir := t.TempDir()
+	a := NewContext(nil, nil, nil, nil, nil, nil, "", false)
+	b := NewContext(nil, nil, nil, nil, nil, nil, "", false)
+	for _, c := range []*Context{a, b} {
+		c.SetDataDir(dir)
+		c.SetUser("bob")
+	}
+	if err := a.SetAlias("mem", "stats memory"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SetVar("X", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.ExportVar("X"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SetFunction("f", "echo f"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.UnsetAlias("mem"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSettings(a.storage.SettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Aliases) != 0 || s.Variables["X"] != "1" || s.Functions["f"] != "echo f" {
+		t.Errorf("unexpected settings %v %v %v", s.Aliases, s.Variables, s.Functions)
+	}
+}
+
+func TestSettings_SetUserDropsThePreviousUser(t *testing.T) {
+	dir := t.TempDir()
+	c := NewContext(nil, nil, nil, nil, nil, nil, "", false)
+	c.SetDataDir(dir)
+	c.SetUser("alice")
+	if err := c.SetVar("A", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ExportVar("A"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetAlias("a", "echo a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetFunction("fa", "echo a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the session logs in again as another user, like after the handshake user of a ssh session
+	c.SetUser("bob")
+	if _, ok := c.vars.Lookup("A"); ok {
+		t.Error("expected the variables of alice to be dropped")
+	}
+	if len(c.ListAliases()) != 0 || len(c.ListFunctions()) != 0 {
+		t.Errorf("expected the shortcuts of alice to be dropped, got %v %v", c.ListAliases(), c.ListFunctions())
+	}
+	if err := c.SetAlias("b", "echo b"); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadSettings(c.storage.SettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Variables) != 0 || len(s.Functions) != 0 || len(s.Aliases) != 1 {
+		t.Errorf("expected only the alias of bob in the settings of bob, got %v %v %v", s.Variables, s.Aliases, s.Functions)
+	}
+
+	// the settings of alice come back at the next login
+	c.SetUser("alice")
+	if value, _ := c.vars.Lookup("A"); value != "1" || len(c.ListAliases()) != 1 {
+		t.Errorf("expected the settings of alice, got %q %v", value, c.ListAliases())
+	}
+}
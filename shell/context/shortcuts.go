@@ -0,0 +1,150 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"errors"
+	"github.com/markel1974/goshell/shell/cli"
+	"sort"
+)
+
+// Shortcuts are the aliases and the functions defined by the user. They are
+// resolved before the command tree and saved with the settings of the user.
+type Shortcuts struct {
+	aliases   map[string]string
+	functions map[string]string
+}
+
+func NewShortcuts() *Shortcuts {
+	return &Shortcuts{
+		aliases:   make(map[string]string),
+		functions: make(map[string]string),
+	}
+}
+
+// Clear removes the aliases and the functions
+func (s *Shortcuts) Clear() {
+	s.aliases = make(map[string]string)
+	s.functions = make(map[string]string)
+}
+
+func (s *Shortcuts) SetAlias(name string, value string) error {
+	if !isShortcutName(name) {
+		return errors.New("invalid alias name: " + name)
+	}
+	s.aliases[name] = value
+	return nil
+}
+
+func (s *Shortcuts) Alias(name string) (string, bool) {
+	value, ok := s.aliases[name]
+	return value, ok
+}
+
+func (s *Shortcuts) UnsetAlias(name string) bool {
+	if _, ok := s.aliases[name]; !ok {
+		return false
+	}
+	delete(s.aliases, name)
+	return true
+}
+
+func (s *Shortcuts) SetFunction(name string, body string) error {
+	if !isShortcutName(name) {
+		return errors.New("invalid function name: " + name)
+	}
+	s.functions[name] = body
+	return nil
+}
+
+func (s *Shortcuts) Function(name string) (string, bool) {
+	body, ok := s.functions[name]
+	return body, ok
+}
+
+func (s *Shortcuts) UnsetFunction(name string) bool {
+	if _, ok := s.functions[name]; !ok {
+		return false
+	}
+	delete(s.functions, name)
+	return true
+}
+
+// Aliases returns a copy of the aliases
+func (s *Shortcuts) Aliases() map[string]string {
+	return copyMap(s.aliases)
+}
+
+// Functions returns a copy of the functions
+func (s *Shortcuts) Functions() map[string]string {
+	return copyMap(s.functions)
+}
+
+// Names returns the sorted names of the aliases and the functions
+func (s *Shortcuts) Names() []string {
+	var out []string
+	for name := range s.aliases {
+		out = append(out, name)
+	}
+	for name := range s.functions {
+		if _, ok := s.aliases[name]; !ok {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// UserCommands describes the aliases and the functions for the help
+func (s *Shortcuts) UserCommands() []cli.UserCommand {
+	var out []cli.UserCommand
+	for _, name := range s.Names() {
+		if value, ok := s.aliases[name]; ok {
+			out = append(out, cli.UserCommand{Name: name, Short: "alias for " + value})
+		} else {
+			out = append(out, cli.UserCommand{Name: name, Short: "function: " + s.functions[name]})
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]string) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func copyMap(src map[string]string) map[string]string {
+	out := make(map[string]string)
+	for k, v := range src {
+		out[k] = v
+	}
+	return out
+}
+
+func isShortcutName(name string) bool {
+	if len(name) == 0 || name[0] == '-' {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultDataDir  = "data"
+	anonymousUser   = "anonymous"
+	settingsDirName = ".settings"
+	historyDirName  = ".history"
+)
+
+var errPathOutside = errors.New("path outside of the user data directory")
+
+// Storage confines the files of a session in the data directory of its user.
+// Every name is relative to <dir>/<user>, absolute paths and any attempt to
+// leave that directory, also through symbolic links, are rejected.
+type Storage struct {
+	dir        string
+	user       string
+	historyDir string
+}
+
+func NewStorage(dir string) *Storage {
+	if len(dir) == 0 {
+		dir = defaultDataDir
+	}
+	return &Storage{
+		dir: dir,
+	}
+}
+
+func (s *Storage) SetDir(dir string) {
+	if len(dir) == 0 {
+		dir = defaultDataDir
+	}
+	s.dir = dir
+}
+
+func (s *Storage) SetUser(user string) {
+	s.user = user
+}
+
+func (s *Storage) User() string {
+	return s.user
+}
+
+// SettingsPath returns the file holding the settings of the user, it is
+// kept out of the data directory of the user so redirections can't reach it.
+func (s *Storage) SettingsPath() string {
+	return filepath.Join(s.dir, settingsDirName, userDirName(s.user)+".json")
+}
+
+// SetHistoryDir sets the directory of the history files, <dir>/.history when empty
+func (s *Storage) SetHistoryDir(dir string) {
+	s.historyDir = dir
+}
+
+// HistoryPath returns the file holding the history of the user, like the
+// settings it is kept out of the data directory of the user.
+func (s *Storage) HistoryPath() string {
+	dir := s.historyDir
+	if len(dir) == 0 {
+		dir = filepath.Join(s.dir, historyDirName)
+	}
+	return filepath.Join(dir, userDirName(s.user)+".jsonl")
+}
+
+// Root returns the data directory of the user
+func (s *Storage) Root() string {
+	return filepath.Join(s.dir, userDirName(s.user))
+}
+
+// Resolve returns the path of name inside the data directory of the user
+func (s *Storage) Resolve(name string) (string, error) {
+	if len(name) == 0 {
+		return "", errors.New("empty file name")
+	}
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", errPathOutside
+	}
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errPathOutside
+	}
+
+	root := s.Root()
+	path := filepath.Join(root, clean)
+
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return "", errPathOutside
+	}
+	if !resolvesWithin(root, filepath.Dir(path)) {
+		return "", errPathOutside
+	}
+
+	return path, nil
+}
+
+// resolvesWithin reports if the nearest existing directory of dir, the one the writes create
+// dir in, stays in root through the symbolic links. Nothing can leave root when it does not exist yet.
+func resolvesWithin(root string, dir string) bool {
+	for {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			rootPath, err := filepath.EvalSymlinks(root)
+			return err == nil && isWithin(rootPath, real)
+		}
+		if _, err := os.Lstat(dir); !os.IsNotExist(err) {
+			// a link to nowhere or a directory that can't be read
+			return false
+		}
+		if dir == root || dir == filepath.Dir(dir) {
+			return true
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func (s *Storage) WriteFile(name string, data string, append bool) error {
+	path, err := s.Resolve(name)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if append {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flags, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(data)
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+func (s *Storage) ReadFile(name string) ([]byte, error) {
+	path, err := s.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+func isWithin(root string, path string) bool {
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+// userDirName maps a user name to a safe directory name, never starting with '.'. The other bytes
+// are escaped as %XX, like the '%' itself, so two users never share a directory.
+func userDirName(user string) string {
+	if len(user) == 0 {
+		return anonymousUser
+	}
+	var b strings.Builder
+	for i := 0; i < len(user); i++ {
+		c := user[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || (c == '.' && i > 0) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStorage_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir)
+	s.SetUser("bob")
+
+	for _, name := range []string{"", "/etc/passwd", "..", "../alice/x", "a/../../x", "./../x"} {
+		if _, err := s.Resolve(name); err == nil {
+			t.Errorf("Resolve(%q) expected an error", name)
+		}
+	}
+
+	path, err := s.Resolve("a/../mem.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(dir, "bob", "mem.txt"); path != expected {
+		t.Errorf("expected %q, got %q", expected, path)
+	}
+}
+
+func TestStorage_ResolveSymlink(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir)
+	s.SetUser("bob")
+
+	if err := os.MkdirAll(s.Root(), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(t.TempDir(), filepath.Join(s.Root(), "out")); err != nil {
+		t.Skip(err)
+	}
+	if _, err := s.Resolve("out/x"); err == nil {
+		t.Error("expected an error for a file behind a symbolic link")
+	}
+	if _, err := s.Resolve("out"); err == nil {
+		t.Error("expected an error for a symbolic link")
+	}
+	if _, err := s.Resolve("out/new/x"); err == nil {
+		t.Error("expected an error for a directory to create behind a symbolic link")
+	}
+	if err := os.Symlink(filepath.Join(dir, "nowhere"), filepath.Join(s.Root(), "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Resolve("dangling/x"); err == nil {
+		t.Error("expected an error for a file behind a dangling symbolic link")
+	}
+	if _, err := s.Resolve("in/new/x"); err != nil {
+		t.Errorf("expected a directory to create in the user directory, got %v", err)
+	}
+}
+
+func TestUserDirName(t *testing.T) {
+	tests := map[string]string{
+		"":              "anonymous",
+		"bob":           "bob",
+		"bob.smith-2_x": "bob.smith-2_x",
+		".bob":          "%2Ebob",
+		"a/b":           "a%2Fb",
+		"a_b":           "a_b",
+		"a%2Fb":         "a%252Fb",
+		"è":             "%C3%A8",
+	}
+	for user, expected := range tests {
+		if name := userDirName(user); name != expected {
+			t.Errorf("userDirName(%q): expected %q, got %q", user, expected, name)
+		}
+	}
+}
+
+func TestStorage_WriteFile(t *testing.T) {
+	s := NewStorage(t.TempDir())
+	s.SetUser("../bob")
+
+	if err := s.WriteFile("audit.txt", "a\n", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteFile("audit.txt", "b\n", true); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.ReadFile("audit.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a\nb\n" {
+		t.Errorf("unexpected content %q", string(data))
+	}
+	if filepath.Base(s.Root()) != "%2E.%2Fbob" {
+		t.Errorf("unexpected user directory %q", s.Root())
+	}
+}
+
+func TestStorage_HistoryPath(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir)
+	s.SetUser("../bob")
+
+	if expected := filepath.Join(dir, ".history", "%2E.%2Fbob.jsonl"); s.HistoryPath() != expected {
+		t.Errorf("expected %q, got %q", expected, s.HistoryPath())
+	}
+	if _, err := s.Resolve("../.history/%2E.%2Fbob.jsonl"); err == nil {
+		t.Error("expected the history out of the user data directory")
+	}
+
+	s.SetHistoryDir("/var/lib/goshell")
+	if expected := filepath.Join("/var/lib/goshell", "%2E.%2Fbob.jsonl"); s.HistoryPath() != expected {
+		t.Errorf("expected %q, got %q", expected, s.HistoryPath())
+	}
+}
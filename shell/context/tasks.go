@@ -15,12 +15,15 @@
 package context
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/markel1974/goshell/shell/adaptiveticker"
+	"github.com/markel1974/goshell/shell/apps/pager"
 	"github.com/markel1974/goshell/shell/cli"
 	"github.com/markel1974/goshell/shell/interfaces"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -37,9 +40,17 @@ const (
 
 const tasksFileExtension = ".task"
 
+const (
+	maxSubstitutionDepth = 8
+	maxSubstitutionSize  = 64 * 1024
+	maxCallDepth         = 32
+)
+
 const (
 	commandActivate = "activate"
 	commandTask     = "task"
+	// pagerCommand shows the output of the paged commands longer than the screen
+	pagerCommand = "more"
 )
 
 type Task struct {
@@ -53,6 +64,16 @@ type Task struct {
 	OffsetX int
 	OffsetY int
 	Scale   float64
+	// async is set when the command runs off the event loop, cancel interrupts it
+	async  bool
+	cancel context.CancelFunc
+	// rest is the part of the command line waiting for the task
+	rest     []*cli.Pipeline
+	spinner  int
+	spinning bool
+	asking   bool
+	// job is set when the task runs as a job
+	job *Job
 }
 
 func NewTask(cmd *cli.Command, line string) *Task {
@@ -112,49 +133,352 @@ type TaskManager struct {
 	foreground *Task
 	selector   *TaskSelector
 	root       *cli.Command
-	path       string
 	dirty      bool
 	width      int
 	height     int
 	fullPaint  bool
 	timersChan chan *adaptiveticker.TimerHandler
 	ids        *adaptiveticker.Ids
+	output     *Output
+	status     int
+	storage    *Storage
+	buffers    *Buffers
+	depth      int
+	lastPid    int
+	shortcuts  *Shortcuts
+	expanding  map[string]bool
+	params     [][]string
+	// foregroundExit is called when the foreground task ends, with the rest of the line waiting for it
+	foregroundExit func(rest []*cli.Pipeline)
+	// messageChan delivers the output of the commands running off the event loop, until closed
+	messageChan chan iMessage
+	closed      chan struct{}
+	// interactive is set while a line typed at the prompt runs, stages counts the stages running
+	interactive bool
+	stages      int
+	// asyncPid is the task allowed to run off the event loop, waiting the one started by the last stage
+	asyncPid int
+	waiting  *Task
+	// asyncCmds are the commands running off the event loop and their pid, until their goroutine returns.
+	// A command has a single set of flags, writers and exit status, it runs once at a time.
+	asyncCmds map[*cli.Command]int
+	// jobs are the jobs of the session, starting the one whose pipeline is being started
+	jobs     []*Job
+	starting *Job
 }
 
-func NewTaskManager(ticker *adaptiveticker.AdaptiveTicker, timersChannel chan *adaptiveticker.TimerHandler, root *cli.Command) *TaskManager {
+func NewTaskManager(ticker *adaptiveticker.AdaptiveTicker, timersChannel chan *adaptiveticker.TimerHandler, messageChannel chan iMessage, root *cli.Command, output *Output, storage *Storage, buffers *Buffers, shortcuts *Shortcuts) *TaskManager {
 	t := &TaskManager{
-		ticker:     ticker,
-		foreground: nil,
-		selector:   NewTaskSelector(),
-		timersChan: timersChannel,
-		root:       root,
-		dirty:      false,
-		fullPaint:  true,
-		width:      80,
-		height:     24,
-		ids:        adaptiveticker.NewIds(1024),
+		messageChan: messageChannel,
+		closed:      make(chan struct{}),
+		asyncPid:    adaptiveticker.UnknownId,
+		ticker:      ticker,
+		output:      output,
+		status:      cli.ExitOK,
+		storage:     storage,
+		buffers:     buffers,
+		lastPid:     adaptiveticker.UnknownId,
+		shortcuts:   shortcuts,
+		expanding:   make(map[string]bool),
+		asyncCmds:   make(map[*cli.Command]int),
+		foreground:  nil,
+		selector:    NewTaskSelector(),
+		timersChan:  timersChannel,
+		root:        root,
+		dirty:       false,
+		fullPaint:   true,
+		width:       80,
+		height:      24,
+		ids:         adaptiveticker.NewIds(1024),
 	}
 
+	root.SetSubstituteFunc(t.substitute)
+	root.SetUserCommandsFunc(shortcuts.UserCommands)
+	root.SetRunContextFunc(t.runContext)
+
 	return t
 }
 
-func (c *TaskManager) Execute(line string, template *Task) bool {
-	if !c.root.Parse(line) {
-		return false
+// Execute runs a command line and returns its exit status, the status of the last pipeline run.
+// Pipelines joined by '&&' or '||' run only if the previous status is respectively zero or non-zero.
+func (c *TaskManager) Execute(line string, template *Task) int {
+	pipelines, err := c.root.ParseLine(line)
+	if err != nil {
+		c.root.PrintErrf(cli.DefaultEol+"Error %s"+cli.DefaultEol, err.Error())
+		c.status = cli.ExitUsage
+		return c.status
+	}
+
+	return c.executePipelines(pipelines, template, nil)
+}
+
+// executePipelines runs the pipelines of a command line, input feeds the first one.
+func (c *TaskManager) executePipelines(pipelines []*cli.Pipeline, template *Task, input io.Reader) int {
+	for i, pipeline := range pipelines {
+		switch pipeline.Op {
+		case cli.OpAnd:
+			if c.status != cli.ExitOK {
+				continue
+			}
+		case cli.OpOr:
+			if c.status == cli.ExitOK {
+				continue
+			}
+		}
+		if i > 0 {
+			input = nil
+		}
+		if pipeline.Background {
+			c.status = c.executeJob(pipeline, template, input)
+			continue
+		}
+		c.status = c.executePipeline(pipeline.Stages, template, input)
+		if task := c.waiting; task != nil {
+			c.waiting = nil
+			task.rest = pipelines[i+1:]
+			return c.status
+		}
 	}
 
+	return c.status
+}
+
+// substitute runs the command line of a command substitution and returns its output.
+// Nested substitutions are limited to maxSubstitutionDepth, the output to maxSubstitutionSize.
+func (c *TaskManager) substitute(line string) (string, error) {
+	if c.depth >= maxSubstitutionDepth {
+		err := errors.New("command substitution nested too deeply")
+		c.root.PrintErrf(cli.DefaultEol+"Error %s"+cli.DefaultEol, err.Error())
+		return "", err
+	}
+	c.depth++
+	c.output.Capture()
+	c.Execute(line, nil)
+	data := c.output.Release()
+	c.depth--
+
+	if len(data) > maxSubstitutionSize {
+		err := fmt.Errorf("command substitution output exceeds %d bytes", maxSubstitutionSize)
+		c.root.PrintErrf(cli.DefaultEol+"Error %s"+cli.DefaultEol, err.Error())
+		return "", err
+	}
+	return pipeText(data), nil
+}
+
+// GetLastPid returns the pid of the last task started and still running
+func (c *TaskManager) GetLastPid() int {
+	if c.lastPid != adaptiveticker.UnknownId && !c.IsActive(c.lastPid) {
+		return adaptiveticker.UnknownId
+	}
+	return c.lastPid
+}
+
+// GetStatus returns the exit status of the last pipeline run
+func (c *TaskManager) GetStatus() int {
+	return c.status
+}
+
+// executePipeline runs the stages in order, the output of each stage is
+// captured and becomes the input of the next one. The last stage writes to the terminal.
+// The exit status is the one of the last stage.
+func (c *TaskManager) executePipeline(stages []*cli.Stage, template *Task, input io.Reader) int {
+	if len(stages) == 1 {
+		return c.executeStage(stages[0], template, input)
+	}
+
+	status := cli.ExitOK
+
+	for i, stage := range stages {
+		last := i == len(stages)-1
+		if !last {
+			c.output.Capture()
+		}
+		status = c.executeStage(stage, nil, input)
+		if !last {
+			input = strings.NewReader(pipeText(c.output.Release()))
+		}
+	}
+
+	return status
+}
+
+func (c *TaskManager) executeStage(stage *cli.Stage, template *Task, input io.Reader) int {
+	if len(stage.Redirects) > 0 {
+		return c.executeRedirected(stage, template, input)
+	}
+	return c.dispatch(stage, template, input)
+}
+
+// dispatch runs a stage resolving the aliases and the functions before the command tree.
+// An alias is not expanded again while it is being expanded, so it can call the command it hides.
+func (c *TaskManager) dispatch(stage *cli.Stage, template *Task, input io.Reader) int {
+	if len(stage.Args) > 0 {
+		name := stage.Args[0]
+		if value, ok := c.shortcuts.Alias(name); ok && !c.expanding[name] {
+			return c.executeAlias(name, value, stage, template, input)
+		}
+		if body, ok := c.shortcuts.Function(name); ok {
+			return c.executeFunction(body, stage, template, input)
+		}
+	}
+	return c.runStage(stage, template, input)
+}
+
+func (c *TaskManager) executeAlias(name string, value string, stage *cli.Stage, template *Task, input io.Reader) int {
+	line := strings.TrimLeft(stage.Line, " \t")
+	if idx := strings.IndexAny(line, " \t"); idx >= 0 {
+		line = value + line[idx:]
+	} else {
+		line = value
+	}
+
+	pipelines, err := c.root.ParseLine(line)
+	if err != nil {
+		c.root.PrintErrf(cli.DefaultEol+"Error %s: %s"+cli.DefaultEol, name, err.Error())
+		return cli.ExitUsage
+	}
+
+	c.expanding[name] = true
+	defer delete(c.expanding, name)
+
+	return c.executePipelines(pipelines, template, input)
+}
+
+// executeFunction runs the body of a function, its arguments are available as $1, $2... $# and $@
+func (c *TaskManager) executeFunction(body string, stage *cli.Stage, template *Task, input io.Reader) int {
+	if len(c.params) >= maxCallDepth {
+		c.root.PrintErrf(cli.DefaultEol+"Error %s: function calls nested too deeply"+cli.DefaultEol, stage.Args[0])
+		return cli.ExitFailure
+	}
+
+	args, err := c.root.ParseArgs(stage.Line)
+	if err != nil {
+		return cli.ExitUsage
+	}
+
+	pipelines, err := c.root.ParseLine(body)
+	if err != nil {
+		c.root.PrintErrf(cli.DefaultEol+"Error %s: %s"+cli.DefaultEol, stage.Args[0], err.Error())
+		return cli.ExitUsage
+	}
+
+	c.params = append(c.params, args)
+	defer func() { c.params = c.params[:len(c.params)-1] }()
+
+	return c.executePipelines(pipelines, template, input)
+}
+
+// GetParam returns a parameter of the running function: $0 is its name, $1... the arguments
+func (c *TaskManager) GetParam(name string) (string, bool) {
+	if len(c.params) == 0 {
+		return "", false
+	}
+	args := c.params[len(c.params)-1]
+	switch name {
+	case "#":
+		return strconv.Itoa(len(args) - 1), true
+	case "@":
+		return strings.Join(args[1:], " "), true
+	}
+	idx, err := strconv.Atoi(name)
+	if err != nil || idx < 0 {
+		return "", false
+	}
+	if idx < len(args) {
+		return args[idx], true
+	}
+	return "", true
+}
+
+// executeRedirected runs a stage capturing the redirected outputs, then writes them
+// to their targets. When an output is redirected more than once the last target wins.
+func (c *TaskManager) executeRedirected(stage *cli.Stage, template *Task, input io.Reader) int {
+	targets := make(map[int]*cli.Redirect)
+	for _, redirect := range stage.Redirects {
+		redirect.Target = c.root.ExpandEnv(redirect.Target)
+		if err := c.checkTarget(redirect.Target); err != nil {
+			c.root.PrintErrf(cli.DefaultEol+"Error %s: %s"+cli.DefaultEol, redirect.Target, err.Error())
+			return cli.ExitFailure
+		}
+		targets[redirect.Fd] = redirect
+	}
+
+	if targets[1] != nil {
+		c.output.Capture()
+	}
+	if targets[2] != nil {
+		c.output.CaptureErr()
+	}
+
+	status := c.runStage(stage, template, input)
+
+	data := make(map[int]string)
+	if targets[2] != nil {
+		data[2] = c.output.ReleaseErr()
+	}
+	if targets[1] != nil {
+		data[1] = c.output.Release()
+	}
+
+	for _, fd := range []int{1, 2} {
+		redirect := targets[fd]
+		if redirect == nil {
+			continue
+		}
+		if err := c.writeTarget(redirect.Target, pipeText(data[fd]), redirect.Append); err != nil {
+			c.root.PrintErrf(cli.DefaultEol+"Error %s: %s"+cli.DefaultEol, redirect.Target, err.Error())
+			status = cli.ExitFailure
+		}
+	}
+
+	return status
+}
+
+// checkTarget validates a redirection target before running the command
+func (c *TaskManager) checkTarget(target string) error {
+	if strings.HasPrefix(target, BufferPrefix) {
+		if !isBufferName(target[len(BufferPrefix):]) {
+			return errors.New("invalid buffer name")
+		}
+		return nil
+	}
+	_, err := c.storage.Resolve(target)
+	return err
+}
+
+func (c *TaskManager) writeTarget(target string, data string, append bool) error {
+	if strings.HasPrefix(target, BufferPrefix) {
+		return c.buffers.Write(target[len(BufferPrefix):], data, append)
+	}
+	return c.storage.WriteFile(target, data, append)
+}
+
+func (c *TaskManager) runStage(stage *cli.Stage, template *Task, input io.Reader) int {
+	args, err := c.root.ParseArgs(stage.Line)
+	if err != nil {
+		c.root.PrintErrf(cli.DefaultEol+"Error %s"+cli.DefaultEol, err.Error())
+		return cli.ExitUsage
+	}
+	c.root.SetArgs(args)
+
 	pCmd, flags, err := c.root.Prepare()
 	if err != nil {
-		return false
+		return cli.ExitNotFound
 	}
 
 	if pCmd == nil {
-		return false
+		return cli.ExitNotFound
 	}
 
-	task, err := c.create(pCmd, line)
+	// parsing the flags again would change the ones of the running command
+	if pid, ok := c.asyncCmds[pCmd]; ok {
+		c.root.PrintErrf(cli.DefaultEol+"Error %s is already running as task %d"+cli.DefaultEol, pCmd.CommandPath(), pid)
+		return cli.ExitFailure
+	}
+
+	task, err := c.create(pCmd, stage.Line)
 	if err != nil {
-		return false
+		return cli.ExitFailure
 	}
 
 	if template != nil {
@@ -163,20 +487,89 @@ func (c *TaskManager) Execute(line string, template *Task) bool {
 		task.Scale = template.Scale
 	}
 
-	if err = c.root.Execute(task.cmd, flags, task.pid); err == nil {
+	if input != nil {
+		task.cmd.SetIn(input)
+		defer task.cmd.SetIn(nil)
+	}
+
+	task.cmd.SetExitCode(cli.ExitOK)
+
+	// a paged command writing to the terminal shows a long output in the pager
+	paged := task.cmd.Paged && !task.cmd.Activate && !c.output.IsCapturing()
+	if paged {
+		c.output.Capture()
+	}
+
+	// the rest of a line typed at the prompt waits for the foreground task
+	wait := c.canRunAsync(input != nil)
+	c.asyncPid = adaptiveticker.UnknownId
+	if wait {
+		c.asyncPid = task.pid
+	}
+	c.interactive = false
+	c.stages++
+	err = c.root.Execute(task.cmd, flags, task.pid)
+	c.stages--
+
+	// an activated task can end within its Run
+	if err == nil && c.IsActive(task.pid) {
 		if task.cmd.Activate {
 			if !task.cmd.Background {
 				c.foreground = task
+				if wait {
+					c.waiting = task
+				}
 			}
 			task.state = taskStateRunning
+			c.lastPid = task.pid
 		}
 	}
 
+	// the task started by a job, or by a background command, keeps running as a job
+	if job := c.starting; job != nil && c.stages == job.stages {
+		job.pid = task.pid
+		if task.state == taskStateRunning {
+			c.attachJob(job, task)
+		}
+	} else if task.state == taskStateRunning && task.cmd.Background && task.job == nil {
+		job := c.newJob(task.Line)
+		c.attachJob(job, task)
+		c.output.WriteString(fmt.Sprintf("%s[%d] %d%s", cli.DefaultEol, job.id, job.pid, cli.DefaultEol))
+	}
+
+	// the status of a command running off the event loop is known when it returns
+	status := cli.ExitOK
+	if !task.async {
+		status = task.cmd.ExitCode()
+	}
+	if err != nil && status == cli.ExitOK {
+		status = cli.ExitFailure
+	}
+
 	if task.state == taskStateSetup {
 		c.Kill(task.pid)
 	}
 
-	return true
+	if paged {
+		c.page(c.output.Release())
+	}
+
+	return status
+}
+
+// page writes the output of a paged command, through the pager command when it does not fit the screen.
+// The line of the command and the next prompt stay visible.
+func (c *TaskManager) page(data string) {
+	text := pipeText(data)
+	if pager.Fits(text, c.width, c.height-1) {
+		c.output.WriteString(data)
+		return
+	}
+	if _, err := c.root.Lookup([]string{pagerCommand}); err != nil {
+		c.output.WriteString(data)
+		return
+	}
+	c.runStage(&cli.Stage{Line: pagerCommand, Args: []string{pagerCommand}}, nil, strings.NewReader(text))
 }
 
 func (c *TaskManager) create(cmd *cli.Command, line string) (*Task, error) {
@@ -280,37 +673,22 @@ func (c *TaskManager) SetSelectionDisabled() {
 	c.PaintRequest()
 }
 
-func (c *TaskManager) SetBasePath(arg string) bool {
-	c.path = arg
-
-	/*
-		var cmd * cli.Command
-
-		arg = strings.Trim(arg, " ")
-
-		if arg == ".." {
-			if c.cmd.Parent() != nil {
-				cmd = c.cmd.Parent()
-			}
-		} else {
-			path := strings.Split(arg, "/")
-			if len(path) > 0 {
-				var e error
-				fmt.Println("TRAVERSING", path)
-				if cmd, _, e = c.cmd.Traverse(path); e != nil {
-					cmd = nil
-				} else {
-					fmt.Println("NEW CMD", cmd)
-				}
-			}
-		}
-
-		if cmd != nil {
-			c.cmd = cmd
-		}
-	*/
+// GetBasePath returns the current position in the command tree
+func (c *TaskManager) GetBasePath() string {
+	return c.root.Base().Path()
+}
 
-	return true
+// SetBasePath moves the current position in the command tree, arg is absolute or relative to it
+func (c *TaskManager) SetBasePath(arg string) error {
+	cmd, err := c.root.Base().Resolve(arg)
+	if err != nil {
+		return err
+	}
+	if cmd.HasParent() && !cmd.HasAvailableSubCommands() {
+		return fmt.Errorf("%s: not a command group", arg)
+	}
+	c.root.SetBase(cmd)
+	return nil
 }
 
 func (c *TaskManager) SetSelectionOptions(option rune, value float64) bool {
@@ -365,36 +743,35 @@ func (c *TaskManager) SetFg(pid int) bool {
 		return false
 	}
 	task := t.(*Task)
+	if job := task.job; job != nil {
+		job.foreground = true
+		c.output.WriteString(job.output.String())
+		job.output.Reset()
+		c.continueJob(job)
+	}
 	c.foreground = task
 	return true
 }
 
-func (c *TaskManager) GetSuggestion(in string, _ int) (string, []string, bool) {
-	var data string
-	var cmd *cli.Command = nil
-
-	args := strings.Split(in, " ")
-	if len(args) == 1 {
-		data = args[0]
-		cmd = c.root
-	} else if len(args) > 1 {
-		var e error
-		data = args[len(args)-1]
-		args = args[:len(args)-1]
+// Highlight returns the spans showing the commands, the flags and the operators of a command line being typed
+func (c *TaskManager) Highlight(line string) []cli.Span {
+	return c.root.Highlight(line, c.shortcuts.Alias)
+}
 
-		if cmd, _, e = c.root.Traverse(args); e != nil {
-			cmd = nil
-		}
-		if cmd == c.root {
-			cmd = nil
-		}
+// GetSuggestion returns the word being completed at the end of in and its candidates.
+// An alias in command position is expanded, so its arguments complete like the aliased command.
+// The target of a redirection has no candidates.
+func (c *TaskManager) GetSuggestion(in string) (string, []cli.Completion) {
+	args, data, target := completionWords(in)
+	if target {
+		return data, nil
 	}
-
-	if cmd == nil {
-		return data, nil, false
+	if len(args) > 0 {
+		if value, ok := c.shortcuts.Alias(args[0]); ok {
+			args = append(strings.Fields(value), args[1:]...)
+		}
 	}
-
-	return data, cmd.SuggestionsFor(data), true
+	return data, c.root.Complete(args, data)
 }
 
 func (c *TaskManager) PaintRequest() bool {
@@ -471,6 +848,11 @@ func (c *TaskManager) SetBackground() bool {
 
 func (c *TaskManager) KillForeground() {
 	if c.foreground != nil {
+		if !c.foreground.async {
+			c.foreground.cmd.SetExitCode(cli.ExitInterrupt)
+		}
+		// the rest of the line is interrupted with the task
+		c.foreground.rest = nil
 		c.Kill(c.foreground.pid)
 	}
 }
@@ -487,17 +869,42 @@ func (c *TaskManager) Kill(pid int) bool {
 		c.ticker.Remove(task.timers)
 	}
 
-	if c.foreground != nil {
-		if c.foreground.pid == pid {
-			c.foreground = nil
+	status := task.cmd.ExitCode()
+	if task.cancel != nil {
+		task.cancel()
+		if task.async {
+			c.eraseSpinner(task)
+			status = cli.ExitInterrupt
 		}
 	}
 
+	if task.job != nil {
+		c.endJob(task.job, status)
+	}
+
 	c.ids.Unset(pid)
 
+	if c.foreground == task {
+		c.foreground = nil
+		c.status = status
+		if c.foregroundExit != nil {
+			c.foregroundExit(task.rest)
+		}
+	}
+
 	return true
 }
 
+// SetForegroundExitFunc sets the function called when the foreground task ends
+func (c *TaskManager) SetForegroundExitFunc(f func(rest []*cli.Pipeline)) {
+	c.foregroundExit = f
+}
+
+// HoldsPrompt reports if a foreground task runs, the prompt is shown again when it ends.
+func (c *TaskManager) HoldsPrompt() bool {
+	return c.foreground != nil
+}
+
 func (c *TaskManager) KillAll(name string) int {
 	count := 0
 	var tasks []*Task
@@ -527,12 +934,13 @@ func (c *TaskManager) KillAll(name string) int {
 	return count
 }
 
-func (c *TaskManager) List() string {
-	out := "\r\nPid: Task"
+// Processes returns the tasks of the session
+func (c *TaskManager) Processes() []interfaces.ProcessInfo {
+	var out []interfaces.ProcessInfo
 	for _, e := range c.ids.All() {
 		task, ok := e.(*Task)
 		if ok && task != nil {
-			out += fmt.Sprintf("\r\n%d: %s", task.pid, task.cmd.Name())
+			out = append(out, interfaces.ProcessInfo{Pid: task.pid, Name: task.cmd.Name(), Line: task.Line})
 		}
 	}
 	return out
@@ -542,8 +950,16 @@ func (c *TaskManager) ExecTimer(pid int, tid int, interval int) bool {
 	ret := false
 	if t, ok := c.ids.Get(pid); ok {
 		task := t.(*Task)
-		if task.cmd.TimerEvent != nil {
-			task.cmd.TimerEvent(task.cmd, task.pid, tid, task.context, interval)
+		if task.job != nil && task.job.state == interfaces.JobStopped {
+			task.job.pending[tid] = interval
+			ret = true
+		} else if task.async {
+			c.spin(task)
+			ret = true
+		} else if task.cmd.TimerEvent != nil {
+			c.runBackground(task, func() {
+				task.cmd.TimerEvent(task.cmd, task.pid, tid, task.context, interval)
+			})
 			ret = true
 		}
 	}
@@ -719,6 +1135,13 @@ func (c *TaskManager) ExecActivate() bool {
 	return false
 }
 
+// pipeText normalizes the captured output of a stage: the line ending is
+// reduced to '\n' and the empty line written by the commands before their output is dropped.
+func pipeText(data string) string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	return strings.TrimLeft(data, "\n")
+}
+
 func (c *TaskManager) closeTimer(task *Task, tid int) bool {
 	ret := false
 	if task != nil {
@@ -0,0 +1,99 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package context
+
+import (
+	"errors"
+	"sort"
+)
+
+// Variables are the shell variables of a session. Exported variables are
+// saved with the settings of the user and restored at the next login.
+type Variables struct {
+	vars     map[string]string
+	exported map[string]bool
+}
+
+func NewVariables() *Variables {
+	return &Variables{
+		vars:     make(map[string]string),
+		exported: make(map[string]bool),
+	}
+}
+
+// Clear removes the variables
+func (v *Variables) Clear() {
+	v.vars = make(map[string]string)
+	v.exported = make(map[string]bool)
+}
+
+func (v *Variables) Set(name string, value string) error {
+	if !IsVarName(name) {
+		return errors.New("invalid variable name: " + name)
+	}
+	v.vars[name] = value
+	return nil
+}
+
+func (v *Variables) Lookup(name string) (string, bool) {
+	value, ok := v.vars[name]
+	return value, ok
+}
+
+// Unset removes the variable, it returns true if it was exported
+func (v *Variables) Unset(name string) bool {
+	exported := v.exported[name]
+	delete(v.vars, name)
+	delete(v.exported, name)
+	return exported
+}
+
+func (v *Variables) Export(name string) error {
+	if _, ok := v.vars[name]; !ok {
+		return errors.New("variable not set: " + name)
+	}
+	v.exported[name] = true
+	return nil
+}
+
+func (v *Variables) IsExported(name string) bool {
+	return v.exported[name]
+}
+
+// List returns the variables as NAME=value, only the exported ones if exported is true
+func (v *Variables) List(exported bool) []string {
+	var out []string
+	for name, value := range v.vars {
+		if exported && !v.exported[name] {
+			continue
+		}
+		out = append(out, name+"="+value)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// IsVarName reports if name is a valid variable name: a letter or '_' followed by letters, digits or '_'
+func IsVarName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -14,6 +14,8 @@
 
 package interfaces
 
+import "context"
+
 type IContext interface {
 	Write(data string)
 	WriteLn(data string)
@@ -26,7 +28,8 @@ type IContext interface {
 	StopTimer(pid int, tid int) bool
 	PaintRequest(pid int) bool
 	SetCaption(pid int, caption string) bool
-	SetBasePath(arg string)
+	SetBasePath(arg string) error
+	GetBasePath() string
 	SetSelectionMode(int)
 	SetSelectionOptions(option rune, value float64) bool
 	SetSelectionModeNext()
@@ -34,11 +37,45 @@ type IContext interface {
 	Deactivate(pid int) bool
 	DeactivateAll(name string) int
 	History(verb HistoryAction, idx int)
+	GetHistory() []HistoryEntry
 	ClearScreen()
-	TaskList() string
+	IsOutputCaptured() bool
+	Processes() []ProcessInfo
+	LastPid() int
 	SaveTasks(name string) bool
 	RestoreTasks(name string) bool
 	ListTasks() []string
 	SetExit()
 	SetFg(pid int) bool
+	SetBg(pid int) bool
+	Jobs() []JobInfo
+	FindJob(spec string) (JobInfo, bool)
+	// The questions wait for the user with the line editor of the prompt, ctx is the one of a RunContext.
+	// They return ErrNotInteractive and the default answer when the command does not run off the event loop.
+	Confirm(ctx context.Context, question string, def bool) (bool, error)
+	Prompt(ctx context.Context, question string, def string) (string, error)
+	Password(ctx context.Context, question string) (string, error)
+	Select(ctx context.Context, question string, options []string, def int) (int, error)
+	// Call runs f on the event loop, where a RunContext can use the other methods, and waits for it
+	Call(ctx context.Context, f func()) error
+	GetEnv(name string) string
+	Environ() []string
+	SetVar(name string, value string) error
+	UnsetVar(name string) error
+	ExportVar(name string) error
+	ListVars(exported bool) []string
+	SetAlias(name string, value string) error
+	UnsetAlias(name string) error
+	GetAlias(name string) (string, bool)
+	ListAliases() []string
+	SetFunction(name string, body string) error
+	UnsetFunction(name string) error
+	GetFunction(name string) (string, bool)
+	ListFunctions() []string
+	ExecLine(line string) int
+	ExpandArgs(text string) ([]string, error)
+	LoadScript(name string) (string, error)
+	ListBuffers() []string
+	GetBuffer(name string) (string, bool)
+	DeleteBuffer(name string) bool
 }
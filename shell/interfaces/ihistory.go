@@ -14,10 +14,20 @@
 
 package interfaces
 
+import "time"
+
 type HistoryAction int
 
 const (
-	HistoryActionList  HistoryAction = iota
 	HistoryActionClear HistoryAction = iota
 	HistoryActionExec  HistoryAction = iota
 )
+
+// HistoryEntry is a command line of the history, Status and Duration are known when the command ends
+type HistoryEntry struct {
+	Line     string        `json:"line"`
+	Time     time.Time     `json:"time"`
+	Status   int           `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Session  string        `json:"session"`
+}
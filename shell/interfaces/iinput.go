@@ -0,0 +1,25 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interfaces
+
+import "errors"
+
+var (
+	// ErrNotInteractive is returned when no user can answer: the command runs in a script,
+	// a pipeline or a background job. The default answer is returned with it.
+	ErrNotInteractive = errors.New("no terminal to ask the user")
+	// ErrCancelled is returned when the user cancels a question with escape
+	ErrCancelled = errors.New("question cancelled")
+)
@@ -24,6 +24,8 @@ const (
 	KeyTypeTab
 	KeyTypeBackspace
 	KeyTypeCancel
+	KeyTypeEscape
+	KeyTypeAlt
 )
 
 const (
@@ -31,6 +33,8 @@ const (
 	CursorDownDef  CursorCodeDef = iota
 	CursorLeftDef  CursorCodeDef = iota
 	CursorRightDef CursorCodeDef = iota
+	CursorHomeDef  CursorCodeDef = iota
+	CursorEndDef   CursorCodeDef = iota
 )
 
 type KeyData struct {
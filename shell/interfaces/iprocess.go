@@ -0,0 +1,43 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interfaces
+
+import "time"
+
+// ProcessInfo describes a task of the session
+type ProcessInfo struct {
+	Pid  int
+	Name string
+	Line string
+}
+
+// States of a job
+const (
+	JobRunning = "Running"
+	JobStopped = "Stopped"
+	JobDone    = "Done"
+)
+
+// JobInfo describes a job of the session: a command line started with '&' or a task stopped with ctrl-z.
+// Pid is the task of the job, Status is its exit status once Done.
+type JobInfo struct {
+	Id      int
+	Pid     int
+	State   string
+	Status  int
+	Runtime time.Duration
+	Line    string
+	Current bool
+}
@@ -21,6 +21,8 @@ type ColorMode int
 const (
 	ModeNormal ColorMode = iota
 	Mode8bit   ColorMode = iota
+	// ModeUnderline underlines the text written with the normal colors
+	ModeUnderline ColorMode = iota
 )
 
 const (
@@ -68,8 +70,14 @@ type ITerminal interface {
 
 	MoveCursorTopLeft() (int, error)
 
+	MoveCursorUp() (int, error)
+
+	MoveCursorDown() (int, error)
+
 	ClearLine(line string) (int, error)
 
+	ClearToEnd() (int, error)
+
 	ClearScreen() (int, error)
 
 	SetSize(w int, h int)
@@ -0,0 +1,194 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package script
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxSourceDepth = 8
+
+// Executor runs the statements of a script in the session
+type Executor interface {
+	// Exec runs a command line and returns its exit status
+	Exec(line string) int
+	// Expand splits text in words, expanding variables and command substitutions
+	Expand(text string) ([]string, error)
+	SetVar(name string, value string) error
+	// Load returns the source of a script
+	Load(name string) (string, error)
+}
+
+type frame struct {
+	nodes  []Node
+	pc     int
+	loop   *For
+	words  []string
+	idx    int
+	source bool
+}
+
+// Interpreter runs a script a step at a time: Run stops at every sleep,
+// so the caller can resume it from a timer without blocking the session.
+type Interpreter struct {
+	exec   Executor
+	frames []*frame
+	status int
+}
+
+func NewInterpreter(exec Executor, script *Script) *Interpreter {
+	return &Interpreter{
+		exec:   exec,
+		frames: []*frame{{nodes: script.Nodes, source: true}},
+	}
+}
+
+// Status returns the exit status of the last command run
+func (i *Interpreter) Status() int {
+	return i.status
+}
+
+// Done reports if the script is over
+func (i *Interpreter) Done() bool {
+	return len(i.frames) == 0
+}
+
+// Run executes the script up to its end or to a sleep, it returns the time to wait before calling Run again.
+func (i *Interpreter) Run() (time.Duration, error) {
+	for len(i.frames) > 0 {
+		f := i.frames[len(i.frames)-1]
+		if f.pc >= len(f.nodes) {
+			if f.loop != nil && f.idx+1 < len(f.words) {
+				f.idx++
+				f.pc = 0
+				if err := i.exec.SetVar(f.loop.Var, f.words[f.idx]); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			i.frames = i.frames[:len(i.frames)-1]
+			continue
+		}
+
+		node := f.nodes[f.pc]
+		f.pc++
+
+		switch n := node.(type) {
+		case *Command:
+			i.status = i.exec.Exec(n.Line)
+
+		case *Assign:
+			words, err := i.exec.Expand(n.Value)
+			if err != nil {
+				return 0, err
+			}
+			if err = i.exec.SetVar(n.Name, strings.Join(words, " ")); err != nil {
+				return 0, err
+			}
+
+		case *If:
+			i.status = i.exec.Exec(n.Cond)
+			if i.status == 0 {
+				i.push(&frame{nodes: n.Then})
+			} else {
+				i.push(&frame{nodes: n.Else})
+			}
+
+		case *For:
+			words, err := i.exec.Expand(n.Words)
+			if err != nil {
+				return 0, err
+			}
+			if len(words) == 0 {
+				continue
+			}
+			if err = i.exec.SetVar(n.Var, words[0]); err != nil {
+				return 0, err
+			}
+			i.push(&frame{nodes: n.Body, loop: n, words: words})
+
+		case *Sleep:
+			words, err := i.exec.Expand(n.Duration)
+			if err != nil {
+				return 0, err
+			}
+			if len(words) != 1 {
+				return 0, errors.New("invalid duration of sleep: " + n.Duration)
+			}
+			d, err := ParseDuration(words[0])
+			if err != nil {
+				return 0, err
+			}
+			return d, nil
+
+		case *Source:
+			if err := i.source(n); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return 0, nil
+}
+
+func (i *Interpreter) push(f *frame) {
+	i.frames = append(i.frames, f)
+}
+
+func (i *Interpreter) source(n *Source) error {
+	depth := 0
+	for _, f := range i.frames {
+		if f.source {
+			depth++
+		}
+	}
+	if depth >= maxSourceDepth {
+		return errors.New("scripts sourced too deeply")
+	}
+	words, err := i.exec.Expand(n.Name)
+	if err != nil {
+		return err
+	}
+	if len(words) != 1 {
+		return errors.New("invalid script name: " + n.Name)
+	}
+	data, err := i.exec.Load(words[0])
+	if err != nil {
+		return err
+	}
+	s, err := Parse(words[0], data)
+	if err != nil {
+		return err
+	}
+	i.push(&frame{nodes: s.Nodes, source: true})
+	return nil
+}
+
+// ParseDuration parses a duration in seconds, like 1.5, or with a unit, like 500ms
+func ParseDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		if seconds < 0 {
+			return 0, errors.New("invalid duration: " + s)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, errors.New("invalid duration: " + s)
+	}
+	return d, nil
+}
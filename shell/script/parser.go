@@ -0,0 +1,263 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package script
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Node is a statement of a script
+type Node interface{}
+
+// Command is a command line run through the command tree
+type Command struct {
+	Line   string
+	LineNo int
+}
+
+// Assign sets a variable: NAME=value
+type Assign struct {
+	Name  string
+	Value string
+}
+
+// If runs Then when the exit status of Cond is zero, Else otherwise
+type If struct {
+	Cond string
+	Then []Node
+	Else []Node
+}
+
+// For runs Body once for every word of Words, assigning it to Var
+type For struct {
+	Var   string
+	Words string
+	Body  []Node
+}
+
+// Sleep suspends the script, the duration is expanded when it runs
+type Sleep struct {
+	Duration string
+}
+
+// Source runs another script in place
+type Source struct {
+	Name string
+}
+
+// Script is a parsed script
+type Script struct {
+	Name  string
+	Nodes []Node
+}
+
+type parser struct {
+	name  string
+	lines []string
+	pos   int
+}
+
+// Parse parses the source of a script.
+//
+//	# comment
+//	NAME=value
+//	if COMMAND; then ... else ... fi
+//	for NAME in WORDS; do ... done
+//	sleep 1.5 | sleep 500ms
+//	source SCRIPT
+func Parse(name string, source string) (*Script, error) {
+	p := &parser{name: name, lines: strings.Split(strings.Replace(source, "\r\n", "\n", -1), "\n")}
+	nodes, end, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if len(end) > 0 {
+		return nil, p.errorf("unexpected %q", end)
+	}
+	return &Script{Name: name, Nodes: nodes}, nil
+}
+
+func (p *parser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("%s:%d: %s", p.name, p.pos, fmt.Sprintf(format, a...))
+}
+
+// next returns the next statement, joining the lines ending with a backslash
+func (p *parser) next() (string, bool) {
+	line := ""
+	for p.pos < len(p.lines) {
+		current := strings.TrimSpace(stripComment(p.lines[p.pos]))
+		p.pos++
+		if strings.HasSuffix(current, "\\") && !strings.HasSuffix(current, "\\\\") {
+			line += current[:len(current)-1]
+			continue
+		}
+		line += current
+		if len(line) > 0 {
+			return line, true
+		}
+	}
+	return line, len(line) > 0
+}
+
+// block parses the statements up to a closing keyword (else, fi, done) or the end of the script
+func (p *parser) block() ([]Node, string, error) {
+	var nodes []Node
+	for {
+		line, ok := p.next()
+		if !ok {
+			return nodes, "", nil
+		}
+		word, rest := cutWord(line)
+		switch word {
+		case "else", "fi", "done":
+			if len(rest) > 0 {
+				return nil, "", p.errorf("unexpected %q after %s", rest, word)
+			}
+			return nodes, word, nil
+		case "then", "do":
+			return nil, "", p.errorf("unexpected %q", word)
+		case "if":
+			node, err := p.parseIf(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, node)
+		case "for":
+			node, err := p.parseFor(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, node)
+		case "sleep":
+			if len(rest) == 0 {
+				return nil, "", p.errorf("missing duration of sleep")
+			}
+			nodes = append(nodes, &Sleep{Duration: rest})
+		case "source":
+			if len(rest) == 0 {
+				return nil, "", p.errorf("missing script to source")
+			}
+			nodes = append(nodes, &Source{Name: rest})
+		default:
+			if name, value, ok := cutAssign(line); ok {
+				nodes = append(nodes, &Assign{Name: name, Value: value})
+			} else {
+				nodes = append(nodes, &Command{Line: line, LineNo: p.pos})
+			}
+		}
+	}
+}
+
+func (p *parser) parseIf(rest string) (Node, error) {
+	cond, ok := p.cutKeyword(rest, "then")
+	if !ok {
+		return nil, p.errorf("expected then")
+	}
+	if len(cond) == 0 {
+		return nil, p.errorf("missing condition of if")
+	}
+	node := &If{Cond: cond}
+	body, end, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	node.Then = body
+	if end == "else" {
+		if node.Else, end, err = p.block(); err != nil {
+			return nil, err
+		}
+	}
+	if end != "fi" {
+		return nil, p.errorf("expected fi")
+	}
+	return node, nil
+}
+
+func (p *parser) parseFor(rest string) (Node, error) {
+	header, ok := p.cutKeyword(rest, "do")
+	if !ok {
+		return nil, p.errorf("expected do")
+	}
+	name, words := cutWord(header)
+	in, words := cutWord(words)
+	if len(name) == 0 || in != "in" {
+		return nil, p.errorf("expected for NAME in WORDS")
+	}
+	body, end, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if end != "done" {
+		return nil, p.errorf("expected done")
+	}
+	return &For{Var: name, Words: words, Body: body}, nil
+}
+
+// cutKeyword removes "; keyword" from the end of the header, or reads it from the next line
+func (p *parser) cutKeyword(header string, keyword string) (string, bool) {
+	if strings.HasSuffix(header, keyword) {
+		h := strings.TrimSpace(header[:len(header)-len(keyword)])
+		if strings.HasSuffix(h, ";") {
+			return strings.TrimSpace(h[:len(h)-1]), true
+		}
+	}
+	line, ok := p.next()
+	if !ok || line != keyword {
+		return "", false
+	}
+	return header, true
+}
+
+func cutWord(line string) (string, string) {
+	if idx := strings.IndexAny(line, " \t"); idx >= 0 {
+		return line[:idx], strings.TrimSpace(line[idx:])
+	}
+	return line, ""
+}
+
+// cutAssign splits a NAME=value statement
+func cutAssign(line string) (string, string, bool) {
+	idx := strings.Index(line, "=")
+	if idx <= 0 {
+		return "", "", false
+	}
+	for i, r := range line[:idx] {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_' || i > 0 && r >= '0' && r <= '9') {
+			return "", "", false
+		}
+	}
+	return line[:idx], line[idx+1:], true
+}
+
+// stripComment removes a comment starting with '#' at the beginning of a word, outside of the quotes
+func stripComment(line string) string {
+	var single, double, escaped bool
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && !single:
+			escaped = true
+		case r == '\'' && !double:
+			single = !single
+		case r == '"' && !single:
+			double = !double
+		case r == '#' && !single && !double && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
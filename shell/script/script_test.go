@@ -0,0 +1,131 @@
+package script
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeExecutor struct {
+	lines   []string
+	vars    map[string]string
+	scripts map[string]string
+}
+
+func (f *fakeExecutor) Exec(line string) int {
+	line = f.expand(line)
+	f.lines = append(f.lines, line)
+	if line == "false" {
+		return 1
+	}
+	return 0
+}
+
+func (f *fakeExecutor) expand(text string) string {
+	for name, value := range f.vars {
+		text = strings.Replace(text, "$"+name, value, -1)
+	}
+	return text
+}
+
+func (f *fakeExecutor) Expand(text string) ([]string, error) {
+	return strings.Fields(f.expand(text)), nil
+}
+
+func (f *fakeExecutor) SetVar(name string, value string) error {
+	f.vars[name] = value
+	return nil
+}
+
+func (f *fakeExecutor) Load(name string) (string, error) {
+	if data, ok := f.scripts[name]; ok {
+		return data, nil
+	}
+	return "", errors.New("script not found: " + name)
+}
+
+func TestInterpreter_Run(t *testing.T) {
+	source := `# comment
+N=2
+for i in 1 $N; do
+  if false; then
+    echo never
+  else
+    echo loop $i # trailing comment
+  fi
+done
+sleep 1.5
+echo "a # b"
+source other
+`
+	s, err := Parse("test", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &fakeExecutor{vars: map[string]string{}, scripts: map[string]string{"other": "echo other"}}
+	i := NewInterpreter(exec, s)
+
+	d, err := i.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != 1500*time.Millisecond || i.Done() {
+		t.Fatalf("expected a sleep of 1.5s, got %v", d)
+	}
+	expected := []string{"false", "echo loop 1", "false", "echo loop 2"}
+	if strings.Join(exec.lines, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected %q, got %q", expected, exec.lines)
+	}
+
+	exec.lines = nil
+	if _, err = i.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !i.Done() {
+		t.Fatal("expected the end of the script")
+	}
+	expected = []string{`echo "a # b"`, "echo other"}
+	if strings.Join(exec.lines, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected %q, got %q", expected, exec.lines)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []string{
+		"if true; then\necho x\n",
+		"for x in a b\necho x\ndone",
+		"fi",
+		"for in a; do\ndone",
+		"sleep",
+		"else",
+	}
+	for _, source := range tests {
+		if _, err := Parse("test", source); err == nil {
+			t.Errorf("Parse(%q) expected an error", source)
+		}
+	}
+}
+
+func TestInterpreter_SourceDepth(t *testing.T) {
+	s, err := Parse("loop", "source loop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &fakeExecutor{vars: map[string]string{}, scripts: map[string]string{"loop": "source loop"}}
+	if _, err = NewInterpreter(exec, s).Run(); err == nil {
+		t.Error("expected an error for a recursive source")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{"2": 2 * time.Second, "0.5": 500 * time.Millisecond, "250ms": 250 * time.Millisecond}
+	for in, expected := range tests {
+		if d, err := ParseDuration(in); err != nil || d != expected {
+			t.Errorf("ParseDuration(%q): expected %v, got %v %v", in, expected, d, err)
+		}
+	}
+	if _, err := ParseDuration("-1"); err == nil {
+		t.Error("expected an error for a negative duration")
+	}
+}
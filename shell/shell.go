@@ -21,11 +21,20 @@ import (
 	"github.com/markel1974/goshell/shell/interfaces"
 	"github.com/markel1974/goshell/shell/ssh"
 	"github.com/markel1974/goshell/shell/telnet"
+	"io/fs"
 )
 
 type IShellServer interface {
 	SetPrompt(prompt string)
+	// Deprecated: Use SetTemplateFunc, the sessions share the flags of template.
 	SetTemplate(template *cli.Command)
+	SetTemplateFunc(f func() *cli.Command)
+	SetEnvAllowlist(allowlist []string)
+	SetDataDir(dir string)
+	SetScriptsDir(dir string)
+	SetHistorySize(size uint)
+	SetHistoryDir(dir string)
+	SetScripts(fsys fs.FS)
 	Start()
 	AsyncStart()
 }
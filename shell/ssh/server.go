@@ -27,6 +27,7 @@ import (
 	"github.com/markel1974/goshell/shell/interfaces"
 	"github.com/markel1974/goshell/shell/terminal"
 	"golang.org/x/crypto/ssh"
+	"io/fs"
 	"io/ioutil"
 	"log"
 	"net"
@@ -35,7 +36,7 @@ import (
 
 type Server struct {
 	ticker             *adaptiveticker.AdaptiveTicker
-	template           *cli.Command
+	template           func() *cli.Command
 	prompt             string
 	addr               string
 	factory            *terminal.EquipmentFactory
@@ -46,6 +47,17 @@ type Server struct {
 	debug              bool
 	auth               interfaces.IAuthenticator
 	autosave           bool
+	envAllowlist       []string
+	dataDir            string
+	scriptsDir         string
+	historySize        uint
+	historyDir         string
+	scripts            fs.FS
+}
+
+type envRequest struct {
+	Name  string
+	Value string
 }
 
 func NewServer(ticker *adaptiveticker.AdaptiveTicker, auth interfaces.IAuthenticator, port int, autosave bool) *Server {
@@ -138,8 +150,46 @@ func (r *Server) SetPrompt(prompt string) {
 	r.prompt = prompt
 }
 
+// SetEnvAllowlist sets the variables accepted from the client env requests.
+func (r *Server) SetEnvAllowlist(allowlist []string) {
+	r.envAllowlist = allowlist
+}
+
+// SetDataDir sets the directory holding the files written by the users.
+func (r *Server) SetDataDir(dir string) {
+	r.dataDir = dir
+}
+
+// SetHistoryDir sets the directory of the history files of the users, by default in the data directory.
+func (r *Server) SetHistoryDir(dir string) {
+	r.historyDir = dir
+}
+
+// SetHistorySize sets the number of commands kept in the history of the users.
+func (r *Server) SetHistorySize(size uint) {
+	r.historySize = size
+}
+
+// SetScriptsDir sets the directory of the scripts run by the users.
+func (r *Server) SetScriptsDir(dir string) {
+	r.scriptsDir = dir
+}
+
+// SetScripts sets the scripts embedded by the host, they take precedence over the scripts directory.
+func (r *Server) SetScripts(fsys fs.FS) {
+	r.scripts = fsys
+}
+
+// SetTemplate sets the commands of the host, every session gets a copy of template.
+// Deprecated: Use SetTemplateFunc, the copies share the flags of template.
 func (r *Server) SetTemplate(template *cli.Command) {
-	r.template = template
+	r.SetTemplateFunc(cli.TemplateFunc(template))
+}
+
+// SetTemplateFunc sets the function building the commands of the host, it is called for every session
+// so the commands, their flags and the variables bound to them are never shared by two sessions.
+func (r *Server) SetTemplateFunc(f func() *cli.Command) {
+	r.template = f
 }
 
 func (r *Server) Start() {
@@ -195,6 +245,23 @@ func (r *Server) handleConnection(nConn net.Conn) {
 		}
 
 		ctx := context.NewContext(r.ticker, channel, channel, r.auth, r.factory, r.template, r.prompt, r.autosave)
+		if r.envAllowlist != nil {
+			ctx.SetEnvAllowlist(r.envAllowlist)
+		}
+		if len(r.dataDir) > 0 {
+			ctx.SetDataDir(r.dataDir)
+		}
+		if len(r.scriptsDir) > 0 {
+			ctx.SetScriptsDir(r.scriptsDir)
+		}
+		if len(r.historyDir) > 0 {
+			ctx.SetHistoryDir(r.historyDir)
+		}
+		if r.historySize > 0 {
+			ctx.SetHistorySize(r.historySize)
+		}
+		ctx.SetScripts(r.scripts)
+		ctx.SetUser(conn.User())
 		ctx.Setup()
 		//ctx.SetEnterKey(10)
 
@@ -208,12 +275,22 @@ func (r *Server) handleConnection(nConn net.Conn) {
 					}
 				case "pty-req":
 					termLen := req.Payload[3]
+					ctx.SetEnv("TERM", string(req.Payload[4:termLen+4]))
 					w, h := r.parseSize(req.Payload[termLen+4:])
 					ctx.SetScreenSize(int(w), int(h))
 					_ = req.Reply(true, nil)
 				case "window-change":
 					w, h := r.parseSize(req.Payload)
 					ctx.SetScreenSize(int(w), int(h))
+				case "env":
+					var env envRequest
+					accepted := false
+					if err := ssh.Unmarshal(req.Payload, &env); err == nil {
+						accepted = ctx.SetEnv(env.Name, env.Value)
+					}
+					if req.WantReply {
+						_ = req.Reply(accepted, nil)
+					}
 				}
 			}
 		}(requests)
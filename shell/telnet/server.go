@@ -22,18 +22,26 @@ import (
 	"github.com/markel1974/goshell/shell/interfaces"
 	"github.com/markel1974/goshell/shell/telnet/session"
 	"github.com/markel1974/goshell/shell/terminal"
+	"io/fs"
 	"log"
 	"net"
 )
 
 type Server struct {
 	ticker   *adaptiveticker.AdaptiveTicker
-	template *cli.Command
+	template func() *cli.Command
 	prompt   string
 	addr     string
 	factory  *terminal.EquipmentFactory
 	auth     interfaces.IAuthenticator
 	autosave bool
+
+	envAllowlist []string
+	dataDir      string
+	scriptsDir   string
+	historySize  uint
+	historyDir   string
+	scripts      fs.FS
 }
 
 func NewServer(ticker *adaptiveticker.AdaptiveTicker, auth interfaces.IAuthenticator, port int, autosave bool) *Server {
@@ -62,6 +70,22 @@ func (r *Server) handleConnection(c net.Conn) {
 	telnetSession := session.NewTelnet(c)
 
 	ctx := context.NewContext(r.ticker, telnetSession, telnetSession, r.auth, r.factory, r.template, r.prompt, r.autosave)
+	if r.envAllowlist != nil {
+		ctx.SetEnvAllowlist(r.envAllowlist)
+	}
+	if len(r.dataDir) > 0 {
+		ctx.SetDataDir(r.dataDir)
+	}
+	if len(r.scriptsDir) > 0 {
+		ctx.SetScriptsDir(r.scriptsDir)
+	}
+	if len(r.historyDir) > 0 {
+		ctx.SetHistoryDir(r.historyDir)
+	}
+	if r.historySize > 0 {
+		ctx.SetHistorySize(r.historySize)
+	}
+	ctx.SetScripts(r.scripts)
 
 	ctx.Setup()
 
@@ -79,6 +103,14 @@ func (r *Server) handleConnection(c net.Conn) {
 
 		case session.TT:
 			//c.terminal.SetTerminalType(string(data))
+			if len(data) > 1 {
+				ctx.SetEnv("TERM", string(data[1:]))
+			}
+
+		case session.NENV:
+			for name, value := range session.ParseEnviron(data) {
+				ctx.SetEnv(name, value)
+			}
 
 		default:
 			log.Println("Unknown code", code, "data", data)
@@ -88,6 +120,7 @@ func (r *Server) handleConnection(c net.Conn) {
 	telnetSession.WillSga()
 	telnetSession.DoWindowSize()
 	telnetSession.DoTerminalType()
+	telnetSession.DoNewEnviron()
 
 	ctx.Exec()
 	ctx.Close()
@@ -95,8 +128,45 @@ func (r *Server) handleConnection(c net.Conn) {
 	_ = c.Close()
 }
 
+// SetEnvAllowlist sets the variables accepted from the client NEW-ENVIRON negotiation.
+func (r *Server) SetEnvAllowlist(allowlist []string) {
+	r.envAllowlist = allowlist
+}
+
+// SetDataDir sets the directory holding the files written by the users.
+func (r *Server) SetDataDir(dir string) {
+	r.dataDir = dir
+}
+
+// SetHistoryDir sets the directory of the history files of the users, by default in the data directory.
+func (r *Server) SetHistoryDir(dir string) {
+	r.historyDir = dir
+}
+
+// SetHistorySize sets the number of commands kept in the history of the users.
+func (r *Server) SetHistorySize(size uint) {
+	r.historySize = size
+}
+
+// SetScriptsDir sets the directory of the scripts run by the users.
+func (r *Server) SetScriptsDir(dir string) {
+	r.scriptsDir = dir
+}
+
+// SetScripts sets the scripts embedded by the host, they take precedence over the scripts directory.
+func (r *Server) SetScripts(fsys fs.FS) {
+	r.scripts = fsys
+}
+
+// SetTemplate sets the commands of the host, every session gets a copy of template.
+// Deprecated: Use SetTemplateFunc, the copies share the flags of template.
 func (r *Server) SetTemplate(template *cli.Command) {
-	r.template = template
+	r.SetTemplateFunc(cli.TemplateFunc(template))
+}
+
+// SetTemplateFunc sets the function building the commands of the host, each session gets its own commands
+func (r *Server) SetTemplateFunc(f func() *cli.Command) {
+	r.template = f
 }
 
 func (r *Server) Start() {
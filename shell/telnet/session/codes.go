@@ -41,6 +41,7 @@ const (
 	RFC  IOCode = iota // Remote flow control
 	LM   IOCode = iota // Line mode
 	EV   IOCode = iota // Environment variables
+	NENV IOCode = iota // New environment variables, RFC 1572
 	SE   IOCode = iota // End of sub negotiation parameters.
 	NOP  IOCode = iota // No operation.
 	DM   IOCode = iota // Data Mark. The data stream portion of a Sync. This should always be accompanied by a TCP Urgent notification.
@@ -87,6 +88,7 @@ func init() {
 	codeToByte[RFC] = '\x21'
 	codeToByte[LM] = '\x22'
 	codeToByte[EV] = '\x24'
+	codeToByte[NENV] = '\x27'
 	codeToByte[SE] = '\xf0'
 	codeToByte[NOP] = '\xf1'
 	codeToByte[DM] = '\xf2'
@@ -171,6 +173,8 @@ func CodeToString(code IOCode) string {
 		return "LM"
 	case EV:
 		return "EV"
+	case NENV:
+		return "NENV"
 	case SE:
 		return "SE"
 	case NOP:
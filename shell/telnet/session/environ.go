@@ -0,0 +1,76 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package session
+
+// NEW-ENVIRON sub negotiation codes, RFC 1572
+const (
+	environIs   byte = 0
+	environInfo byte = 2
+
+	environVar     byte = 0
+	environValue   byte = 1
+	environEsc     byte = 2
+	environUserVar byte = 3
+)
+
+// ParseEnviron decodes the payload of an IS or INFO NEW-ENVIRON sub negotiation.
+func ParseEnviron(data []byte) map[string]string {
+	out := make(map[string]string)
+	if len(data) == 0 || (data[0] != environIs && data[0] != environInfo) {
+		return out
+	}
+
+	var name, value []byte
+	inName := false
+	inValue := false
+
+	flush := func() {
+		if len(name) > 0 {
+			out[string(name)] = string(value)
+		}
+		name = nil
+		value = nil
+	}
+
+	for i := 1; i < len(data); i++ {
+		b := data[i]
+		switch b {
+		case environVar, environUserVar:
+			flush()
+			inName = true
+			inValue = false
+			continue
+		case environValue:
+			inName = false
+			inValue = true
+			continue
+		case environEsc:
+			// a trailing ESC escapes nothing
+			i++
+			if i == len(data) {
+				continue
+			}
+			b = data[i]
+		}
+		if inName {
+			name = append(name, b)
+		} else if inValue {
+			value = append(value, b)
+		}
+	}
+	flush()
+
+	return out
+}
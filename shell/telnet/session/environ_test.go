@@ -0,0 +1,38 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEnviron(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected map[string]string
+	}{
+		{"empty", nil, map[string]string{}},
+		{"not IS or INFO", []byte{1, environVar, 'A', environValue, 'b'}, map[string]string{}},
+		{"var", []byte{environIs, environVar, 'L', 'A', 'N', 'G', environValue, 'C'}, map[string]string{"LANG": "C"}},
+		{"info", []byte{environInfo, environVar, 'T', 'Z', environValue, 'U', 'T', 'C'}, map[string]string{"TZ": "UTC"}},
+		{"uservar", []byte{environIs, environUserVar, 'X', environValue, '1'}, map[string]string{"X": "1"}},
+		{"several", []byte{environIs, environVar, 'A', environValue, '1', environUserVar, 'B', environValue, '2'},
+			map[string]string{"A": "1", "B": "2"}},
+		{"without value", []byte{environIs, environVar, 'A'}, map[string]string{"A": ""}},
+		{"empty name", []byte{environIs, environVar, environValue, '1'}, map[string]string{}},
+		{"escaped value", []byte{environIs, environVar, 'A', environValue, 'x', environEsc, environVar, environEsc, environEsc, 'y'},
+			map[string]string{"A": "x\x00\x02y"}},
+		{"escaped name", []byte{environIs, environUserVar, 'A', environEsc, environValue, 'B', environValue, '1'},
+			map[string]string{"A\x01B": "1"}},
+		{"trailing escape in value", []byte{environIs, environVar, 'A', environValue, 'x', environEsc}, map[string]string{"A": "x"}},
+		{"trailing escape in name", []byte{environIs, environVar, 'A', environEsc}, map[string]string{"A": ""}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := ParseEnviron(tc.data); !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
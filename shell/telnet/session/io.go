@@ -110,6 +110,11 @@ func (t *Telnet) DoTerminalType() {
 	t.SendCommand(DO, TT, IAC, SB, TT, 1, IAC, SE) // 1 = SEND
 }
 
+func (t *Telnet) DoNewEnviron() {
+	// See http://tools.ietf.org/html/rfc1572
+	t.SendCommand(DO, NENV, IAC, SB, NENV, 1, IAC, SE) // 1 = SEND
+}
+
 func (t *Telnet) SendCommand(codes ...IOCode) {
 	_, _ = t.conn.Write(t.BuildCommand(codes...))
 }
@@ -38,7 +38,8 @@ const (
 		black  colorCode = "\033[22;30m"
 		gray   colorCode = "\033[22;37m"
 	*/
-	normal colorCode = "\033[0m"
+	normal    colorCode = "\033[0m"
+	underline colorCode = "\033[4m"
 )
 
 const (
@@ -94,6 +95,10 @@ func Colorize(text string, fg colorCode) string {
 	return fmt.Sprintf("%s%s%s", string(fg), text, string(terminator))
 }
 
+func Underline(text string) string {
+	return fmt.Sprintf("%s%s%s", string(underline), text, string(normal))
+}
+
 func ColorizeWithBackground(text string, fg colorCode, bg colorCode) string {
 	terminator := normal
 	return fmt.Sprintf("%s%s%s%s", string(fg), string(bg), text, string(terminator))
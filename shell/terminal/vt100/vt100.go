@@ -26,6 +26,9 @@ var (
 	escMoveCursorLeftDef    = []byte{27, 91, 68}
 	escMoveCursorRightDef   = []byte{27, 91, 67}
 	escMoveCursorTopLeftDef = []byte{27, 91, 'H'}
+	escMoveCursorUpDef      = []byte{27, 91, 65}
+	escMoveCursorDownDef    = []byte{27, 91, 66}
+	escClearToEndDef        = []byte{27, 91, 'J'}
 	escSaveCursorDef        = []byte{27, '7'}
 	escRestoreCursorDef     = []byte{27, '8'}
 
@@ -79,6 +82,9 @@ func (l *VT100) Colorize(text string, f int, b int, mode interfaces.ColorMode) s
 		}
 		return Colorize8WithBackground(text, f, b)
 
+	case interfaces.ModeUnderline:
+		return Underline(l.Colorize(text, f, b, interfaces.ModeNormal))
+
 	default:
 		return text
 	}
@@ -104,6 +110,19 @@ func (l *VT100) MoveCursorTopLeft() (int, error) {
 	return l.z.Write(escMoveCursorTopLeftDef)
 }
 
+func (l *VT100) MoveCursorUp() (int, error) {
+	return l.z.Write(escMoveCursorUpDef)
+}
+
+func (l *VT100) MoveCursorDown() (int, error) {
+	return l.z.Write(escMoveCursorDownDef)
+}
+
+// ClearToEnd erases from the cursor to the end of the screen
+func (l *VT100) ClearToEnd() (int, error) {
+	return l.z.Write(escClearToEndDef)
+}
+
 func (l *VT100) ClearLine(_ string) (int, error) {
 	//l.ResetBuffer()
 	//l.current = []rune(line)
@@ -269,14 +288,24 @@ func (l *VT100) Scan(data []byte) {
 			case 0:
 				escape = false
 			case 1:
-				if key == 91 {
+				if key == 91 || key == 'O' {
 					escapeSequence = append(escapeSequence, byte(key))
 				} else {
+					// escape followed by a key is the key pressed with alt (or meta)
+					if key == 127 || key == 8 {
+						l.doAlt(127)
+					} else if key >= 0x20 && key < 0x7F {
+						l.doAlt(key)
+					}
 					escape = false
 				}
 
 			case 2, 3, 4:
-				if key >= 0x30 && key <= 0x3F {
+				if escapeSequence[1] == 'O' {
+					// SS3, sent for the cursor keys in application mode
+					l.doEscape(0, 0, byte(key))
+					escape = false
+				} else if key >= 0x30 && key <= 0x3F {
 					escapeParameter = byte(key)
 					escapeSequence = append(escapeSequence, byte(key))
 				} else if key >= 0x20 && key <= 0x2F {
@@ -307,6 +336,8 @@ func (l *VT100) Scan(data []byte) {
 					l.doCtrl(key)
 				case 4:
 					l.doCtrl(key)
+				case 26:
+					l.doCtrl(key)
 				case 8:
 					if l.keyFunc != nil {
 						l.keyFunc(interfaces.NewKeyData(interfaces.KeyTypeBackspace, 8))
@@ -322,13 +353,20 @@ func (l *VT100) Scan(data []byte) {
 						l.keyFunc(interfaces.NewKeyData(interfaces.KeyTypeBackspace, 8))
 					}
 				default:
-					if l.keyFunc != nil {
+					if key < 32 && key != 0 && key != 10 {
+						l.doCtrl(key)
+					} else if l.keyFunc != nil {
 						l.keyFunc(interfaces.NewKeyData(interfaces.KeyTypeKey, key))
 					}
 				}
 			}
 		}
 	}
+
+	// an escape alone is the escape key
+	if escape && len(escapeSequence) == 1 && l.keyFunc != nil {
+		l.keyFunc(interfaces.NewKeyData(interfaces.KeyTypeEscape, 27))
+	}
 }
 
 func (l *VT100) doEscape(parameter byte, intermediate byte, final byte) bool {
@@ -345,11 +383,20 @@ func (l *VT100) doEscape(parameter byte, intermediate byte, final byte) bool {
 		l.doMoveCursorRight()
 	case 68:
 		l.doMoveCursorLeft()
+	case 'H':
+		l.doMoveCursor(interfaces.CursorHomeDef)
+	case 'F':
+		l.doMoveCursor(interfaces.CursorEndDef)
 	case 126:
-		if parameter == 51 {
+		switch parameter {
+		case '3':
 			if l.keyFunc != nil {
 				l.keyFunc(interfaces.NewKeyData(interfaces.KeyTypeCancel, 127))
 			}
+		case '1', '7':
+			l.doMoveCursor(interfaces.CursorHomeDef)
+		case '4', '8':
+			l.doMoveCursor(interfaces.CursorEndDef)
 		}
 	}
 
@@ -362,6 +409,18 @@ func (l *VT100) doCtrl(key rune) {
 	}
 }
 
+func (l *VT100) doAlt(key rune) {
+	if l.keyFunc != nil {
+		l.keyFunc(interfaces.NewKeyData(interfaces.KeyTypeAlt, key))
+	}
+}
+
+func (l *VT100) doMoveCursor(code interfaces.CursorCodeDef) {
+	if l.keyFunc != nil {
+		l.keyFunc(interfaces.NewKeyData(interfaces.KeyTypeCursor, rune(code)))
+	}
+}
+
 func (l *VT100) doMoveCursorRight() {
 	if l.keyFunc != nil {
 		l.keyFunc(interfaces.NewKeyData(interfaces.KeyTypeCursor, rune(interfaces.CursorRightDef)))
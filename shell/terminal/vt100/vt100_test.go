@@ -0,0 +1,70 @@
+package vt100
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/markel1974/goshell/shell/interfaces"
+)
+
+func key(t interfaces.KeyType, k rune) interfaces.KeyData {
+	return interfaces.KeyData{Type: t, Key: k}
+}
+
+func cursor(code interfaces.CursorCodeDef) interfaces.KeyData {
+	return key(interfaces.KeyTypeCursor, rune(code))
+}
+
+func TestVT100_Scan(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []interfaces.KeyData
+	}{
+		{"keys", "ab", []interfaces.KeyData{key(interfaces.KeyTypeKey, 'a'), key(interfaces.KeyTypeKey, 'b')}},
+		{"utf8", "è", []interfaces.KeyData{key(interfaces.KeyTypeKey, 'è')}},
+		{"enter", "\r", []interfaces.KeyData{key(interfaces.KeyTypeEnter, '\n')}},
+		{"tab", "\t", []interfaces.KeyData{key(interfaces.KeyTypeTab, '\t')}},
+		{"backspace", "\x7f", []interfaces.KeyData{key(interfaces.KeyTypeBackspace, 8)}},
+		{"ctrl-h", "\x08", []interfaces.KeyData{key(interfaces.KeyTypeBackspace, 8)}},
+		{"ctrl-c", "\x03", []interfaces.KeyData{key(interfaces.KeyTypeCtrl, 3)}},
+		{"ctrl-a", "\x01", []interfaces.KeyData{key(interfaces.KeyTypeCtrl, 1)}},
+		{"ctrl-y", "\x19", []interfaces.KeyData{key(interfaces.KeyTypeCtrl, 25)}},
+		{"line feed", "\n", []interfaces.KeyData{key(interfaces.KeyTypeKey, '\n')}},
+		{"escape", "\x1b", []interfaces.KeyData{key(interfaces.KeyTypeEscape, 27)}},
+		{"alt-b", "\x1bb", []interfaces.KeyData{key(interfaces.KeyTypeAlt, 'b')}},
+		{"alt-y", "\x1byx", []interfaces.KeyData{key(interfaces.KeyTypeAlt, 'y'), key(interfaces.KeyTypeKey, 'x')}},
+		{"alt-backspace", "\x1b\x7f", []interfaces.KeyData{key(interfaces.KeyTypeAlt, 127)}},
+		{"alt-ctrl-h", "\x1b\x08", []interfaces.KeyData{key(interfaces.KeyTypeAlt, 127)}},
+		{"up", "\x1b[A", []interfaces.KeyData{cursor(interfaces.CursorUpDef)}},
+		{"down", "\x1b[B", []interfaces.KeyData{cursor(interfaces.CursorDownDef)}},
+		{"right", "\x1b[C", []interfaces.KeyData{cursor(interfaces.CursorRightDef)}},
+		{"left", "\x1b[D", []interfaces.KeyData{cursor(interfaces.CursorLeftDef)}},
+		{"application up", "\x1bOA", []interfaces.KeyData{cursor(interfaces.CursorUpDef)}},
+		{"home", "\x1b[H", []interfaces.KeyData{cursor(interfaces.CursorHomeDef)}},
+		{"end", "\x1b[F", []interfaces.KeyData{cursor(interfaces.CursorEndDef)}},
+		{"application home", "\x1bOH", []interfaces.KeyData{cursor(interfaces.CursorHomeDef)}},
+		{"application end", "\x1bOF", []interfaces.KeyData{cursor(interfaces.CursorEndDef)}},
+		{"vt home", "\x1b[1~", []interfaces.KeyData{cursor(interfaces.CursorHomeDef)}},
+		{"vt end", "\x1b[4~", []interfaces.KeyData{cursor(interfaces.CursorEndDef)}},
+		{"rxvt home", "\x1b[7~", []interfaces.KeyData{cursor(interfaces.CursorHomeDef)}},
+		{"rxvt end", "\x1b[8~", []interfaces.KeyData{cursor(interfaces.CursorEndDef)}},
+		{"delete", "\x1b[3~", []interfaces.KeyData{key(interfaces.KeyTypeCancel, 127)}},
+		{"sequence between keys", "a\x1b[Db", []interfaces.KeyData{
+			key(interfaces.KeyTypeKey, 'a'), cursor(interfaces.CursorLeftDef), key(interfaces.KeyTypeKey, 'b'),
+		}},
+		{"unknown sequence", "\x1b[5~a", []interfaces.KeyData{key(interfaces.KeyTypeKey, 'a')}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []interfaces.KeyData
+			v := NewVt100(&bytes.Buffer{})
+			v.SetKeyFunc(func(event *interfaces.KeyData) { keys = append(keys, *event) })
+			v.Scan([]byte(tt.data))
+			if !reflect.DeepEqual(keys, tt.expected) {
+				t.Errorf("got %v, want %v", keys, tt.expected)
+			}
+		})
+	}
+}